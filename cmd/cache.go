@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/spf13/cobra"
+)
+
+// CacheCmd represents the cache command
+var CacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the list cache of the running server",
+}
+
+var InvalidateCacheCmd = &cobra.Command{
+	Use:   "invalidate <path>",
+	Short: "Invalidate the list cache of a path and all its sub-folders",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("path is required")
+			return
+		}
+		Init()
+		defer Release()
+		InvalidateCacheOnline(args[0])
+	},
+}
+
+func InvalidateCacheOnline(path string) {
+	client := resty.New().SetTimeout(10 * time.Second).SetTLSClientConfig(&tls.Config{InsecureSkipVerify: conf.Conf.TlsInsecureSkipVerify})
+	token := setting.GetStr(conf.Token)
+	port := conf.Conf.Scheme.HttpPort
+	u := fmt.Sprintf("http://localhost:%d/api/admin/cache/invalidate", port)
+	if port == -1 {
+		if conf.Conf.Scheme.HttpsPort == -1 {
+			utils.Log.Errorf("[invalidate_cache] no open port")
+			return
+		}
+		u = fmt.Sprintf("https://localhost:%d/api/admin/cache/invalidate", conf.Conf.Scheme.HttpsPort)
+	}
+	res, err := client.R().SetHeader("Authorization", token).SetBody(map[string]string{"path": path}).Post(u)
+	if err != nil {
+		utils.Log.Errorf("[invalidate_cache] failed, is the server running? %+v", err)
+		return
+	}
+	code := utils.Json.Get(res.Body(), "code").ToInt()
+	msg := utils.Json.Get(res.Body(), "message").ToString()
+	if res.StatusCode() != 200 || code != 200 {
+		utils.Log.Errorf("[invalidate_cache] error: %s", msg)
+		return
+	}
+	var storages []string
+	utils.Json.Get(res.Body(), "data", "storages").ToVal(&storages)
+	utils.Log.Infof("the list cache of [%s] in %d storages %v have been invalidated", path, len(storages), storages)
+}
+
+func init() {
+	RootCmd.AddCommand(CacheCmd)
+	CacheCmd.AddCommand(InvalidateCacheCmd)
+}
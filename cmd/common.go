@@ -5,9 +5,14 @@ import (
 	"path/filepath"
 	"strconv"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/bootstrap"
 	"github.com/alist-org/alist/v3/internal/bootstrap/data"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/schedule"
+	"github.com/alist-org/alist/v3/internal/tracing"
+	"github.com/alist-org/alist/v3/internal/webhook"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
@@ -16,14 +21,21 @@ func Init() {
 	bootstrap.InitConfig()
 	bootstrap.Log()
 	bootstrap.InitDB()
+	bootstrap.InitListCache()
 	data.InitData()
 	bootstrap.InitStreamLimit()
+	bootstrap.InitTracing()
 	bootstrap.InitIndex()
 	bootstrap.InitUpgradePatch()
 }
 
 func Release() {
+	schedule.Stop()
+	audit.Stop()
+	webhook.Stop()
+	tracing.Shutdown()
 	db.Close()
+	op.CloseListCache()
 }
 
 var pid = -1
@@ -0,0 +1,65 @@
+//go:build fuse
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/bootstrap"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fuse"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var mountArgs fuse.MountArgs
+var mountUsername string
+
+// MountCmd represents the mount command
+var MountCmd = &cobra.Command{
+	Use:   "mount <mountpoint>",
+	Short: "Mount the storages as a local filesystem with FUSE",
+	Long: `Mount the storages as a local filesystem with FUSE,
+all the operations are done as the given user, so its base path and permissions are respected`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			utils.Log.Errorf("mount point is required")
+			return
+		}
+		Init()
+		defer Release()
+		user, err := op.GetAdmin()
+		if mountUsername != "" {
+			user, err = op.GetUserByName(mountUsername)
+		}
+		if err != nil {
+			utils.Log.Errorf("failed to get user: %+v", err)
+			return
+		}
+		if user.Disabled {
+			utils.Log.Errorf("user [%s] is disabled", user.Username)
+			return
+		}
+		bootstrap.LoadStorages()
+		bootstrap.InitStorageHealthCheck()
+		bootstrap.InitAudit()
+		bootstrap.InitWebhooks()
+		for !conf.StoragesLoaded {
+			time.Sleep(100 * time.Millisecond)
+		}
+		utils.Log.Infof("mount [%s] of user [%s] at %s", mountArgs.RootFolder, user.Username, args[0])
+		if !fuse.Mount(user, args[0], mountArgs) {
+			utils.Log.Errorf("failed to mount at %s", args[0])
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(MountCmd)
+	MountCmd.Flags().StringVarP(&mountUsername, "user", "u", "", "the user to access storages as, default to admin")
+	MountCmd.Flags().StringVar(&mountArgs.RootFolder, "root", "/", "the folder to mount, relative to the base path of user")
+	MountCmd.Flags().StringVar(&mountArgs.MetaPass, "meta-pass", "", "the password of folders protected by meta")
+	MountCmd.Flags().BoolVar(&mountArgs.ReadOnly, "read-only", false, "mount as read only")
+	MountCmd.Flags().StringArrayVarP(&mountArgs.Options, "option", "o", nil, "options passed to fuse, e.g. allow_other")
+}
@@ -43,6 +43,11 @@ the address is defined in config file`,
 		bootstrap.InitOfflineDownloadTools()
 		bootstrap.LoadStorages()
 		bootstrap.InitTaskManager()
+		bootstrap.InitSchedule()
+		bootstrap.InitTrash()
+		bootstrap.InitStorageHealthCheck()
+		bootstrap.InitAudit()
+		bootstrap.InitWebhooks()
 		if !flags.Debug && !flags.Dev {
 			gin.SetMode(gin.ReleaseMode)
 		}
@@ -6,6 +6,7 @@ import (
 	"github.com/SheltonZhu/115driver/pkg/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
 
 var _ model.Obj = (*FileObj)(nil)
@@ -36,3 +37,9 @@ type UploadResult struct {
 		IsVideo  int    `json:"is_video"`
 	} `json:"data"`
 }
+
+// UploadState is saved to the upload checkpoint to resume the multipart upload
+type UploadState struct {
+	Params driver.UploadOSSParams            `json:"params"`
+	Imur   oss.InitiateMultipartUploadResult `json:"imur"`
+}
@@ -357,13 +357,41 @@ func (d *Pan115) UploadByMultipart(ctx context.Context, params *driver115.Upload
 		return nil, err
 	}
 
-	if imur, err = bucket.InitiateMultipartUpload(params.Object,
-		oss.SetHeader(driver115.OssSecurityTokenHeaderName, ossToken.SecurityToken),
-		oss.UserAgentHeader(driver115.OSSUserAgent),
-		oss.EnableSha1(), oss.Sequential(),
-	); err != nil {
-		return nil, err
+	// resume the multipart upload initiated before, the parts uploaded are skipped
+	cp := driver.GetUploadCheckpoint(ctx)
+	var state UploadState
+	if cp.Load(&state) && state.Params.Bucket == params.Bucket && state.Imur.UploadID != "" {
+		var uploaded map[int]oss.UploadedPart
+		if uploaded, err = listUploadedParts(bucket, state.Imur, ossToken); err == nil {
+			sha1 := params.SHA1
+			params, imur = &state.Params, state.Imur
+			params.SHA1 = sha1
+			pending := chunks[:0:0]
+			for _, chunk := range chunks {
+				if p, ok := uploaded[chunk.Number]; ok && int64(p.Size) == chunk.Size {
+					parts = append(parts, oss.UploadPart{PartNumber: p.PartNumber, ETag: p.ETag})
+				} else {
+					pending = append(pending, chunk)
+				}
+			}
+			utils.Log.Infof("[115] resume uploading %s, %d/%d parts uploaded", s.GetName(), len(parts), len(chunks))
+			chunks = pending
+		} else {
+			utils.Log.Warnf("[115] failed to resume upload: %+v", err)
+			cp.Clear()
+		}
 	}
+	if imur.UploadID == "" {
+		if imur, err = bucket.InitiateMultipartUpload(params.Object,
+			oss.SetHeader(driver115.OssSecurityTokenHeaderName, ossToken.SecurityToken),
+			oss.UserAgentHeader(driver115.OSSUserAgent),
+			oss.EnableSha1(), oss.Sequential(),
+		); err != nil {
+			return nil, err
+		}
+		cp.Save(UploadState{Params: *params, Imur: imur})
+	}
+	total := len(parts) + len(chunks)
 
 	wg := sync.WaitGroup{}
 	wg.Add(len(chunks))
@@ -413,8 +441,8 @@ func (d *Pan115) UploadByMultipart(ctx context.Context, params *driver115.Upload
 				if err != nil {
 					errCh <- errors.Wrap(err, fmt.Sprintf("上传 %s 的第%d个分片时出现错误：%v", s.GetName(), chunk.Number, err))
 				} else {
-					num := completedNum.Add(1)
-					up(float64(num) * 100.0 / float64(len(chunks)))
+					num := int(completedNum.Add(1)) + total - len(chunks)
+					up(float64(num) * 100.0 / float64(total))
 				}
 				UploadedPartsCh <- part
 			}
@@ -450,6 +478,8 @@ LOOP:
 		driver115.OssOption(params, ossToken),
 		oss.CallbackResult(&bodyBytes),
 	)...); err != nil {
+		// the uploaded parts may be broken, so upload from scratch next time
+		cp.Clear()
 		return nil, err
 	}
 
@@ -460,6 +490,31 @@ LOOP:
 	return &uploadResult, uploadResult.Err(string(bodyBytes))
 }
 
+// listUploadedParts list all the parts uploaded of imur, the key is the part number
+func listUploadedParts(bucket *oss.Bucket, imur oss.InitiateMultipartUploadResult, ossToken *driver115.UploadOSSTokenResp) (map[int]oss.UploadedPart, error) {
+	parts := make(map[int]oss.UploadedPart)
+	marker := 0
+	for {
+		res, err := bucket.ListUploadedParts(imur,
+			oss.SetHeader(driver115.OssSecurityTokenHeaderName, ossToken.SecurityToken),
+			oss.UserAgentHeader(driver115.OSSUserAgent),
+			oss.PartNumberMarker(marker),
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range res.UploadedParts {
+			parts[p.PartNumber] = p
+		}
+		if !res.IsTruncated {
+			return parts, nil
+		}
+		if marker, err = strconv.Atoi(res.NextPartNumberMarker); err != nil {
+			return nil, err
+		}
+	}
+}
+
 func chunksProducer(ch chan oss.FileChunk, chunks []oss.FileChunk) {
 	for _, chunk := range chunks {
 		ch <- chunk
@@ -2,6 +2,7 @@ package _123
 
 import (
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/listcache"
 	"github.com/alist-org/alist/v3/internal/op"
 )
 
@@ -31,4 +32,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &Pan123{}
 	})
+	listcache.RegisterObjType(File{})
 }
@@ -2,6 +2,7 @@ package _123Share
 
 import (
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/listcache"
 	"github.com/alist-org/alist/v3/internal/op"
 )
 
@@ -41,4 +42,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &Pan123Share{}
 	})
+	listcache.RegisterObjType(File{})
 }
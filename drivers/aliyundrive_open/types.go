@@ -77,6 +77,15 @@ type CreateResp struct {
 	PartInfoList []PartInfo `json:"part_info_list"`
 }
 
+// UploadState is saved to the upload checkpoint to resume the upload
+type UploadState struct {
+	FileId   string `json:"file_id"`
+	UploadId string `json:"upload_id"`
+	PartSize int64  `json:"part_size"`
+	// Parts is the number of parts confirmed
+	Parts int `json:"parts"`
+}
+
 type MoveOrCopyResp struct {
 	Exist   bool   `json:"exist"`
 	DriveID string `json:"drive_id"`
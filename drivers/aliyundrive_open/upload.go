@@ -166,6 +166,22 @@ func (d *AliyundriveOpen) upload(ctx context.Context, dstDir model.Obj, stream m
 	}
 	count := int(math.Ceil(float64(stream.GetSize()) / float64(partSize)))
 	createData["part_info_list"] = makePartInfos(count)
+	// resume the upload created before, the parts uploaded are skipped
+	cp := driver.GetUploadCheckpoint(ctx)
+	var state UploadState
+	if cp.Load(&state) && state.PartSize == partSize && state.FileId != "" {
+		partInfoList, err := d.getUploadUrl(count, state.FileId, state.UploadId)
+		if err == nil {
+			log.Infof("[aliyundrive_open] resume uploading %s from part %d", stream.GetName(), state.Parts+1)
+			return d.uploadParts(ctx, stream, &CreateResp{
+				FileId:       state.FileId,
+				UploadId:     state.UploadId,
+				PartInfoList: partInfoList,
+			}, state, true, up)
+		}
+		log.Warnf("[aliyundrive_open] failed to resume upload: %+v", err)
+		cp.Clear()
+	}
 	// rapid upload
 	rapidUpload := !stream.IsForceStreamUpload() && stream.GetSize() > 100*utils.KB && d.RapidUpload
 	if rapidUpload {
@@ -216,52 +232,67 @@ func (d *AliyundriveOpen) upload(ctx context.Context, dstDir model.Obj, stream m
 		}
 	}
 
-	if !createResp.RapidUpload {
-		// 2. normal upload
-		log.Debugf("[aliyundive_open] normal upload")
+	if createResp.RapidUpload {
+		log.Debugf("[aliyundrive_open] rapid upload success, file id: %s", createResp.FileId)
+		log.Debugf("[aliyundrive_open] create file success, resp: %+v", createResp)
+		// 3. complete
+		return d.completeUpload(createResp.FileId, createResp.UploadId)
+	}
+	state = UploadState{FileId: createResp.FileId, UploadId: createResp.UploadId, PartSize: partSize}
+	cp.Save(state)
+	return d.uploadParts(ctx, stream, &createResp, state, rapidUpload, up)
+}
 
-		preTime := time.Now()
-		var offset, length int64 = 0, partSize
-		//var length
-		for i := 0; i < len(createResp.PartInfoList); i++ {
-			if utils.IsCanceled(ctx) {
-				return nil, ctx.Err()
-			}
-			// refresh upload url if 50 minutes passed
-			if time.Since(preTime) > 50*time.Minute {
-				createResp.PartInfoList, err = d.getUploadUrl(count, createResp.FileId, createResp.UploadId)
-				if err != nil {
-					return nil, err
-				}
-				preTime = time.Now()
-			}
-			if remain := stream.GetSize() - offset; length > remain {
-				length = remain
-			}
-			rd := utils.NewMultiReadable(io.LimitReader(stream, partSize))
-			if rapidUpload {
-				srd, err := stream.RangeRead(http_range.Range{Start: offset, Length: length})
-				if err != nil {
-					return nil, err
-				}
-				rd = utils.NewMultiReadable(srd)
+// uploadParts upload the parts after state.Parts, the stream is read by range if rangeRead,
+// otherwise it's read sequentially from the start
+func (d *AliyundriveOpen) uploadParts(ctx context.Context, stream model.FileStreamer, createResp *CreateResp, state UploadState, rangeRead bool, up driver.UpdateProgress) (model.Obj, error) {
+	// 2. normal upload
+	log.Debugf("[aliyundive_open] normal upload")
+	cp := driver.GetUploadCheckpoint(ctx)
+	partSize := state.PartSize
+	count := len(createResp.PartInfoList)
+	preTime := time.Now()
+	var err error
+	var offset, length = int64(state.Parts) * partSize, partSize
+	//var length
+	for i := state.Parts; i < count; i++ {
+		if utils.IsCanceled(ctx) {
+			return nil, ctx.Err()
+		}
+		// refresh upload url if 50 minutes passed
+		if time.Since(preTime) > 50*time.Minute {
+			createResp.PartInfoList, err = d.getUploadUrl(count, createResp.FileId, createResp.UploadId)
+			if err != nil {
+				return nil, err
 			}
-			err = retry.Do(func() error {
-				_ = rd.Reset()
-				rateLimitedRd := driver.NewLimitedUploadStream(ctx, rd)
-				return d.uploadPart(ctx, rateLimitedRd, createResp.PartInfoList[i])
-			},
-				retry.Attempts(3),
-				retry.DelayType(retry.BackOffDelay),
-				retry.Delay(time.Second))
+			preTime = time.Now()
+		}
+		if remain := stream.GetSize() - offset; length > remain {
+			length = remain
+		}
+		rd := utils.NewMultiReadable(io.LimitReader(stream, partSize))
+		if rangeRead {
+			srd, err := stream.RangeRead(http_range.Range{Start: offset, Length: length})
 			if err != nil {
 				return nil, err
 			}
-			offset += partSize
-			up(float64(i*100) / float64(count))
+			rd = utils.NewMultiReadable(srd)
 		}
-	} else {
-		log.Debugf("[aliyundrive_open] rapid upload success, file id: %s", createResp.FileId)
+		err = retry.Do(func() error {
+			_ = rd.Reset()
+			rateLimitedRd := driver.NewLimitedUploadStream(ctx, rd)
+			return d.uploadPart(ctx, rateLimitedRd, createResp.PartInfoList[i])
+		},
+			retry.Attempts(3),
+			retry.DelayType(retry.BackOffDelay),
+			retry.Delay(time.Second))
+		if err != nil {
+			return nil, err
+		}
+		offset += partSize
+		state.Parts = i + 1
+		cp.Save(state)
+		up(float64(i*100) / float64(count))
 	}
 
 	log.Debugf("[aliyundrive_open] create file success, resp: %+v", createResp)
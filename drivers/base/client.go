@@ -7,6 +7,7 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/net"
+	"github.com/alist-org/alist/v3/internal/tracing"
 	"github.com/go-resty/resty/v2"
 )
 
@@ -25,9 +26,13 @@ func InitClient() {
 		}),
 	).SetTLSClientConfig(&tls.Config{InsecureSkipVerify: conf.Conf.TlsInsecureSkipVerify})
 	NoRedirectClient.SetHeader("user-agent", UserAgent)
+	traced(NoRedirectClient)
 
 	RestyClient = NewRestyClient()
 	HttpClient = net.NewHttpClient()
+	if conf.Conf.Tracing.Enable {
+		HttpClient.Transport = &tracing.Transport{Base: HttpClient.Transport}
+	}
 }
 
 func NewRestyClient() *resty.Client {
@@ -37,5 +42,14 @@ func NewRestyClient() *resty.Client {
 		SetRetryResetReaders(true).
 		SetTimeout(DefaultTimeout).
 		SetTLSClientConfig(&tls.Config{InsecureSkipVerify: conf.Conf.TlsInsecureSkipVerify})
+	return traced(client)
+}
+
+// traced trace the requests of the client if tracing is enabled,
+// the TLS config and proxy of the client can't be changed after it
+func traced(client *resty.Client) *resty.Client {
+	if conf.Conf.Tracing.Enable {
+		client.SetTransport(&tracing.Transport{Base: client.GetClient().Transport})
+	}
 	return client
 }
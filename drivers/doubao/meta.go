@@ -2,6 +2,7 @@ package doubao
 
 import (
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/listcache"
 	"github.com/alist-org/alist/v3/internal/op"
 )
 
@@ -33,4 +34,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &Doubao{}
 	})
+	listcache.RegisterObjType(&Object{})
 }
@@ -2,6 +2,7 @@ package doubao_share
 
 import (
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/listcache"
 	"github.com/alist-org/alist/v3/internal/op"
 )
 
@@ -29,4 +30,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &DoubaoShare{}
 	})
+	listcache.RegisterObjType(&FileObject{})
 }
@@ -27,6 +27,7 @@ type File struct {
 	CreatedTime     time.Time `json:"createdTime"`
 	Size            string    `json:"size"`
 	ThumbnailLink   string    `json:"thumbnailLink"`
+	Parents         []string  `json:"parents"`
 	ShortcutDetails struct {
 		TargetId       string `json:"targetId"`
 		TargetMimeType string `json:"targetMimeType"`
@@ -65,6 +66,26 @@ func fileToObj(f File) *model.ObjThumb {
 	return obj
 }
 
+type StartPageToken struct {
+	StartPageToken string `json:"startPageToken"`
+}
+
+type Changes struct {
+	NextPageToken     string   `json:"nextPageToken"`
+	NewStartPageToken string   `json:"newStartPageToken"`
+	Changes           []Change `json:"changes"`
+}
+
+type Change struct {
+	FileId  string `json:"fileId"`
+	Removed bool   `json:"removed"`
+	File    *struct {
+		Id      string   `json:"id"`
+		Name    string   `json:"name"`
+		Parents []string `json:"parents"`
+	} `json:"file"`
+}
+
 type Error struct {
 	Error struct {
 		Errors []struct {
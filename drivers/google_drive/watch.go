@@ -0,0 +1,146 @@
+package google_drive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+// the max depth of the folders walked up to resolve the path of a change
+const maxParentDepth = 64
+
+// Watch poll the changes with the changes api
+// ApiDoc: https://developers.google.com/drive/api/guides/manage-changes
+func (d *GoogleDrive) Watch(ctx context.Context, notify func(dir string)) error {
+	if d.WatchInterval <= 0 {
+		return errs.NotSupport
+	}
+	// the root folder may be the alias "root", the parents of changes are the real ids
+	root, err := d.getFolder(ctx, d.RootFolderID)
+	if err != nil {
+		return err
+	}
+	// only the changes afterwards are needed
+	var start StartPageToken
+	_, err = d.request("https://www.googleapis.com/drive/v3/changes/startPageToken", http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx)
+	}, &start)
+	if err != nil {
+		return err
+	}
+	token := start.StartPageToken
+	ticker := time.NewTicker(time.Duration(d.WatchInterval) * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		dirs := make(map[string]struct{})
+		r := &pathResolver{root: root.Id, paths: make(map[string]string), get: func(id string) (File, error) {
+			return d.getFolder(ctx, id)
+		}}
+		next, err := d.changes(ctx, token, func(change Change) {
+			// the removed files have no parents, so everything should be refreshed
+			if change.Removed || change.File == nil || len(change.File.Parents) == 0 {
+				dirs["/"] = struct{}{}
+				return
+			}
+			for _, parent := range change.File.Parents {
+				if dir, ok := r.resolve(parent, maxParentDepth); !ok {
+					dirs["/"] = struct{}{}
+				} else if dir != "" {
+					dirs[dir] = struct{}{}
+				}
+			}
+		})
+		if err != nil {
+			utils.Log.Warnf("[google_drive] failed to get changes of %s: %+v", d.MountPath, err)
+			continue
+		}
+		token = next
+		for dir := range dirs {
+			notify(dir)
+		}
+	}
+}
+
+// changes walk through the pages of changes and return the token for the next round
+func (d *GoogleDrive) changes(ctx context.Context, token string, fn func(change Change)) (string, error) {
+	for {
+		var resp Changes
+		_, err := d.request("https://www.googleapis.com/drive/v3/changes", http.MethodGet, func(req *resty.Request) {
+			req.SetContext(ctx).SetQueryParams(map[string]string{
+				"pageToken":      token,
+				"pageSize":       "1000",
+				"includeRemoved": "true",
+				"fields":         "nextPageToken,newStartPageToken,changes(fileId,removed,file(id,name,parents))",
+			})
+		}, &resp)
+		if err != nil {
+			return "", err
+		}
+		for _, change := range resp.Changes {
+			fn(change)
+		}
+		if resp.NewStartPageToken != "" {
+			return resp.NewStartPageToken, nil
+		}
+		if resp.NextPageToken == "" {
+			return "", fmt.Errorf("neither nextPageToken nor newStartPageToken is returned")
+		}
+		token = resp.NextPageToken
+	}
+}
+
+func (d *GoogleDrive) getFolder(ctx context.Context, id string) (File, error) {
+	var file File
+	_, err := d.request("https://www.googleapis.com/drive/v3/files/"+id, http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx).SetQueryParam("fields", "id,name,parents")
+	}, &file)
+	return file, err
+}
+
+// pathResolver resolve the path (from the root folder of storage) of the folders by walking up
+// their parents, the folders out of the root folder are resolved to ""
+type pathResolver struct {
+	root  string
+	paths map[string]string
+	get   func(id string) (File, error)
+}
+
+// resolve returns false if the path can't be resolved, e.g. the parent can't be got
+func (r *pathResolver) resolve(id string, depth int) (string, bool) {
+	if id == r.root {
+		return "/", true
+	}
+	if p, ok := r.paths[id]; ok {
+		return p, true
+	}
+	if depth <= 0 {
+		return "", false
+	}
+	folder, err := r.get(id)
+	if err != nil {
+		return "", false
+	}
+	p := ""
+	if len(folder.Parents) > 0 {
+		parent, ok := r.resolve(folder.Parents[0], depth-1)
+		if !ok {
+			return "", false
+		}
+		if parent != "" {
+			p = stdpath.Join(parent, folder.Name)
+		}
+	}
+	r.paths[id] = p
+	return p, true
+}
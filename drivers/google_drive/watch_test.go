@@ -0,0 +1,46 @@
+package google_drive
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPathResolver(t *testing.T) {
+	folders := map[string]File{
+		"a":     {Id: "a", Name: "a", Parents: []string{"root1"}},
+		"b":     {Id: "b", Name: "b", Parents: []string{"a"}},
+		"root1": {Id: "root1", Name: "root", Parents: []string{"drive"}},
+		"other": {Id: "other", Name: "other", Parents: []string{"drive"}},
+		"drive": {Id: "drive", Name: "My Drive"},
+		"lost":  {Id: "lost", Name: "lost", Parents: []string{"missing"}},
+	}
+	calls := 0
+	r := &pathResolver{root: "root1", paths: make(map[string]string), get: func(id string) (File, error) {
+		calls++
+		if f, ok := folders[id]; ok {
+			return f, nil
+		}
+		return File{}, errors.New("not found")
+	}}
+	tests := []struct {
+		id     string
+		expect string
+		ok     bool
+	}{
+		{"root1", "/", true},
+		{"a", "/a", true},
+		{"b", "/a/b", true},
+		{"other", "", true},
+		{"lost", "", false},
+	}
+	for _, tt := range tests {
+		if p, ok := r.resolve(tt.id, maxParentDepth); p != tt.expect || ok != tt.ok {
+			t.Errorf("resolve(%s) = %s, %v, want %s, %v", tt.id, p, ok, tt.expect, tt.ok)
+		}
+	}
+	// the resolved folders are cached
+	calls = 0
+	if p, _ := r.resolve("b", maxParentDepth); p != "/a/b" || calls != 0 {
+		t.Errorf("resolve(b) again = %s with %d calls", p, calls)
+	}
+}
@@ -2,6 +2,7 @@ package halalcloud
 
 import (
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/listcache"
 	"github.com/alist-org/alist/v3/internal/op"
 )
 
@@ -35,4 +36,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &HalalCloud{}
 	})
+	listcache.RegisterObjType(&Files{})
 }
@@ -2,6 +2,7 @@ package LenovoNasShare
 
 import (
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/listcache"
 	"github.com/alist-org/alist/v3/internal/op"
 )
 
@@ -30,4 +31,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &LenovoNasShare{}
 	})
+	listcache.RegisterObjType(File{})
 }
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
@@ -22,6 +23,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/times"
+	"github.com/fsnotify/fsnotify"
 	cp "github.com/otiai10/copy"
 	log "github.com/sirupsen/logrus"
 	_ "golang.org/x/image/webp"
@@ -43,6 +45,10 @@ type Local struct {
 
 	// use ffmpeg
 	useFFmpeg bool
+
+	// the folders listed are added to the watcher when watching changes
+	watcher   *fsnotify.Watcher
+	watcherMu sync.Mutex
 }
 
 func (d *Local) Config() driver.Config {
@@ -141,6 +147,7 @@ func (d *Local) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([
 	if err != nil {
 		return nil, err
 	}
+	d.watch(fullPath)
 	var files []model.Obj
 	for _, f := range rawFiles {
 		if !d.ShowHidden && strings.HasPrefix(f.Name(), ".") {
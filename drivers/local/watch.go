@@ -0,0 +1,76 @@
+package local
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+func (d *Local) Watch(ctx context.Context, notify func(dir string)) error {
+	if !d.WatchChanges {
+		return errs.NotSupport
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	d.watcherMu.Lock()
+	d.watcher = watcher
+	d.watcherMu.Unlock()
+	defer func() {
+		d.watcherMu.Lock()
+		d.watcher = nil
+		d.watcherMu.Unlock()
+		_ = watcher.Close()
+	}()
+	// the sub folders are added when they are listed, watching the whole tree may exceed the limit of system
+	d.watch(d.GetRootPath())
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			if dir, ok := d.relPath(filepath.Dir(event.Name)); ok {
+				notify(dir)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warnf("[local] watch error of %s: %+v", d.GetRootPath(), err)
+		}
+	}
+}
+
+// watch add the folder to the watcher if the changes are being watched
+func (d *Local) watch(fullPath string) {
+	d.watcherMu.Lock()
+	defer d.watcherMu.Unlock()
+	if d.watcher == nil {
+		return
+	}
+	if err := d.watcher.Add(fullPath); err != nil {
+		log.Debugf("[local] failed to watch %s: %+v", fullPath, err)
+	}
+}
+
+// relPath convert the full path to the path relative to the root folder
+func (d *Local) relPath(fullPath string) (string, bool) {
+	rel, err := filepath.Rel(d.GetRootPath(), fullPath)
+	if err != nil || rel == ".." || len(rel) > 2 && rel[:3] == ".."+string(filepath.Separator) {
+		return "", false
+	}
+	if rel == "." {
+		return "/", true
+	}
+	return "/" + filepath.ToSlash(rel), true
+}
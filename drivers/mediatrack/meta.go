@@ -2,6 +2,7 @@ package mediatrack
 
 import (
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/listcache"
 	"github.com/alist-org/alist/v3/internal/op"
 )
 
@@ -21,4 +22,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &MediaTrack{}
 	})
+	listcache.RegisterObjType(&Object{})
 }
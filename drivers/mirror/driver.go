@@ -0,0 +1,183 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	stdpath "path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type Mirror struct {
+	model.Storage
+	Addition
+	members []*member
+	next    atomic.Uint32
+}
+
+func (d *Mirror) Config() driver.Config {
+	return config
+}
+
+func (d *Mirror) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Mirror) Init(ctx context.Context) error {
+	d.members = nil
+	for _, path := range strings.Split(d.Paths, "\n") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		d.members = append(d.members, &member{path: utils.FixAndCleanPath(path)})
+	}
+	if len(d.members) == 0 {
+		return errors.New("paths is required")
+	}
+	return nil
+}
+
+func (d *Mirror) Drop(ctx context.Context) error {
+	d.members = nil
+	return nil
+}
+
+func (d *Mirror) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	return tryMembers(ctx, d, func(ctx context.Context, m *member) (model.Obj, error) {
+		obj, err := fs.Get(ctx, stdpath.Join(m.path, path), &fs.GetArgs{NoLog: true})
+		if err != nil {
+			return nil, err
+		}
+		return &model.Object{
+			Path:     path,
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			Modified: obj.ModTime(),
+			IsFolder: obj.IsDir(),
+			HashInfo: obj.GetHash(),
+		}, nil
+	})
+}
+
+func (d *Mirror) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	fsArgs := &fs.ListArgs{NoLog: true, Refresh: args.Refresh}
+	return tryMembers(ctx, d, func(ctx context.Context, m *member) ([]model.Obj, error) {
+		return d.list(ctx, stdpath.Join(m.path, dir.GetPath()), fsArgs)
+	})
+}
+
+func (d *Mirror) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	return tryMembers(ctx, d, func(ctx context.Context, m *member) (*model.Link, error) {
+		return d.link(ctx, stdpath.Join(m.path, file.GetPath()), args)
+	})
+}
+
+func (d *Mirror) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	return d.write(ctx, func(ctx context.Context, m *member, _ int) error {
+		return fs.MakeDir(ctx, stdpath.Join(m.path, parentDir.GetPath(), dirName))
+	})
+}
+
+func (d *Mirror) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.write(ctx, func(ctx context.Context, m *member, _ int) error {
+		return fs.Move(ctx, stdpath.Join(m.path, srcObj.GetPath()), stdpath.Join(m.path, dstDir.GetPath()))
+	})
+}
+
+func (d *Mirror) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.write(ctx, func(ctx context.Context, m *member, _ int) error {
+		return fs.Rename(ctx, stdpath.Join(m.path, srcObj.GetPath()), newName)
+	})
+}
+
+func (d *Mirror) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.write(ctx, func(ctx context.Context, m *member, _ int) error {
+		_, err := fs.Copy(ctx, stdpath.Join(m.path, srcObj.GetPath()), stdpath.Join(m.path, dstDir.GetPath()))
+		return err
+	})
+}
+
+func (d *Mirror) Remove(ctx context.Context, obj model.Obj) error {
+	return d.write(ctx, func(ctx context.Context, m *member, _ int) error {
+		return fs.Remove(ctx, stdpath.Join(m.path, obj.GetPath()))
+	})
+}
+
+func (d *Mirror) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	// the content is read once for each member, so it has to be cached
+	var file model.File
+	if d.WriteAll && len(d.members) > 1 {
+		var err error
+		if file, err = s.CacheFullInTempFile(); err != nil {
+			return err
+		}
+	}
+	return d.write(ctx, func(ctx context.Context, m *member, i int) error {
+		// the usage of the user has been counted by the upload to the mirror,
+		// so the members are put by op instead of fs
+		storage, dstDirActualPath, err := op.GetStorageAndActualPath(stdpath.Join(m.path, dstDir.GetPath()))
+		if err != nil {
+			return err
+		}
+		if file == nil {
+			return op.Put(ctx, storage, dstDirActualPath, s, up)
+		}
+		var progress driver.UpdateProgress
+		// only the progress of the primary is reported
+		if i == 0 {
+			progress = up
+		}
+		return op.Put(ctx, storage, dstDirActualPath, &stream.FileStream{
+			Ctx:      ctx,
+			Obj:      s,
+			Reader:   sectionFile{io.NewSectionReader(file, 0, s.GetSize())},
+			Mimetype: s.GetMimetype(),
+		}, progress)
+	})
+}
+
+// write apply the change to the primary, or to all members if WriteAll is enabled,
+// the others are not changed if the primary failed
+func (d *Mirror) write(ctx context.Context, fn func(ctx context.Context, m *member, i int) error) error {
+	if !d.Writable {
+		return errs.PermissionDenied
+	}
+	members := d.members[:1]
+	if d.WriteAll {
+		members = d.members
+	}
+	var errList []error
+	for i, m := range members {
+		if err := fn(ctx, m, i); err != nil {
+			if i == 0 {
+				return err
+			}
+			errList = append(errList, fmt.Errorf("failed write to %s: %w", m.path, err))
+		}
+	}
+	return errors.Join(errList...)
+}
+
+var _ driver.Driver = (*Mirror)(nil)
+var _ driver.Getter = (*Mirror)(nil)
@@ -0,0 +1,35 @@
+package mirror
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	Paths        string `json:"paths" required:"true" type:"text" help:"One path per line, the first one is the primary"`
+	ReadStrategy string `json:"read_strategy" type:"select" options:"failover,round_robin" default:"failover" help:"failover reads from the first available member, round_robin spreads the reads to all members"`
+	Cooldown     int    `json:"cooldown" type:"number" default:"60" help:"Seconds to skip a member after it errors"`
+	Timeout      int    `json:"timeout" type:"number" default:"0" help:"Timeout of each member in seconds, 0 for no timeout"`
+	Writable     bool   `json:"writable" type:"bool" default:"false"`
+	WriteAll     bool   `json:"write_all" type:"bool" default:"false" help:"Write to all members, otherwise only to the primary"`
+}
+
+var config = driver.Config{
+	Name:             "Mirror",
+	LocalSort:        true,
+	NoCache:          true,
+	NoUpload:         false,
+	DefaultRoot:      "/",
+	ProxyRangeOption: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Mirror{
+			Addition: Addition{
+				ReadStrategy: "failover",
+				Cooldown:     60,
+			},
+		}
+	})
+}
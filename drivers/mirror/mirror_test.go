@@ -0,0 +1,59 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+)
+
+func TestTryMembers(t *testing.T) {
+	d := &Mirror{Addition: Addition{Paths: "/a\n/b\n/c", ReadStrategy: "failover", Cooldown: 60}}
+	if err := d.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	try := func(failed map[string]error) (string, []string) {
+		var tried []string
+		res, err := tryMembers(context.Background(), d, func(ctx context.Context, m *member) (string, error) {
+			tried = append(tried, m.path)
+			if err := failed[m.path]; err != nil {
+				return "", err
+			}
+			return m.path, nil
+		})
+		if err != nil {
+			t.Fatalf("failed to try members: %+v", err)
+		}
+		return res, tried
+	}
+
+	// the object not found doesn't put the member into cooldown
+	if res, _ := try(map[string]error{"/a": errs.ObjectNotFound}); res != "/b" {
+		t.Errorf("expected /b, got %s", res)
+	}
+	if res, _ := try(nil); res != "/a" {
+		t.Errorf("expected /a, got %s", res)
+	}
+	// the failed primary is tried at last until the cooldown ends
+	if res, _ := try(map[string]error{"/a": errors.New("429 too many requests")}); res != "/b" {
+		t.Errorf("expected /b, got %s", res)
+	}
+	if res, tried := try(nil); res != "/b" || len(tried) != 1 {
+		t.Errorf("expected /b without trying /a, got %s after %v", res, tried)
+	}
+	d.members[0].recover()
+	if res, _ := try(nil); res != "/a" {
+		t.Errorf("expected /a after recovered, got %s", res)
+	}
+
+	d.ReadStrategy = "round_robin"
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		res, _ := try(nil)
+		seen[res] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("expected the reads to be spread to all members, got %v", seen)
+	}
+}
@@ -0,0 +1,42 @@
+package mirror
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+type member struct {
+	path        string
+	mu          sync.Mutex
+	failedUntil time.Time
+}
+
+func (m *member) available() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return time.Now().After(m.failedUntil)
+}
+
+// fail skip the member for the cooldown, so the next requests go to the others directly
+func (m *member) fail(cooldown time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failedUntil = time.Now().Add(cooldown)
+}
+
+func (m *member) recover() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failedUntil = time.Time{}
+}
+
+// sectionFile is the content of the uploaded file cached for each member, the cache is
+// removed by the stream of the upload, so it needn't be closed
+type sectionFile struct {
+	*io.SectionReader
+}
+
+func (f sectionFile) Close() error {
+	return nil
+}
@@ -0,0 +1,124 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"slices"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	log "github.com/sirupsen/logrus"
+)
+
+// readOrder returns the members in the order to read, the ones in cooldown are tried at last
+func (d *Mirror) readOrder() []*member {
+	members := slices.Clone(d.members)
+	if d.ReadStrategy == "round_robin" {
+		start := int(d.next.Add(1)) % len(members)
+		members = append(members[start:], members[:start]...)
+	}
+	slices.SortStableFunc(members, func(a, b *member) int {
+		switch aa, ba := a.available(), b.available(); {
+		case aa && !ba:
+			return -1
+		case !aa && ba:
+			return 1
+		}
+		return 0
+	})
+	return members
+}
+
+// tryMembers call fn with the members in the read order until it succeeds, the member is
+// skipped for the cooldown if it failed for other reasons than the object is not found
+func tryMembers[T any](ctx context.Context, d *Mirror, fn func(ctx context.Context, m *member) (T, error)) (T, error) {
+	var res T
+	var firstErr error
+	for _, m := range d.readOrder() {
+		var err error
+		if d.Timeout > 0 {
+			childCtx, cancel := context.WithTimeout(ctx, time.Duration(d.Timeout)*time.Second)
+			res, err = fn(childCtx, m)
+			cancel()
+		} else {
+			res, err = fn(ctx, m)
+		}
+		if err == nil {
+			m.recover()
+			return res, nil
+		}
+		// the request is canceled by the client
+		if ctx.Err() != nil {
+			return res, err
+		}
+		if !errs.IsObjectNotFound(err) {
+			log.Warnf("mirror member %s failed, try the next one: %+v", m.path, err)
+			m.fail(time.Duration(d.Cooldown) * time.Second)
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return res, firstErr
+}
+
+func (d *Mirror) list(ctx context.Context, path string, args *fs.ListArgs) ([]model.Obj, error) {
+	objs, err := fs.List(ctx, path, args)
+	if err != nil {
+		return nil, err
+	}
+	return utils.SliceConvert(objs, func(obj model.Obj) (model.Obj, error) {
+		thumb, ok := model.GetThumb(obj)
+		objRes := model.Object{
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			Modified: obj.ModTime(),
+			IsFolder: obj.IsDir(),
+			HashInfo: obj.GetHash(),
+		}
+		if !ok {
+			return &objRes, nil
+		}
+		return &model.ObjThumb{
+			Object: objRes,
+			Thumbnail: model.Thumbnail{
+				Thumbnail: thumb,
+			},
+		}, nil
+	})
+}
+
+func (d *Mirror) link(ctx context.Context, reqPath string, args model.LinkArgs) (*model.Link, error) {
+	storage, reqActualPath, err := op.GetStorageAndActualPath(reqPath)
+	if err != nil {
+		return nil, err
+	}
+	if !args.Redirect {
+		link, _, err := op.Link(ctx, storage, reqActualPath, args)
+		return link, err
+	}
+	if _, err = fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true}); err != nil {
+		return nil, err
+	}
+	if common.ShouldProxy(storage, stdpath.Base(reqPath)) {
+		link := &model.Link{
+			URL: fmt.Sprintf("%s/p%s?sign=%s",
+				common.GetApiUrl(args.HttpReq),
+				utils.EncodePath(reqPath, true),
+				sign.Sign(reqPath)),
+		}
+		if args.HttpReq != nil && d.ProxyRange {
+			link.RangeReadCloser = common.NoProxyRange
+		}
+		return link, nil
+	}
+	link, _, err := op.Link(ctx, storage, reqActualPath, args)
+	return link, err
+}
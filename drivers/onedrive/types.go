@@ -89,3 +89,23 @@ type FileSystemInfoFacet struct {
 	CreatedDateTime      time.Time `json:"createdDateTime,omitempty"`      // The UTC date and time the file was created on a client.
 	LastModifiedDateTime time.Time `json:"lastModifiedDateTime,omitempty"` // The UTC date and time the file was last modified on a client.
 }
+
+type DeltaItem struct {
+	Id              string `json:"id"`
+	Name            string `json:"name"`
+	ParentReference struct {
+		Id   string `json:"id"`
+		Path string `json:"path"`
+	} `json:"parentReference"`
+}
+
+type DeltaResp struct {
+	Value     []DeltaItem `json:"value"`
+	NextLink  string      `json:"@odata.nextLink"`
+	DeltaLink string      `json:"@odata.deltaLink"`
+}
+
+type UploadSession struct {
+	UploadUrl          string   `json:"uploadUrl"`
+	NextExpectedRanges []string `json:"nextExpectedRanges,omitempty"`
+}
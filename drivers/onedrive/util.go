@@ -8,6 +8,8 @@ import (
 	"io"
 	"net/http"
 	stdpath "path"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alist-org/alist/v3/drivers/base"
@@ -15,6 +17,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/go-resty/resty/v2"
 	jsoniter "github.com/json-iterator/go"
@@ -192,17 +195,60 @@ func toAPIMetadata(stream model.FileStreamer) Metadata {
 	return metadata
 }
 
-func (d *Onedrive) upBig(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
-	url := d.GetMetaUrl(false, stdpath.Join(dstDir.GetPath(), stream.GetName())) + "/createUploadSession"
-	metadata := map[string]interface{}{"item": toAPIMetadata(stream)}
-	res, err := d.Request(url, http.MethodPost, func(req *resty.Request) {
-		req.SetBody(metadata).SetContext(ctx)
-	}, nil)
+// getUploadOffset get the offset to continue the upload session from
+func (d *Onedrive) getUploadOffset(ctx context.Context, uploadUrl string) (int64, error) {
+	var resp UploadSession
+	res, err := base.RestyClient.R().SetContext(ctx).SetResult(&resp).Get(uploadUrl)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if res.StatusCode() != http.StatusOK {
+		return 0, fmt.Errorf("get upload session status: %d", res.StatusCode())
 	}
-	uploadUrl := jsoniter.Get(res, "uploadUrl").ToString()
+	if len(resp.NextExpectedRanges) == 0 {
+		return 0, fmt.Errorf("no expected ranges of upload session")
+	}
+	start, _, _ := strings.Cut(resp.NextExpectedRanges[0], "-")
+	return strconv.ParseInt(start, 10, 64)
+}
+
+func (d *Onedrive) upBig(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
 	var finish int64 = 0
+	var uploadUrl string
+	// resume the upload session created before
+	cp := driver.GetUploadCheckpoint(ctx)
+	var state UploadSession
+	if cp.Load(&state) && state.UploadUrl != "" {
+		offset, err := d.getUploadOffset(ctx, state.UploadUrl)
+		if err == nil && offset <= stream.GetSize() {
+			uploadUrl, finish = state.UploadUrl, offset
+			utils.Log.Infof("[Onedrive] resume uploading %s from %d", stream.GetName(), finish)
+		} else {
+			utils.Log.Warnf("[Onedrive] failed to resume upload session: %v", err)
+			cp.Clear()
+		}
+	}
+	if uploadUrl == "" {
+		url := d.GetMetaUrl(false, stdpath.Join(dstDir.GetPath(), stream.GetName())) + "/createUploadSession"
+		metadata := map[string]interface{}{"item": toAPIMetadata(stream)}
+		res, err := d.Request(url, http.MethodPost, func(req *resty.Request) {
+			req.SetBody(metadata).SetContext(ctx)
+		}, nil)
+		if err != nil {
+			return err
+		}
+		uploadUrl = jsoniter.Get(res, "uploadUrl").ToString()
+		// the offset is queried from the session when resuming, so only the url is saved
+		cp.Save(UploadSession{UploadUrl: uploadUrl})
+	}
+	var reader io.Reader = stream
+	if finish > 0 {
+		var err error
+		reader, err = stream.RangeRead(http_range.Range{Start: finish, Length: stream.GetSize() - finish})
+		if err != nil {
+			return err
+		}
+	}
 	DEFAULT := d.ChunkSize * 1024 * 1024
 	retryCount := 0
 	maxRetries := 3
@@ -214,7 +260,7 @@ func (d *Onedrive) upBig(ctx context.Context, dstDir model.Obj, stream model.Fil
 		byteSize := min(left, DEFAULT)
 		utils.Log.Debugf("[Onedrive] upload range: %d-%d/%d", finish, finish+byteSize-1, stream.GetSize())
 		byteData := make([]byte, byteSize)
-		n, err := io.ReadFull(stream, byteData)
+		n, err := io.ReadFull(reader, byteData)
 		utils.Log.Debug(err, n)
 		if err != nil {
 			return err
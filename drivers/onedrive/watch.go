@@ -0,0 +1,135 @@
+package onedrive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+// Watch poll the changes with the delta api
+// ApiDoc: https://learn.microsoft.com/en-us/onedrive/developer/rest-api/api/driveitem_delta
+func (d *Onedrive) Watch(ctx context.Context, notify func(dir string)) error {
+	if d.WatchInterval <= 0 {
+		return errs.NotSupport
+	}
+	// only the changes afterwards are needed
+	link, err := d.delta(ctx, d.GetMetaUrl(false, "/")+"/delta?token=latest", nil)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(time.Duration(d.WatchInterval) * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		dirs := make(map[string]struct{})
+		parents := make(map[string]string)
+		next, err := d.delta(ctx, link, func(item DeltaItem) {
+			dir, ok := d.deltaParentPath(ctx, item, parents)
+			if !ok {
+				// the path can't be resolved, so everything should be refreshed
+				dirs["/"] = struct{}{}
+			} else if dir, ok = d.toStoragePath(dir); ok {
+				dirs[dir] = struct{}{}
+			}
+		})
+		if err != nil {
+			utils.Log.Warnf("[onedrive] failed to get changes of %s: %+v", d.MountPath, err)
+			continue
+		}
+		link = next
+		for dir := range dirs {
+			notify(dir)
+		}
+	}
+}
+
+// delta walk through the pages of changes and return the link for the next round
+func (d *Onedrive) delta(ctx context.Context, link string, fn func(item DeltaItem)) (string, error) {
+	for {
+		var resp DeltaResp
+		_, err := d.Request(link, http.MethodGet, func(req *resty.Request) {
+			req.SetContext(ctx)
+		}, &resp)
+		if err != nil {
+			return "", err
+		}
+		if fn != nil {
+			for _, item := range resp.Value {
+				fn(item)
+			}
+		}
+		if resp.DeltaLink != "" {
+			return resp.DeltaLink, nil
+		}
+		if resp.NextLink == "" {
+			return "", fmt.Errorf("neither nextLink nor deltaLink is returned")
+		}
+		link = resp.NextLink
+	}
+}
+
+// deltaParentPath get the path (from the root of drive) of the parent of item,
+// the path isn't returned by delta of personal accounts, so it's queried by the id of parent
+func (d *Onedrive) deltaParentPath(ctx context.Context, item DeltaItem, parents map[string]string) (string, bool) {
+	if p, ok := drivePath(item.ParentReference.Path); ok {
+		return p, true
+	}
+	if item.ParentReference.Id == "" {
+		// the root of drive itself
+		return "", false
+	}
+	if p, ok := parents[item.ParentReference.Id]; ok {
+		return p, p != ""
+	}
+	var parent DeltaItem
+	_, err := d.Request(d.itemUrl(item.ParentReference.Id)+"?$select=id,name,parentReference", http.MethodGet, func(req *resty.Request) {
+		req.SetContext(ctx)
+	}, &parent)
+	p := ""
+	if err == nil {
+		if pp, ok := drivePath(parent.ParentReference.Path); ok {
+			p = stdpath.Join(pp, parent.Name)
+		} else if parent.ParentReference.Id == "" {
+			p = "/"
+		}
+	}
+	parents[item.ParentReference.Id] = p
+	return p, p != ""
+}
+
+func (d *Onedrive) itemUrl(id string) string {
+	host := onedriveHostMap[d.Region]
+	if d.IsSharepoint {
+		return fmt.Sprintf("%s/v1.0/sites/%s/drive/items/%s", host.Api, d.SiteId, id)
+	}
+	return fmt.Sprintf("%s/v1.0/me/drive/items/%s", host.Api, id)
+}
+
+// drivePath convert the path of parentReference like /drive/root:/a/b to /a/b
+func drivePath(p string) (string, bool) {
+	i := strings.Index(p, "root:")
+	if i < 0 {
+		return "", false
+	}
+	return utils.FixAndCleanPath(p[i+len("root:"):]), true
+}
+
+// toStoragePath convert the path from the root of drive to the path from the root folder of storage
+func (d *Onedrive) toStoragePath(p string) (string, bool) {
+	root := utils.FixAndCleanPath(d.RootFolderPath)
+	if !utils.IsSubPath(root, p) {
+		return "", false
+	}
+	return utils.FixAndCleanPath(strings.TrimPrefix(p, root)), true
+}
@@ -2,6 +2,7 @@ package onedrive_app
 
 import (
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/listcache"
 	"github.com/alist-org/alist/v3/internal/op"
 )
 
@@ -26,4 +27,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &OnedriveAPP{}
 	})
+	listcache.RegisterObjType(&Object{})
 }
@@ -2,6 +2,7 @@ package quark_uc_tv
 
 import (
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/listcache"
 	"github.com/alist-org/alist/v3/internal/op"
 )
 
@@ -64,4 +65,5 @@ func init() {
 			},
 		}
 	})
+	listcache.RegisterObjType(&Files{})
 }
@@ -170,6 +170,9 @@ func (d *S3) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up
 	key := getKey(stdpath.Join(dstDir.GetPath(), s.GetName()), false)
 	contentType := s.GetMimetype()
 	log.Debugln("key:", key)
+	if driver.HasUploadCheckpoint(ctx) && s.GetSize() > s3manager.DefaultUploadPartSize {
+		return d.putResumable(ctx, key, s, up)
+	}
 	input := &s3manager.UploadInput{
 		Bucket: &d.Bucket,
 		Key:    &key,
@@ -0,0 +1,124 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	log "github.com/sirupsen/logrus"
+)
+
+// UploadState is saved to the upload checkpoint to resume the multipart upload
+type UploadState struct {
+	Key      string `json:"key"`
+	UploadId string `json:"upload_id"`
+	PartSize int64  `json:"part_size"`
+}
+
+// putResumable upload the parts one by one, the parts confirmed are listed from the storage when resuming.
+// the multipart upload is not aborted on failure, so that it can be resumed by the next retry
+func (d *S3) putResumable(ctx context.Context, key string, s model.FileStreamer, up driver.UpdateProgress) error {
+	size := s.GetSize()
+	partSize := int64(s3manager.DefaultUploadPartSize)
+	if size > s3manager.MaxUploadParts*partSize {
+		partSize = size / (s3manager.MaxUploadParts - 1)
+	}
+	cp := driver.GetUploadCheckpoint(ctx)
+	var state UploadState
+	var parts []*s3.CompletedPart
+	if cp.Load(&state) && state.Key == key && state.PartSize == partSize && state.UploadId != "" {
+		var err error
+		parts, err = d.listParts(ctx, state)
+		if err != nil {
+			log.Warnf("[s3] failed to resume upload of %s: %+v", key, err)
+			cp.Clear()
+			state = UploadState{}
+		} else {
+			log.Infof("[s3] resume uploading %s from part %d", key, len(parts)+1)
+		}
+	} else {
+		state = UploadState{}
+	}
+	if state.UploadId == "" {
+		contentType := s.GetMimetype()
+		out, err := d.client.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:      &d.Bucket,
+			Key:         &key,
+			ContentType: &contentType,
+		})
+		if err != nil {
+			return err
+		}
+		state = UploadState{Key: key, UploadId: *out.UploadId, PartSize: partSize}
+		cp.Save(state)
+	}
+
+	offset := int64(len(parts)) * partSize
+	var reader io.Reader = s
+	if offset > 0 {
+		var err error
+		reader, err = s.RangeRead(http_range.Range{Start: offset, Length: size - offset})
+		if err != nil {
+			return err
+		}
+	}
+	buf := make([]byte, partSize)
+	for num := int64(len(parts)) + 1; offset < size; num++ {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		n := min(partSize, size-offset)
+		if _, err := io.ReadFull(reader, buf[:n]); err != nil {
+			return err
+		}
+		if err := driver.ServerUploadLimitWaitN(ctx, int(n)); err != nil {
+			return err
+		}
+		out, err := d.client.UploadPartWithContext(ctx, &s3.UploadPartInput{
+			Bucket:     &d.Bucket,
+			Key:        &key,
+			UploadId:   &state.UploadId,
+			PartNumber: aws.Int64(num),
+			Body:       bytes.NewReader(buf[:n]),
+		})
+		if err != nil {
+			return err
+		}
+		parts = append(parts, &s3.CompletedPart{ETag: out.ETag, PartNumber: aws.Int64(num)})
+		offset += n
+		up(float64(offset) * 100 / float64(size))
+	}
+	_, err := d.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          &d.Bucket,
+		Key:             &key,
+		UploadId:        &state.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+	return err
+}
+
+// listParts list the continuous parts uploaded from the first one
+func (d *S3) listParts(ctx context.Context, state UploadState) ([]*s3.CompletedPart, error) {
+	var parts []*s3.CompletedPart
+	err := d.client.ListPartsPagesWithContext(ctx, &s3.ListPartsInput{
+		Bucket:   &d.Bucket,
+		Key:      &state.Key,
+		UploadId: &state.UploadId,
+	}, func(out *s3.ListPartsOutput, lastPage bool) bool {
+		for _, p := range out.Parts {
+			if aws.Int64Value(p.PartNumber) != int64(len(parts))+1 || aws.Int64Value(p.Size) != state.PartSize {
+				return false
+			}
+			parts = append(parts, &s3.CompletedPart{ETag: p.ETag, PartNumber: p.PartNumber})
+		}
+		return true
+	})
+	return parts, err
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/listcache"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
 )
@@ -105,4 +106,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &ThunderExpert{}
 	})
+	listcache.RegisterObjType(&Files{})
 }
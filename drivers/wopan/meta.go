@@ -2,6 +2,7 @@ package template
 
 import (
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/listcache"
 	"github.com/alist-org/alist/v3/internal/op"
 )
 
@@ -34,4 +35,5 @@ func init() {
 	op.RegisterDriver(func() driver.Driver {
 		return &Wopan{}
 	})
+	listcache.RegisterObjType(&Object{})
 }
@@ -0,0 +1,171 @@
+// Package audit records the file operations done by the users of all the protocols
+package audit
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	queueSize = 4096
+	batchSize = 100
+)
+
+var (
+	mu    sync.RWMutex
+	queue chan *model.AuditLog
+	done  chan struct{}
+)
+
+// Init start to save the audit logs, the logs recorded before it are dropped
+func Init() {
+	mu.Lock()
+	defer mu.Unlock()
+	if !conf.Conf.Audit.Enable || queue != nil {
+		return
+	}
+	queue = make(chan *model.AuditLog, queueSize)
+	done = make(chan struct{})
+	go work(queue, done)
+}
+
+// Stop save the queued logs and stop
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+	if queue == nil {
+		return
+	}
+	close(queue)
+	<-done
+	queue = nil
+}
+
+// Record add an audit log of the operation, the user, client ip and protocol are taken from ctx
+func Record(ctx context.Context, operation, srcPath, dstPath string, size int64, err error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if queue == nil {
+		return
+	}
+	l := &model.AuditLog{
+		Time:      time.Now(),
+		Operation: operation,
+		SrcPath:   srcPath,
+		DstPath:   dstPath,
+		Size:      size,
+		Success:   err == nil,
+	}
+	if err != nil {
+		l.Error = err.Error()
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		l.UserID, l.Username = user.ID, user.Username
+	}
+	if ip, ok := ctx.Value(conf.ClientIPKey).(string); ok {
+		l.IP = stripPort(ip)
+	}
+	if protocol, ok := ctx.Value(conf.ProtocolKey).(string); ok {
+		l.Protocol = protocol
+	}
+	select {
+	case queue <- l:
+	default:
+		log.Warnf("audit log queue is full, dropped: %s %s by %s", operation, srcPath, l.Username)
+	}
+}
+
+func stripPort(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func work(queue chan *model.AuditLog, done chan struct{}) {
+	defer close(done)
+	var file *os.File
+	if conf.Conf.Audit.File != "" {
+		var err error
+		file, err = os.OpenFile(conf.Conf.Audit.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			log.Errorf("failed to open audit log file: %+v", err)
+		} else {
+			defer file.Close()
+		}
+	}
+	batch := make([]*model.AuditLog, 0, batchSize)
+	for l := range queue {
+		batch = append(batch[:0], l)
+		// save the logs in the queue together
+	drain:
+		for len(batch) < batchSize {
+			select {
+			case l, ok := <-queue:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, l)
+			default:
+				break drain
+			}
+		}
+		save(batch, file)
+	}
+}
+
+func save(batch []*model.AuditLog, file *os.File) {
+	if err := db.CreateAuditLogs(batch); err != nil {
+		log.Errorf("failed to save %d audit logs: %+v", len(batch), err)
+	}
+	if file == nil {
+		return
+	}
+	var buf []byte
+	for _, l := range batch {
+		b, err := utils.Json.Marshal(l)
+		if err != nil {
+			continue
+		}
+		buf = append(append(buf, b...), '\n')
+	}
+	if _, err := file.Write(buf); err != nil {
+		log.Errorf("failed to write audit log file: %+v", err)
+	}
+}
+
+func GetLogs(filter model.AuditLogFilter, pageIndex, pageSize int) ([]model.AuditLog, int64, error) {
+	if filter.Path != "" {
+		filter.Path = utils.FixAndCleanPath(filter.Path)
+	}
+	return db.GetAuditLogs(filter, pageIndex, pageSize)
+}
+
+// CleanExpired delete the audit logs by the retention setting
+func CleanExpired() {
+	item, _ := op.GetSettingItemByKey(conf.AuditRetentionDays)
+	if item == nil {
+		return
+	}
+	days, err := strconv.Atoi(item.Value)
+	if err != nil || days <= 0 {
+		return
+	}
+	n, err := db.DeleteAuditLogsBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Errorf("failed to clean expired audit logs: %+v", err)
+	} else if n > 0 {
+		log.Infof("%d expired audit logs are deleted", n)
+	}
+}
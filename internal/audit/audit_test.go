@@ -0,0 +1,133 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func getLogs(t *testing.T, filter model.AuditLogFilter) []model.AuditLog {
+	t.Helper()
+	logs, _, err := audit.GetLogs(filter, 1, model.MaxInt)
+	if err != nil {
+		t.Fatalf("failed get audit logs: %+v", err)
+	}
+	return logs
+}
+
+func TestRecord(t *testing.T) {
+	dir := t.TempDir()
+	conf.Conf.Audit = conf.Audit{Enable: true, File: filepath.Join(dir, "audit.log")}
+	audit.Record(context.Background(), model.AuditRemove, "/dropped", "", 0, nil)
+	audit.Init()
+	user := &model.User{ID: 7, Username: "auditor"}
+	ctx := context.WithValue(context.Background(), "user", user)
+	ctx = context.WithValue(ctx, conf.ClientIPKey, "10.0.0.1:1234")
+	ctx = context.WithValue(ctx, conf.ProtocolKey, model.ProtocolWebDAV)
+	audit.Record(ctx, model.AuditMove, "/record/a", "/record/b", 0, nil)
+	audit.Record(ctx, model.AuditRemove, "/record/c", "", 0, errors.New("denied"))
+	// the queued logs are saved when stopped
+	audit.Stop()
+	audit.Record(ctx, model.AuditRemove, "/record/stopped", "", 0, nil)
+
+	logs := getLogs(t, model.AuditLogFilter{Path: "/record"})
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 logs, got %+v", logs)
+	}
+	failed, moved := logs[0], logs[1]
+	if moved.Operation != model.AuditMove || moved.DstPath != "/record/b" || !moved.Success ||
+		moved.UserID != 7 || moved.Username != "auditor" || moved.IP != "10.0.0.1" || moved.Protocol != model.ProtocolWebDAV {
+		t.Errorf("unexpected log of move: %+v", moved)
+	}
+	if failed.Success || failed.Error != "denied" {
+		t.Errorf("the error should be recorded, got %+v", failed)
+	}
+	if logs = getLogs(t, model.AuditLogFilter{Path: "/dropped"}); len(logs) != 0 {
+		t.Errorf("the logs before init should be dropped, got %+v", logs)
+	}
+	data, err := os.ReadFile(conf.Conf.Audit.File)
+	if err != nil || strings.Count(string(data), "\n") != 2 || !strings.Contains(string(data), `"src_path":"/record/a"`) {
+		t.Errorf("the logs should be written into the file, got %s, %+v", data, err)
+	}
+}
+
+func TestRecordDownload(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("12345"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %+v", err)
+	}
+	root, _ := utils.Json.MarshalToString(map[string]string{"root_folder_path": dir})
+	if _, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: "/down", Addition: root}); err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	conf.Conf.Audit = conf.Audit{Enable: true}
+	audit.Init()
+	ctx := context.WithValue(context.Background(), "user", &model.User{ID: 1, Username: "downloader"})
+	// the link got for the info of the file is not a download
+	if _, _, err := fs.Link(ctx, "/down/a.txt", model.LinkArgs{}); err != nil {
+		t.Fatalf("failed link: %+v", err)
+	}
+	link, _, err := fs.LinkDown(ctx, "/down/a.txt", model.LinkArgs{})
+	if err != nil {
+		t.Fatalf("failed link: %+v", err)
+	}
+	if link.MFile != nil {
+		_ = link.MFile.Close()
+	}
+	audit.Stop()
+	logs := getLogs(t, model.AuditLogFilter{Operation: model.AuditDownload, Path: "/down"})
+	if len(logs) != 1 || logs[0].Size != 5 || logs[0].Username != "downloader" {
+		t.Errorf("only the download should be recorded, got %+v", logs)
+	}
+}
+
+func TestCleanExpired(t *testing.T) {
+	now := time.Now()
+	logs := []*model.AuditLog{
+		{Time: now.AddDate(0, 0, -3), Operation: model.AuditRemove, SrcPath: "/expired/old"},
+		{Time: now.Add(-time.Hour), Operation: model.AuditRemove, SrcPath: "/expired/new"},
+	}
+	if err := db.CreateAuditLogs(logs); err != nil {
+		t.Fatalf("failed to create audit logs: %+v", err)
+	}
+	retention := &model.SettingItem{Key: conf.AuditRetentionDays, Value: "0", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE}
+	if err := op.SaveSettingItem(retention); err != nil {
+		t.Fatalf("failed to save setting: %+v", err)
+	}
+	audit.CleanExpired()
+	if res := getLogs(t, model.AuditLogFilter{Path: "/expired"}); len(res) != 2 {
+		t.Errorf("the logs should be kept forever by 0 days, got %+v", res)
+	}
+	retention.Value = "2"
+	if err := op.SaveSettingItem(retention); err != nil {
+		t.Fatalf("failed to save setting: %+v", err)
+	}
+	audit.CleanExpired()
+	if res := getLogs(t, model.AuditLogFilter{Path: "/expired"}); len(res) != 1 || res[0].SrcPath != "/expired/new" {
+		t.Errorf("only the expired logs should be deleted, got %+v", res)
+	}
+}
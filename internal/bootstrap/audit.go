@@ -0,0 +1,15 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitAudit start to save the audit logs and delete the expired ones periodically
+func InitAudit() {
+	audit.Init()
+	c := cron.NewCron(time.Hour)
+	c.Do(audit.CleanExpired)
+}
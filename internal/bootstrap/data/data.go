@@ -4,6 +4,7 @@ import "github.com/alist-org/alist/v3/cmd/flags"
 
 func InitData() {
 	initUser()
+	migrateDefaultPermissions()
 	initSettings()
 	initTasks()
 	if flags.Dev {
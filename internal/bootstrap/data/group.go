@@ -0,0 +1,50 @@
+package data
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// migrateDefaultPermissions convert the default permission of the auto registered users,
+// which is replaced by the default groups, into a group
+func migrateDefaultPermissions() {
+	migrateDefaultPermission("sso_default_permission", conf.SSODefaultGroups, model.SSO, "sso")
+	migrateDefaultPermission("ldap_default_permission", conf.LdapDefaultGroups, model.LDAP, "ldap")
+}
+
+func migrateDefaultPermission(oldKey, newKey string, settingGroup int, groupName string) {
+	old, err := op.GetSettingItemByKey(oldKey)
+	if err != nil || old.Flag == model.DEPRECATED {
+		return
+	}
+	permission, err := strconv.Atoi(old.Value)
+	if err != nil || permission == 0 {
+		return
+	}
+	group := &model.Group{
+		Name:       groupName,
+		BasePath:   "/",
+		Permission: int32(permission),
+		Remark:     "migrated from " + oldKey,
+	}
+	if err = op.CreateGroup(group); err != nil {
+		utils.Log.Errorf("failed to migrate %s into group: %+v", oldKey, err)
+		return
+	}
+	err = op.SaveSettingItem(&model.SettingItem{
+		Key:   newKey,
+		Value: groupName,
+		Type:  conf.TypeString,
+		Group: settingGroup,
+		Flag:  model.PRIVATE,
+	})
+	if err != nil {
+		utils.Log.Errorf("failed to save setting %s: %+v", newKey, err)
+		return
+	}
+	utils.Log.Infof("%s is migrated into group [%s]", oldKey, groupName)
+}
@@ -0,0 +1,37 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/listcache"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+func InitListCache() {
+	c := conf.Conf.ListCache
+	var cache listcache.ListCache
+	var err error
+	switch c.Type {
+	case "", "memory":
+		return
+	case "bolt":
+		cache, err = listcache.NewBolt(c.BoltFile)
+	case "redis":
+		cache, err = listcache.NewRedis(&redis.Options{
+			Addr:     c.Redis.Address,
+			Username: c.Redis.Username,
+			Password: c.Redis.Password,
+			DB:       c.Redis.DB,
+		}, c.KeyPrefix)
+	default:
+		log.Errorf("unknown list cache type: %s, fallback to memory", c.Type)
+		return
+	}
+	if err != nil {
+		log.Errorf("init list cache error: %+v, fallback to memory", err)
+		return
+	}
+	log.Infof("use %s as list cache", c.Type)
+	op.SetListCache(cache)
+}
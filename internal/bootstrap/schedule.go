@@ -0,0 +1,8 @@
+package bootstrap
+
+import "github.com/alist-org/alist/v3/internal/schedule"
+
+// InitSchedule start the scheduled jobs, only the server runs them
+func InitSchedule() {
+	schedule.Init()
+}
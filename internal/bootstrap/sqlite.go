@@ -0,0 +1,43 @@
+package bootstrap
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// sqliteDriver is the sqlite3 driver with the REGEXP function, which is used by the regex search
+const sqliteDriver = "sqlite3_regexp"
+
+var sqliteRegexps = cache.NewMemCache[*regexp.Regexp]()
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("regexp", sqliteRegexp, true)
+		},
+	})
+}
+
+// sqliteRegexp is called by "value REGEXP pattern", the compiled patterns are cached
+func sqliteRegexp(pattern, value string) (bool, error) {
+	re, ok := sqliteRegexps.Get(pattern)
+	if !ok {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return false, err
+		}
+		sqliteRegexps.Set(pattern, re, cache.WithEx[*regexp.Regexp](10*time.Minute))
+	}
+	return re.MatchString(value), nil
+}
+
+func openSqlite(dsn string) gorm.Dialector {
+	return sqlite.New(sqlite.Config{DriverName: sqliteDriver, DSN: dsn})
+}
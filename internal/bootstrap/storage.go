@@ -11,6 +11,9 @@ import (
 )
 
 func LoadStorages() {
+	if err := op.LoadBalanceGroups(); err != nil {
+		utils.Log.Errorf("failed load balance groups: %+v", err)
+	}
 	storages, err := db.GetEnabledStorages()
 	if err != nil {
 		utils.Log.Fatalf("failed get enabled storages: %+v", err)
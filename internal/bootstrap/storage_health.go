@@ -0,0 +1,15 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitStorageHealthCheck check the storages periodically, the interval of each storage is
+// decided by the setting and the failures, so the due ones are picked every minute
+func InitStorageHealthCheck() {
+	c := cron.NewCron(time.Minute)
+	c.Do(op.CheckStoragesHealth)
+}
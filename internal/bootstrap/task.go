@@ -45,4 +45,8 @@ func InitTaskManager() {
 	op.RegisterSettingChangingCallback(func() {
 		fs.ArchiveContentUploadTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskDecompressUploadThreadsNum, conf.Conf.Tasks.DecompressUpload.Workers)))
 	})
+	fs.SyncTaskManager = tache.NewManager[*fs.SyncTask](tache.WithWorks(setting.GetInt(conf.TaskSyncThreadsNum, conf.Conf.Tasks.Sync.Workers)), tache.WithPersistFunction(db.GetTaskDataFunc("sync", conf.Conf.Tasks.Sync.TaskPersistant), db.UpdateTaskDataFunc("sync", conf.Conf.Tasks.Sync.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Sync.MaxRetry))
+	op.RegisterSettingChangingCallback(func() {
+		fs.SyncTaskManager.SetWorkersNumActive(taskFilterNegative(setting.GetInt(conf.TaskSyncThreadsNum, conf.Conf.Tasks.Sync.Workers)))
+	})
 }
@@ -0,0 +1,8 @@
+package bootstrap
+
+import "github.com/alist-org/alist/v3/internal/tracing"
+
+// InitTracing set up the exporter of the spans if tracing is enabled
+func InitTracing() {
+	tracing.Init()
+}
@@ -0,0 +1,17 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitTrash empty the expired objects in trash periodically
+func InitTrash() {
+	c := cron.NewCron(time.Hour)
+	c.Do(func() {
+		op.CleanExpiredTrash(context.Background())
+	})
+}
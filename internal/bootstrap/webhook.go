@@ -0,0 +1,23 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/event"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/webhook"
+)
+
+// InitWebhooks publish the storage status changes found by the health check on the event bus,
+// and start to post the events to the webhooks
+func InitWebhooks() {
+	op.RegisterStorageHealthHook(func(storage driver.Driver, healthy bool, err error) {
+		s := storage.GetStorage()
+		event.Publish(event.StorageStatusChanged, event.StorageData{
+			MountPath: s.MountPath,
+			Driver:    s.Driver,
+			Status:    s.Status,
+			Healthy:   healthy,
+		})
+	})
+	webhook.Init()
+}
@@ -26,6 +26,21 @@ type Meilisearch struct {
 	IndexPrefix string `json:"index_prefix" env:"INDEX_PREFIX"`
 }
 
+type Redis struct {
+	Address  string `json:"address" env:"ADDR"`
+	Username string `json:"username" env:"USERNAME"`
+	Password string `json:"password" env:"PASSWORD"`
+	DB       int    `json:"db" env:"DB"`
+}
+
+type ListCache struct {
+	// Type is one of memory, bolt and redis
+	Type      string `json:"type" env:"TYPE"`
+	BoltFile  string `json:"bolt_file" env:"BOLT_FILE"`
+	Redis     Redis  `json:"redis" envPrefix:"REDIS_"`
+	KeyPrefix string `json:"key_prefix" env:"KEY_PREFIX"`
+}
+
 type Scheme struct {
 	Address      string `json:"address" env:"ADDR"`
 	HttpPort     int    `json:"http_port" env:"HTTP_PORT"`
@@ -47,6 +62,28 @@ type LogConfig struct {
 	Compress   bool   `json:"compress" env:"COMPRESS"`
 }
 
+type Audit struct {
+	Enable bool `json:"enable" env:"ENABLE"`
+	// File is the JSON-lines file the audit logs are also written to, empty means disabled
+	File string `json:"file" env:"FILE"`
+}
+
+type Metrics struct {
+	Enable bool `json:"enable" env:"ENABLE"`
+	// Token is required as the bearer token to access /metrics if it's not empty
+	Token string `json:"token" env:"TOKEN"`
+}
+
+type Tracing struct {
+	Enable bool `json:"enable" env:"ENABLE"`
+	// Exporter is otlp or stdout
+	Exporter string `json:"exporter" env:"EXPORTER"`
+	// Endpoint is the url of the OTLP/HTTP collector, e.g. http://localhost:4318,
+	// the OTEL_EXPORTER_OTLP_* env are used if it's empty
+	Endpoint    string  `json:"endpoint" env:"ENDPOINT"`
+	SampleRatio float64 `json:"sample_ratio" env:"SAMPLE_RATIO"`
+}
+
 type TaskConfig struct {
 	Workers        int  `json:"workers" env:"WORKERS"`
 	MaxRetry       int  `json:"max_retry" env:"MAX_RETRY"`
@@ -60,6 +97,7 @@ type TasksConfig struct {
 	Copy               TaskConfig `json:"copy" envPrefix:"COPY_"`
 	Decompress         TaskConfig `json:"decompress" envPrefix:"DECOMPRESS_"`
 	DecompressUpload   TaskConfig `json:"decompress_upload" envPrefix:"DECOMPRESS_UPLOAD_"`
+	Sync               TaskConfig `json:"sync" envPrefix:"SYNC_"`
 	AllowRetryCanceled bool       `json:"allow_retry_canceled" env:"ALLOW_RETRY_CANCELED"`
 }
 
@@ -101,11 +139,15 @@ type Config struct {
 	TokenExpiresIn        int         `json:"token_expires_in" env:"TOKEN_EXPIRES_IN"`
 	Database              Database    `json:"database" envPrefix:"DB_"`
 	Meilisearch           Meilisearch `json:"meilisearch" envPrefix:"MEILISEARCH_"`
+	ListCache             ListCache   `json:"list_cache" envPrefix:"LIST_CACHE_"`
 	Scheme                Scheme      `json:"scheme"`
 	TempDir               string      `json:"temp_dir" env:"TEMP_DIR"`
 	BleveDir              string      `json:"bleve_dir" env:"BLEVE_DIR"`
 	DistDir               string      `json:"dist_dir"`
 	Log                   LogConfig   `json:"log"`
+	Audit                 Audit       `json:"audit" envPrefix:"AUDIT_"`
+	Metrics               Metrics     `json:"metrics" envPrefix:"METRICS_"`
+	Tracing               Tracing     `json:"tracing" envPrefix:"TRACING_"`
 	DelayedStart          int         `json:"delayed_start" env:"DELAYED_START"`
 	MaxConnections        int         `json:"max_connections" env:"MAX_CONNECTIONS"`
 	MaxConcurrency        int         `json:"max_concurrency" env:"MAX_CONCURRENCY"`
@@ -123,6 +165,7 @@ func DefaultConfig() *Config {
 	indexDir := filepath.Join(flags.DataDir, "bleve")
 	logPath := filepath.Join(flags.DataDir, "log/log.log")
 	dbPath := filepath.Join(flags.DataDir, "data.db")
+	listCachePath := filepath.Join(flags.DataDir, "list_cache.db")
 	return &Config{
 		Scheme: Scheme{
 			Address:    "0.0.0.0",
@@ -145,6 +188,14 @@ func DefaultConfig() *Config {
 		Meilisearch: Meilisearch{
 			Host: "http://localhost:7700",
 		},
+		ListCache: ListCache{
+			Type:     "memory",
+			BoltFile: listCachePath,
+			Redis: Redis{
+				Address: "localhost:6379",
+			},
+			KeyPrefix: "alist:list:",
+		},
 		BleveDir: indexDir,
 		Log: LogConfig{
 			Enable:     true,
@@ -153,6 +204,13 @@ func DefaultConfig() *Config {
 			MaxBackups: 30,
 			MaxAge:     28,
 		},
+		Audit: Audit{
+			Enable: true,
+		},
+		Tracing: Tracing{
+			Exporter:    "otlp",
+			SampleRatio: 1,
+		},
 		MaxConnections:        0,
 		MaxConcurrency:        64,
 		TlsInsecureSkipVerify: true,
@@ -184,6 +242,11 @@ func DefaultConfig() *Config {
 				Workers:  5,
 				MaxRetry: 2,
 			},
+			Sync: TaskConfig{
+				Workers:  2,
+				MaxRetry: 1,
+				// TaskPersistant: true,
+			},
 			AllowRetryCanceled: false,
 		},
 		Cors: Cors{
@@ -45,12 +45,17 @@ const (
 	ForwardDirectLinkParams = "forward_direct_link_params"
 	IgnoreDirectLinkParams  = "ignore_direct_link_params"
 	WebauthnLoginEnabled    = "webauthn_login_enabled"
+	TrashPath               = "trash_path"
+	TrashRetentionDays      = "trash_retention_days"
+	AuditRetentionDays      = "audit_retention_days"
+	StorageHealthInterval   = "storage_health_interval"
 
 	// index
-	SearchIndex     = "search_index"
-	AutoUpdateIndex = "auto_update_index"
-	IgnorePaths     = "ignore_paths"
-	MaxIndexDepth   = "max_index_depth"
+	SearchIndex         = "search_index"
+	AutoUpdateIndex     = "auto_update_index"
+	IgnorePaths         = "ignore_paths"
+	MaxIndexDepth       = "max_index_depth"
+	IndexContentMaxSize = "index_content_max_size"
 
 	// aria2
 	Aria2Uri    = "aria2_uri"
@@ -92,19 +97,19 @@ const (
 	SSOExtraScopes       = "sso_extra_scopes"
 	SSOAutoRegister      = "sso_auto_register"
 	SSODefaultDir        = "sso_default_dir"
-	SSODefaultPermission = "sso_default_permission"
+	SSODefaultGroups     = "sso_default_groups"
 	SSOCompatibilityMode = "sso_compatibility_mode"
 
 	// ldap
-	LdapLoginEnabled      = "ldap_login_enabled"
-	LdapServer            = "ldap_server"
-	LdapManagerDN         = "ldap_manager_dn"
-	LdapManagerPassword   = "ldap_manager_password"
-	LdapUserSearchBase    = "ldap_user_search_base"
-	LdapUserSearchFilter  = "ldap_user_search_filter"
-	LdapDefaultPermission = "ldap_default_permission"
-	LdapDefaultDir        = "ldap_default_dir"
-	LdapLoginTips         = "ldap_login_tips"
+	LdapLoginEnabled     = "ldap_login_enabled"
+	LdapServer           = "ldap_server"
+	LdapManagerDN        = "ldap_manager_dn"
+	LdapManagerPassword  = "ldap_manager_password"
+	LdapUserSearchBase   = "ldap_user_search_base"
+	LdapUserSearchFilter = "ldap_user_search_filter"
+	LdapDefaultGroups    = "ldap_default_groups"
+	LdapDefaultDir       = "ldap_default_dir"
+	LdapLoginTips        = "ldap_login_tips"
 
 	// s3
 	S3Buckets         = "s3_buckets"
@@ -131,6 +136,7 @@ const (
 	TaskCopyThreadsNum                    = "copy_task_threads_num"
 	TaskDecompressDownloadThreadsNum      = "decompress_download_task_threads_num"
 	TaskDecompressUploadThreadsNum        = "decompress_upload_task_threads_num"
+	TaskSyncThreadsNum                    = "sync_task_threads_num"
 	StreamMaxClientDownloadSpeed          = "max_client_download_speed"
 	StreamMaxClientUploadSpeed            = "max_client_upload_speed"
 	StreamMaxServerDownloadSpeed          = "max_server_download_speed"
@@ -149,5 +155,9 @@ const (
 
 // ContextKey is the type of context keys.
 const (
-	NoTaskKey = "no_task"
+	NoTaskKey           = "no_task"
+	UploadCheckpointKey = "upload_checkpoint"
+	ClientIPKey         = "client_ip"
+	ProtocolKey         = "protocol"
+	TransferKey         = "transfer"
 )
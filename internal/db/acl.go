@@ -0,0 +1,54 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetACLById(id uint) (*model.ACL, error) {
+	var a model.ACL
+	if err := db.First(&a, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get acl")
+	}
+	return &a, nil
+}
+
+func GetACLs(pageIndex, pageSize int) (acls []model.ACL, count int64, err error) {
+	aclDB := db.Model(&model.ACL{})
+	if err = aclDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get acls count")
+	}
+	if err = aclDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&acls).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find acls")
+	}
+	return acls, count, nil
+}
+
+// GetACLsOfUser returns the ACLs of the user and the groups
+func GetACLsOfUser(userId uint, groupIds []uint) ([]model.ACL, error) {
+	var acls []model.ACL
+	query := db.Where(columnName("user_id")+" = ?", userId)
+	if len(groupIds) > 0 {
+		query = query.Or(columnName("group_id")+" IN ?", groupIds)
+	}
+	if err := query.Find(&acls).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find acls of user %d", userId)
+	}
+	return acls, nil
+}
+
+func CreateACL(a *model.ACL) error {
+	return errors.WithStack(db.Create(a).Error)
+}
+
+func UpdateACL(a *model.ACL) error {
+	return errors.WithStack(db.Save(a).Error)
+}
+
+func DeleteACLById(id uint) error {
+	return errors.WithStack(db.Delete(&model.ACL{}, id).Error)
+}
+
+func DeleteACLsByUserId(userId uint) error {
+	return errors.WithStack(db.Where(columnName("user_id")+" = ?", userId).Delete(&model.ACL{}).Error)
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateAuditLogs(logs []*model.AuditLog) error {
+	return errors.WithStack(db.Create(logs).Error)
+}
+
+func GetAuditLogs(filter model.AuditLogFilter, pageIndex, pageSize int) (logs []model.AuditLog, count int64, err error) {
+	auditDB := db.Model(&model.AuditLog{})
+	if filter.Username != "" {
+		auditDB = auditDB.Where(columnName("username")+" = ?", filter.Username)
+	}
+	if filter.Protocol != "" {
+		auditDB = auditDB.Where(columnName("protocol")+" = ?", filter.Protocol)
+	}
+	if filter.Operation != "" {
+		auditDB = auditDB.Where(columnName("operation")+" = ?", filter.Operation)
+	}
+	if filter.Path != "" && filter.Path != "/" {
+		path := strings.TrimSuffix(filter.Path, "/")
+		auditDB = auditDB.Where(
+			db.Where(columnName("src_path")+" = ? OR "+columnName("src_path")+" LIKE ?", path, path+"/%").
+				Or(columnName("dst_path")+" = ? OR "+columnName("dst_path")+" LIKE ?", path, path+"/%"))
+	}
+	if filter.Start != nil {
+		auditDB = auditDB.Where(columnName("time")+" >= ?", *filter.Start)
+	}
+	if filter.End != nil {
+		auditDB = auditDB.Where(columnName("time")+" < ?", *filter.End)
+	}
+	if filter.Success != "" {
+		auditDB = auditDB.Where(columnName("success")+" = ?", filter.Success == "true")
+	}
+	if err = auditDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get audit logs count")
+	}
+	if err = auditDB.Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find audit logs")
+	}
+	return logs, count, nil
+}
+
+func DeleteAuditLogsBefore(t time.Time) (int64, error) {
+	res := db.Where(columnName("time")+" < ?", t).Delete(&model.AuditLog{})
+	return res.RowsAffected, errors.WithStack(res.Error)
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetBalanceGroupById(id uint) (*model.BalanceGroup, error) {
+	var g model.BalanceGroup
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get balance group")
+	}
+	return &g, nil
+}
+
+func GetBalanceGroups() ([]model.BalanceGroup, error) {
+	var groups []model.BalanceGroup
+	if err := db.Order(columnName("mount_path")).Find(&groups).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find balance groups")
+	}
+	return groups, nil
+}
+
+func CreateBalanceGroup(g *model.BalanceGroup) error {
+	return errors.WithStack(db.Create(g).Error)
+}
+
+func UpdateBalanceGroup(g *model.BalanceGroup) error {
+	return errors.WithStack(db.Save(g).Error)
+}
+
+func DeleteBalanceGroupById(id uint) error {
+	return errors.WithStack(db.Delete(&model.BalanceGroup{}, id).Error)
+}
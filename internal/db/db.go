@@ -12,7 +12,10 @@ var db *gorm.DB
 
 func Init(d *gorm.DB) {
 	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey))
+	if err := migrateSearchNodes(); err != nil {
+		log.Fatalf("failed migrate database: %+v", err)
+	}
+	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey), new(model.ScheduledJob), new(model.ScheduledJobRun), new(model.TrashItem), new(model.Share), new(model.FileRequest), new(model.UploadedFile), new(model.UserUsage), new(model.Group), new(model.UserGroup), new(model.GroupMeta), new(model.ACL), new(model.AuditLog), new(model.StorageHealthLog), new(model.BalanceGroup), new(model.Webhook), new(model.WebhookDelivery), new(model.IndexJob))
 	if err != nil {
 		log.Fatalf("failed migrate database: %s", err.Error())
 	}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetFileRequestByToken(token string) (*model.FileRequest, error) {
+	var r model.FileRequest
+	if err := db.Where(columnName("token")+" = ?", token).First(&r).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get file request")
+	}
+	return &r, nil
+}
+
+// GetFileRequests returns the file requests created by the user, or all of them if creatorId is 0
+func GetFileRequests(creatorId uint, pageIndex, pageSize int) (requests []model.FileRequest, count int64, err error) {
+	requestDB := db.Model(&model.FileRequest{})
+	if creatorId != 0 {
+		requestDB = requestDB.Where(columnName("creator_id")+" = ?", creatorId)
+	}
+	if err = requestDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get file requests count")
+	}
+	if err = requestDB.Order(columnName("created") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&requests).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find file requests")
+	}
+	return requests, count, nil
+}
+
+func CreateFileRequest(r *model.FileRequest) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateFileRequest(r *model.FileRequest) error {
+	return errors.WithStack(db.Save(r).Error)
+}
+
+func DeleteFileRequestByToken(token string) error {
+	return errors.WithStack(db.Where(columnName("token")+" = ?", token).Delete(&model.FileRequest{}).Error)
+}
+
+func IncreaseFileRequestUploadCount(token string) error {
+	return errors.WithStack(db.Model(&model.FileRequest{}).Where(columnName("token")+" = ?", token).
+		UpdateColumn("upload_count", gorm.Expr(columnName("upload_count")+" + 1")).Error)
+}
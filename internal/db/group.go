@@ -0,0 +1,133 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetGroupById(id uint) (*model.Group, error) {
+	var g model.Group
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get group")
+	}
+	return &g, nil
+}
+
+func GetGroupsByNames(names []string) ([]model.Group, error) {
+	var groups []model.Group
+	if err := db.Where(columnName("name")+" IN ?", names).Find(&groups).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find groups")
+	}
+	return groups, nil
+}
+
+func GetGroups(pageIndex, pageSize int) (groups []model.Group, count int64, err error) {
+	groupDB := db.Model(&model.Group{})
+	if err = groupDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get groups count")
+	}
+	if err = groupDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&groups).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find groups")
+	}
+	return groups, count, nil
+}
+
+func CreateGroup(g *model.Group) error {
+	return errors.WithStack(db.Create(g).Error)
+}
+
+func UpdateGroup(g *model.Group) error {
+	return errors.WithStack(db.Save(g).Error)
+}
+
+// DeleteGroupById delete the group with its members, metas and ACLs
+func DeleteGroupById(id uint) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(columnName("group_id")+" = ?", id).Delete(&model.UserGroup{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(columnName("group_id")+" = ?", id).Delete(&model.GroupMeta{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(columnName("group_id")+" = ?", id).Delete(&model.ACL{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Group{}, id).Error
+	}))
+}
+
+// GetGroupsByUserId returns the groups the user belongs to
+func GetGroupsByUserId(userId uint) ([]model.Group, error) {
+	var groups []model.Group
+	sub := db.Model(&model.UserGroup{}).Select(columnName("group_id")).Where(columnName("user_id")+" = ?", userId)
+	if err := db.Where(columnName("id")+" IN (?)", sub).Order(columnName("id")).Find(&groups).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find groups of user %d", userId)
+	}
+	return groups, nil
+}
+
+// GetGroupIdsByUserIds returns the ids of the groups of each user
+func GetGroupIdsByUserIds(userIds []uint) (map[uint][]uint, error) {
+	var relations []model.UserGroup
+	if err := db.Where(columnName("user_id")+" IN ?", userIds).Order(columnName("group_id")).Find(&relations).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find groups of users")
+	}
+	res := make(map[uint][]uint)
+	for _, r := range relations {
+		res[r.UserID] = append(res[r.UserID], r.GroupID)
+	}
+	return res, nil
+}
+
+// SetUserGroups replace the groups of the user
+func SetUserGroups(userId uint, groupIds []uint) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(columnName("user_id")+" = ?", userId).Delete(&model.UserGroup{}).Error; err != nil {
+			return err
+		}
+		if len(groupIds) == 0 {
+			return nil
+		}
+		relations := make([]model.UserGroup, 0, len(groupIds))
+		for _, id := range groupIds {
+			relations = append(relations, model.UserGroup{UserID: userId, GroupID: id})
+		}
+		return tx.Create(&relations).Error
+	}))
+}
+
+func DeleteUserGroupsByUserId(userId uint) error {
+	return errors.WithStack(db.Where(columnName("user_id")+" = ?", userId).Delete(&model.UserGroup{}).Error)
+}
+
+func GetGroupMetaById(id uint) (*model.GroupMeta, error) {
+	var m model.GroupMeta
+	if err := db.First(&m, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get group meta")
+	}
+	return &m, nil
+}
+
+func GetGroupMetasByGroupIds(groupIds []uint) ([]model.GroupMeta, error) {
+	var metas []model.GroupMeta
+	if len(groupIds) == 0 {
+		return metas, nil
+	}
+	if err := db.Where(columnName("group_id")+" IN ?", groupIds).Order(columnName("id")).Find(&metas).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find group metas")
+	}
+	return metas, nil
+}
+
+func CreateGroupMeta(m *model.GroupMeta) error {
+	return errors.WithStack(db.Create(m).Error)
+}
+
+func UpdateGroupMeta(m *model.GroupMeta) error {
+	return errors.WithStack(db.Save(m).Error)
+}
+
+func DeleteGroupMetaById(id uint) error {
+	return errors.WithStack(db.Delete(&model.GroupMeta{}, id).Error)
+}
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetIndexJobs() ([]model.IndexJob, error) {
+	var jobs []model.IndexJob
+	if err := db.Order(columnName("mount_path")).Find(&jobs).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return jobs, nil
+}
+
+func GetIndexJob(storageId uint) (*model.IndexJob, error) {
+	var job model.IndexJob
+	if err := db.First(&job, storageId).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get index job")
+	}
+	return &job, nil
+}
+
+func SaveIndexJob(job *model.IndexJob) error {
+	return errors.WithStack(db.Save(job).Error)
+}
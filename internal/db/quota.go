@@ -0,0 +1,223 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ensureUsages create the usages of the users not counted yet by the files they uploaded,
+// e.g. the files recorded before the usages are kept
+func ensureUsages(tx *gorm.DB, userIds ...uint) error {
+	var counted []uint
+	if err := tx.Model(&model.UserUsage{}).Where(columnName("user_id")+" IN ?", userIds).
+		Pluck("user_id", &counted).Error; err != nil {
+		return err
+	}
+	for _, id := range userIds {
+		if slices.Contains(counted, id) {
+			continue
+		}
+		usage := model.UserUsage{UserID: id}
+		err := tx.Model(&model.UploadedFile{}).
+			Select("COALESCE(SUM("+columnName("size")+"), 0) AS used_bytes, COUNT(*) AS used_files").
+			Where(columnName("user_id")+" = ?", id).Scan(&usage).Error
+		if err != nil {
+			return err
+		}
+		usage.UserID = id
+		if err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&usage).Error; err != nil {
+			return err
+		}
+		counted = append(counted, id)
+	}
+	return nil
+}
+
+// addUsage add the bytes and files to the usage of the user, they are negative when released
+func addUsage(tx *gorm.DB, userId uint, bytes, files int64) error {
+	if err := ensureUsages(tx, userId); err != nil {
+		return err
+	}
+	return tx.Model(&model.UserUsage{}).Where(columnName("user_id")+" = ?", userId).Updates(map[string]interface{}{
+		"used_bytes": gorm.Expr(columnName("used_bytes")+" + ?", bytes),
+		"used_files": gorm.Expr(columnName("used_files")+" + ?", files),
+	}).Error
+}
+
+func getUserUsage(tx *gorm.DB, userId uint) (*model.UserUsage, error) {
+	if err := ensureUsages(tx, userId); err != nil {
+		return nil, err
+	}
+	var usage model.UserUsage
+	err := tx.Where(columnName("user_id")+" = ?", userId).First(&usage).Error
+	return &usage, err
+}
+
+func getGroupUsage(tx *gorm.DB, groupId uint) (*model.UserUsage, error) {
+	var members []uint
+	if err := tx.Model(&model.UserGroup{}).Where(columnName("group_id")+" = ?", groupId).
+		Pluck("user_id", &members).Error; err != nil {
+		return nil, err
+	}
+	var usage model.UserUsage
+	if len(members) == 0 {
+		return &usage, nil
+	}
+	if err := ensureUsages(tx, members...); err != nil {
+		return nil, err
+	}
+	err := tx.Model(&model.UserUsage{}).
+		Select("COALESCE(SUM("+columnName("used_bytes")+"), 0) AS used_bytes, COALESCE(SUM("+columnName("used_files")+"), 0) AS used_files").
+		Where(columnName("user_id")+" IN ?", members).Scan(&usage).Error
+	return &usage, err
+}
+
+// GetUserUsage returns the usage of the files uploaded by the user
+func GetUserUsage(userId uint) (*model.UserUsage, error) {
+	var usage *model.UserUsage
+	err := db.Transaction(func(tx *gorm.DB) (err error) {
+		usage, err = getUserUsage(tx, userId)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get usage of user %d", userId)
+	}
+	return usage, nil
+}
+
+// GetGroupUsage returns the total usage of the members of the group
+func GetGroupUsage(groupId uint) (*model.UserUsage, error) {
+	var usage *model.UserUsage
+	err := db.Transaction(func(tx *gorm.DB) (err error) {
+		usage, err = getGroupUsage(tx, groupId)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed get usage of group %d", groupId)
+	}
+	return usage, nil
+}
+
+// ReserveUsage add the bytes and a file to the usage of the user before uploading. check is called with the
+// usages after adding in the same transaction and the reservation is rolled back if it fails, the groups are
+// locked meanwhile so that the concurrent uploads of the members can't exceed the quotas of the groups together
+func ReserveUsage(userId uint, groupIds []uint, size int64, check func(userUsage *model.UserUsage, groupUsages map[uint]*model.UserUsage) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(groupIds) > 0 {
+			var groups []model.Group
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(columnName("id")+" IN ?", groupIds).Find(&groups).Error
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if err := addUsage(tx, userId, size, 1); err != nil {
+			return errors.WithStack(err)
+		}
+		userUsage, err := getUserUsage(tx, userId)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		groupUsages := make(map[uint]*model.UserUsage, len(groupIds))
+		for _, id := range groupIds {
+			if groupUsages[id], err = getGroupUsage(tx, id); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return check(userUsage, groupUsages)
+	})
+}
+
+// ReleaseUsage release the usage reserved by ReserveUsage, e.g. the upload is failed
+func ReleaseUsage(userId uint, size int64) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		return addUsage(tx, userId, -size, -1)
+	}))
+}
+
+// releaseUploadedFiles delete the records of the files and release them from the usages of the uploaders
+func releaseUploadedFiles(tx *gorm.DB, query *gorm.DB) error {
+	var usages []model.UserUsage
+	err := tx.Model(&model.UploadedFile{}).Where(query).
+		Select(columnName("user_id") + " AS user_id, COALESCE(SUM(" + columnName("size") + "), 0) AS used_bytes, COUNT(*) AS used_files").
+		Group(columnName("user_id")).Scan(&usages).Error
+	if err != nil {
+		return err
+	}
+	for _, u := range usages {
+		if err = addUsage(tx, u.UserID, -u.UsedBytes, -u.UsedFiles); err != nil {
+			return err
+		}
+	}
+	return tx.Where(query).Delete(&model.UploadedFile{}).Error
+}
+
+func whereUploadedFile(storageId uint, path string) *gorm.DB {
+	return db.Where(columnName("storage_id")+" = ? AND "+columnName("path")+" = ?", storageId, path)
+}
+
+// SetUploadedFile record the file whose usage is reserved by ReserveUsage,
+// the one at the same path is replaced since it's overwritten
+func SetUploadedFile(f *model.UploadedFile) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := releaseUploadedFiles(tx, whereUploadedFile(f.StorageID, f.Path)); err != nil {
+			return err
+		}
+		return tx.Create(f).Error
+	}))
+}
+
+// whereUploadedFiles match the file at the path and the files in it if it's a folder
+func whereUploadedFiles(storageId uint, path string) *gorm.DB {
+	if path == "/" {
+		return db.Where(columnName("storage_id")+" = ?", storageId)
+	}
+	return db.Where(fmt.Sprintf("%s = ? AND (%s = ? OR %s LIKE ? ESCAPE '!')", columnName("storage_id"), columnName("path"), columnName("path")),
+		storageId, path, escapeLike(path)+"/%")
+}
+
+// MoveUploadedFiles change the paths of the files at the src path and in it to the dst path
+func MoveUploadedFiles(storageId uint, srcPath, dstPath string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		var files []model.UploadedFile
+		if err := tx.Where(whereUploadedFiles(storageId, srcPath)).Find(&files).Error; err != nil {
+			return err
+		}
+		for _, f := range files {
+			path := dstPath + strings.TrimPrefix(f.Path, srcPath)
+			if err := releaseUploadedFiles(tx, whereUploadedFile(storageId, path)); err != nil {
+				return err
+			}
+			if err := tx.Model(&f).Update("path", path).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// DeleteUploadedFiles delete the records of the file at the path and the files in it
+func DeleteUploadedFiles(storageId uint, path string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		return releaseUploadedFiles(tx, whereUploadedFiles(storageId, path))
+	}))
+}
+
+func DeleteUploadedFilesByStorageId(storageId uint) error {
+	return DeleteUploadedFiles(storageId, "/")
+}
+
+// DeleteUserUsage forget the files uploaded by the user, so that they are not counted any more
+func DeleteUserUsage(userId uint) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(columnName("user_id")+" = ?", userId).Delete(&model.UploadedFile{}).Error; err != nil {
+			return err
+		}
+		return tx.Where(columnName("user_id")+" = ?", userId).Delete(&model.UserUsage{}).Error
+	}))
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetScheduledJobById(id uint) (*model.ScheduledJob, error) {
+	var j model.ScheduledJob
+	if err := db.First(&j, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get scheduled job")
+	}
+	return &j, nil
+}
+
+func GetScheduledJobs(pageIndex, pageSize int) (jobs []model.ScheduledJob, count int64, err error) {
+	jobDB := db.Model(&model.ScheduledJob{})
+	if err = jobDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get scheduled jobs count")
+	}
+	if err = jobDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&jobs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find scheduled jobs")
+	}
+	return jobs, count, nil
+}
+
+func CreateScheduledJob(j *model.ScheduledJob) error {
+	return errors.WithStack(db.Create(j).Error)
+}
+
+func UpdateScheduledJob(j *model.ScheduledJob) error {
+	return errors.WithStack(db.Save(j).Error)
+}
+
+// UpdateScheduledJobStatus only update the last run columns, so that the job edited while running is not overwritten
+func UpdateScheduledJobStatus(j *model.ScheduledJob) error {
+	return errors.WithStack(db.Model(j).Select("last_run_time", "last_status", "last_message").Updates(j).Error)
+}
+
+func DeleteScheduledJobById(id uint) error {
+	if err := db.Where(columnName("job_id")+" = ?", id).Delete(&model.ScheduledJobRun{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(db.Delete(&model.ScheduledJob{}, id).Error)
+}
+
+func CreateScheduledJobRun(r *model.ScheduledJobRun) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateScheduledJobRun(r *model.ScheduledJobRun) error {
+	return errors.WithStack(db.Save(r).Error)
+}
+
+func GetScheduledJobRuns(jobId uint, pageIndex, pageSize int) (runs []model.ScheduledJobRun, count int64, err error) {
+	runDB := db.Model(&model.ScheduledJobRun{}).Where(columnName("job_id")+" = ?", jobId)
+	if err = runDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get scheduled job runs count")
+	}
+	if err = runDB.Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&runs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find scheduled job runs")
+	}
+	return runs, count, nil
+}
+
+// PruneScheduledJobRuns keep only the latest keep runs of the job
+func PruneScheduledJobRuns(jobId uint, keep int) error {
+	var ids []uint
+	err := db.Model(&model.ScheduledJobRun{}).Where(columnName("job_id")+" = ?", jobId).
+		Order(columnName("id")+" desc").Offset(keep).Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(db.Where(columnName("job_id")+" = ? AND "+columnName("id")+" <= ?", jobId, ids[0]).
+		Delete(&model.ScheduledJobRun{}).Error)
+}
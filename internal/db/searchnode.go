@@ -16,9 +16,86 @@ func whereInParent(parent string) *gorm.DB {
 	if parent == "/" {
 		return db.Where("1 = 1")
 	}
-	return db.Where(fmt.Sprintf("%s LIKE ?", columnName("parent")),
-		fmt.Sprintf("%s/%%", parent)).
-		Or(fmt.Sprintf("%s = ?", columnName("parent")), parent)
+	return db.Where(fmt.Sprintf("%s = ?", columnName("parent")), parent).Or(whereParentUnder(parent))
+}
+
+// whereParentUnder filter the nodes in the sub folders of dir
+func whereParentUnder(dir string) *gorm.DB {
+	if dir == "/" {
+		return db.Where(fmt.Sprintf("%s <> ?", columnName("parent")), "/")
+	}
+	return db.Where(fmt.Sprintf("%s LIKE ? ESCAPE '!'", columnName("parent")), escapeLike(dir)+"/%")
+}
+
+// whereNode filter the node of the path
+func whereNode(path string) *gorm.DB {
+	return db.Where(fmt.Sprintf("%s = ? AND %s = ?", columnName("parent"), columnName("name")),
+		stdpath.Dir(path), stdpath.Base(path))
+}
+
+// whereUnder filter the node of the path and the ones under it
+func whereUnder(path string) *gorm.DB {
+	if path == "/" {
+		return db.Where("1 = 1")
+	}
+	return db.Where(whereNode(path)).Or(whereInParent(path))
+}
+
+// whereVisible filter the nodes which can be accessed by the visibility of the user in req
+func whereVisible(searchDB *gorm.DB, req model.SearchReq) *gorm.DB {
+	if len(req.Paths) > 0 {
+		paths := db.Where("1 = 0")
+		for _, path := range req.Paths {
+			paths = paths.Or(whereUnder(path))
+			// the parents of the paths can be accessed to walk to them
+			for dir := stdpath.Dir(path); path != "/" && dir != "/"; dir = stdpath.Dir(dir) {
+				paths = paths.Or(whereNode(dir))
+			}
+		}
+		searchDB = searchDB.Where(paths)
+	}
+	for _, rule := range req.Rules {
+		region := db.Where(fmt.Sprintf("%s = ?", columnName("parent")), rule.Parent)
+		if rule.Sub {
+			sub := db.Where(whereParentUnder(rule.Parent))
+			for _, except := range rule.Except {
+				sub = sub.Not(db.Where(fmt.Sprintf("%s = ?", columnName("parent")), except).Or(whereParentUnder(except)))
+			}
+			region = region.Or(sub)
+		}
+		hidden := db.Where(region)
+		if len(rule.Names) > 0 {
+			names := db.Where("1 = 0")
+			for _, name := range rule.Names {
+				names = names.Or(whereRegexp(columnName("name")), name)
+			}
+			hidden = hidden.Where(names)
+		}
+		searchDB = searchDB.Not(hidden)
+	}
+	for _, hidePath := range req.HidePaths {
+		searchDB = searchDB.Not(whereRegexp(pathExpr()), hidePath)
+	}
+	return searchDB
+}
+
+// whereRegexp returns the condition of matching expr by a regex, the REGEXP function of sqlite
+// is registered with the driver
+func whereRegexp(expr string) string {
+	if conf.Conf.Database.Type == "postgres" {
+		return expr + " ~ ?"
+	}
+	return expr + " REGEXP ?"
+}
+
+// pathExpr returns the expression of the path of the node
+func pathExpr() string {
+	parent, name := columnName("parent"), columnName("name")
+	dir := fmt.Sprintf("CASE WHEN %s = '/' THEN '' ELSE %s END", parent, parent)
+	if conf.Conf.Database.Type == "mysql" {
+		return fmt.Sprintf("CONCAT(%s, '/', %s)", dir, name)
+	}
+	return fmt.Sprintf("%s || '/' || %s", dir, name)
 }
 
 func CreateSearchNode(node *model.SearchNode) error {
@@ -31,14 +108,7 @@ func BatchCreateSearchNodes(nodes *[]model.SearchNode) error {
 
 func DeleteSearchNodesByParent(path string) error {
 	path = utils.FixAndCleanPath(path)
-	err := db.Where(whereInParent(path)).Delete(&model.SearchNode{}).Error
-	if err != nil {
-		return err
-	}
-	dir, name := stdpath.Split(path)
-	return db.Where(fmt.Sprintf("%s = ? AND %s = ?",
-		columnName("parent"), columnName("name")),
-		dir, name).Delete(&model.SearchNode{}).Error
+	return db.Where(whereUnder(path)).Delete(&model.SearchNode{}).Error
 }
 
 func ClearSearchNodes() error {
@@ -55,37 +125,86 @@ func GetSearchNodesByParent(parent string) ([]model.SearchNode, error) {
 }
 
 func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int64, error) {
-	var searchDB *gorm.DB
-	if !useFullText || conf.Conf.Database.Type == "sqlite3" {
-		keywordsClause := db.Where("1 = 1")
+	searchDB := db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent))
+	switch {
+	case req.Keywords == "":
+	case req.Mode == model.SearchPhrase:
+		phrase := "%" + escapeLike(req.Keywords) + "%"
+		searchDB = searchDB.Where("(name LIKE ? ESCAPE '!' OR content LIKE ? ESCAPE '!')", phrase, phrase)
+	case req.Mode == model.SearchWildcard:
+		pattern := strings.NewReplacer("*", "%", "?", "_").Replace(escapeLike(req.Keywords))
+		searchDB = searchDB.Where("name LIKE ? ESCAPE '!'", pattern)
+	case req.Mode == model.SearchRegex:
+		searchDB = searchDB.Where(whereRegexp("name"), req.Keywords)
+	case !useFullText || conf.Conf.Database.Type == "sqlite3":
 		for _, keyword := range strings.Fields(req.Keywords) {
-			keywordsClause = keywordsClause.Where("name LIKE ?", fmt.Sprintf("%%%s%%", keyword))
-		}
-		searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).Where(keywordsClause)
-	} else {
-		switch conf.Conf.Database.Type {
-		case "mysql":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", "'*"+req.Keywords+"*'")
-		case "postgres":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("to_tsvector(name) @@ to_tsquery(?)", strings.Join(strings.Fields(req.Keywords), " & "))
+			keyword = fmt.Sprintf("%%%s%%", keyword)
+			searchDB = searchDB.Where("(name LIKE ? OR content LIKE ?)", keyword, keyword)
 		}
+	case conf.Conf.Database.Type == "mysql":
+		keywords := "'*" + req.Keywords + "*'"
+		searchDB = searchDB.Where("(MATCH (name) AGAINST (? IN BOOLEAN MODE) OR MATCH (content) AGAINST (? IN BOOLEAN MODE))", keywords, keywords)
+	case conf.Conf.Database.Type == "postgres":
+		query := strings.Join(strings.Fields(req.Keywords), " & ")
+		searchDB = searchDB.Where("(to_tsvector(name) @@ to_tsquery(?) OR to_tsvector(content) @@ to_tsquery(?))", query, query)
 	}
 
+	return findSearchNodes(whereFilters(searchDB, req), req, searchOrder(req))
+}
+
+// whereFilters filter the nodes by the visibility and the filters in req
+func whereFilters(searchDB *gorm.DB, req model.SearchReq) *gorm.DB {
+	searchDB = whereVisible(searchDB, req)
 	if req.Scope != 0 {
 		isDir := req.Scope == 1
-		searchDB.Where(db.Where("is_dir = ?", isDir))
+		searchDB = searchDB.Where("is_dir = ?", isDir)
+	}
+	if req.MinSize > 0 {
+		searchDB = searchDB.Where("size >= ?", req.MinSize)
+	}
+	if req.MaxSize > 0 {
+		searchDB = searchDB.Where("size <= ?", req.MaxSize)
+	}
+	if req.ModifiedAfter != nil {
+		searchDB = searchDB.Where(columnName("modified")+" >= ?", *req.ModifiedAfter)
 	}
+	if req.ModifiedBefore != nil {
+		searchDB = searchDB.Where(columnName("modified")+" <= ?", *req.ModifiedBefore)
+	}
+	if len(req.Exts) > 0 {
+		searchDB = searchDB.Where("ext IN ?", req.Exts)
+	}
+	if len(req.Types) > 0 {
+		searchDB = searchDB.Where("obj_type IN ?", req.Types)
+	}
+	return searchDB
+}
 
+func findSearchNodes(searchDB *gorm.DB, req model.SearchReq, order any) ([]model.SearchNode, int64, error) {
 	var count int64
 	if err := searchDB.Count(&count).Error; err != nil {
 		return nil, 0, errors.Wrapf(err, "failed get search items count")
 	}
 	var files []model.SearchNode
-	if err := searchDB.Order("name asc").Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
+	if err := searchDB.Order(order).Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
 		Find(&files).Error; err != nil {
 		return nil, 0, err
 	}
 	return files, count, nil
 }
+
+// escapeLike escape the wildcards of LIKE with !, which is the same in all the databases
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+func searchOrder(req model.SearchReq) string {
+	orderBy, direction := req.OrderBy, req.OrderDirection
+	if orderBy == "" {
+		orderBy = "name"
+	}
+	if direction == "" {
+		direction = "asc"
+	}
+	return columnName(orderBy) + " " + direction
+}
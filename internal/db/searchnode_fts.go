@@ -0,0 +1,220 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// the full text index of the nodes is a FTS5 table in sqlite, which refers to the nodes by id
+// (the implicit rowids may be changed by VACUUM), and a tsvector column in postgres
+
+// ftsContentLimit is the characters of the content in the tsvector, which can't be larger than 1MB
+const ftsContentLimit = 100000
+
+func searchNodesTable() string {
+	return conf.Conf.Database.TablePrefix + "search_nodes"
+}
+
+func searchNodesFTSTable() string {
+	return searchNodesTable() + "_fts"
+}
+
+// InitSearchNodesFTS create the full text index of the nodes if not exists,
+// the nodes indexed before are added to it
+func InitSearchNodesFTS() error {
+	switch conf.Conf.Database.Type {
+	case "sqlite3":
+		var count int64
+		err := db.Raw("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?", searchNodesFTSTable()).
+			Scan(&count).Error
+		if err != nil || count > 0 {
+			return errors.WithStack(err)
+		}
+		return db.Transaction(func(tx *gorm.DB) error {
+			// the trigram tokenizer matches any part of the names as LIKE, including the CJK ones
+			stmts := []string{fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(name, content, content='%s', content_rowid='id', tokenize='trigram')",
+				searchNodesFTSTable(), searchNodesTable())}
+			stmts = append(stmts, sqliteFTSTriggers()...)
+			stmts = append(stmts, fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", searchNodesFTSTable(), searchNodesFTSTable()))
+			for _, stmt := range stmts {
+				if err := tx.Exec(stmt).Error; err != nil {
+					if strings.Contains(err.Error(), "no such module: fts5") {
+						return errors.New("fts5 is not enabled, alist should be built with the sqlite_fts5 tag")
+					}
+					return errors.Wrapf(err, "failed create full text index")
+				}
+			}
+			return nil
+		})
+	case "postgres":
+		table := searchNodesTable()
+		stmts := []string{
+			"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+			fmt.Sprintf(`ALTER TABLE %s ADD COLUMN IF NOT EXISTS fts tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+	setweight(to_tsvector('simple', left(coalesce(content, ''), %d)), 'B')) STORED`, table, ftsContentLimit),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_fts ON %s USING GIN (fts)", table, table),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_name_trgm ON %s USING GIN (name gin_trgm_ops)", table, table),
+		}
+		for _, stmt := range stmts {
+			if err := db.Exec(stmt).Error; err != nil {
+				return errors.Wrapf(err, "failed create full text index")
+			}
+		}
+		return nil
+	}
+	return errors.Errorf("full text index is not supported by %s", conf.Conf.Database.Type)
+}
+
+// migrateSearchNodes add the id to the nodes indexed by the old versions, the table is recreated
+// as the primary key can't be added to it by AutoMigrate, and the FTS5 table referring to the
+// rowids is dropped to be rebuilt by InitSearchNodesFTS
+func migrateSearchNodes() error {
+	m := db.Migrator()
+	if !m.HasTable(&model.SearchNode{}) || m.HasColumn(&model.SearchNode{}, "ID") {
+		return nil
+	}
+	table, old := searchNodesTable(), searchNodesTable()+"_old"
+	columns, err := m.ColumnTypes(&model.SearchNode{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if conf.Conf.Database.Type == "sqlite3" {
+			if err := tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", searchNodesFTSTable())).Error; err != nil {
+				return err
+			}
+		}
+		// the names of the indexes are unique in the database of sqlite and postgres
+		if m.HasIndex(&model.SearchNode{}, "Parent") {
+			if err := m.DropIndex(&model.SearchNode{}, "Parent"); err != nil {
+				return err
+			}
+		}
+		if err := m.RenameTable(table, old); err != nil {
+			return err
+		}
+		if err := m.CreateTable(&model.SearchNode{}); err != nil {
+			return err
+		}
+		// the columns not in the model any more, such as the generated fts column of postgres, are dropped
+		var names []string
+		for _, c := range columns {
+			if m.HasColumn(&model.SearchNode{}, c.Name()) {
+				names = append(names, columnName(c.Name()))
+			}
+		}
+		if err := tx.Exec(fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", table,
+			strings.Join(names, ", "), strings.Join(names, ", "), old)).Error; err != nil {
+			return err
+		}
+		return m.DropTable(old)
+	})
+	return errors.Wrapf(err, "failed add id to search nodes")
+}
+
+// sqliteFTSTriggers returns the statements creating the triggers which keep the FTS5 table in sync
+func sqliteFTSTriggers() []string {
+	table, fts := searchNodesTable(), searchNodesFTSTable()
+	insert := fmt.Sprintf("INSERT INTO %s(rowid, name, content) VALUES (new.id, new.name, new.content);", fts)
+	del := fmt.Sprintf("INSERT INTO %s(%s, rowid, name, content) VALUES ('delete', old.id, old.name, old.content);", fts, fts)
+	return []string{
+		fmt.Sprintf("CREATE TRIGGER %s_ai AFTER INSERT ON %s BEGIN %s END", fts, table, insert),
+		fmt.Sprintf("CREATE TRIGGER %s_ad AFTER DELETE ON %s BEGIN %s END", fts, table, del),
+		fmt.Sprintf("CREATE TRIGGER %s_au AFTER UPDATE ON %s BEGIN %s %s END", fts, table, del, insert),
+	}
+}
+
+// ClearSearchNodesFTS delete all the nodes, the triggers of sqlite are dropped in the meantime
+// so that the FTS5 table is cleared at once
+func ClearSearchNodesFTS() error {
+	if conf.Conf.Database.Type != "sqlite3" {
+		return ClearSearchNodes()
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		fts := searchNodesFTSTable()
+		stmts := []string{
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s_ai", fts),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s_ad", fts),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s_au", fts),
+			fmt.Sprintf("DELETE FROM %s", searchNodesTable()),
+			fmt.Sprintf("INSERT INTO %s(%s) VALUES ('delete-all')", fts, fts),
+		}
+		stmts = append(stmts, sqliteFTSTriggers()...)
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return errors.Wrapf(err, "failed clear search nodes")
+			}
+		}
+		return nil
+	})
+}
+
+// SearchNodeFTS search the nodes by the full text index, which are ranked by the relevance
+// unless the order is given. The wildcard and regex modes and the keywords the index can't
+// match are searched by SearchNode.
+func SearchNodeFTS(req model.SearchReq) ([]model.SearchNode, int64, error) {
+	if req.Keywords == "" || req.Mode == model.SearchWildcard || req.Mode == model.SearchRegex {
+		return SearchNode(req, false)
+	}
+	searchDB := db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent))
+	var rank clause.Expr
+	switch conf.Conf.Database.Type {
+	case "sqlite3":
+		match, ok := fts5Match(req)
+		if !ok {
+			return SearchNode(req, false)
+		}
+		// the names weigh more than the contents, and the better matches have lower bm25
+		fts := searchNodesFTSTable()
+		searchDB = searchDB.Joins(fmt.Sprintf("JOIN (SELECT rowid AS fts_rowid, bm25(%s, 10.0, 1.0) AS fts_rank FROM %s WHERE %s MATCH ?) AS m ON m.fts_rowid = %s.id",
+			fts, fts, fts, searchNodesTable()), match)
+		rank = clause.Expr{SQL: "m.fts_rank"}
+	case "postgres":
+		// the names are also matched by the trigram index, as they are not split well by to_tsvector
+		if req.Mode == model.SearchPhrase {
+			searchDB = searchDB.Where("(fts @@ phraseto_tsquery('simple', ?) OR name ILIKE ? ESCAPE '!')",
+				req.Keywords, "%"+escapeLike(req.Keywords)+"%")
+			rank = clause.Expr{SQL: "ts_rank(fts, phraseto_tsquery('simple', ?)) + similarity(name, ?) DESC",
+				Vars: []any{req.Keywords, req.Keywords}}
+			break
+		}
+		for _, keyword := range strings.Fields(req.Keywords) {
+			searchDB = searchDB.Where("(fts @@ plainto_tsquery('simple', ?) OR name ILIKE ? ESCAPE '!')",
+				keyword, "%"+escapeLike(keyword)+"%")
+		}
+		rank = clause.Expr{SQL: "ts_rank(fts, plainto_tsquery('simple', ?)) + similarity(name, ?) DESC",
+			Vars: []any{req.Keywords, req.Keywords}}
+	default:
+		return nil, 0, errors.Errorf("full text index is not supported by %s", conf.Conf.Database.Type)
+	}
+	var order any = searchOrder(req)
+	if req.OrderBy == "" {
+		order = clause.OrderBy{Expression: rank}
+	}
+	return findSearchNodes(whereFilters(searchDB, req), req, order)
+}
+
+// fts5Match returns the FTS5 query of the keywords, the terms shorter than 3 characters
+// can't be matched by the trigram tokenizer
+func fts5Match(req model.SearchReq) (string, bool) {
+	terms := strings.Fields(req.Keywords)
+	if req.Mode == model.SearchPhrase {
+		terms = []string{req.Keywords}
+	}
+	for i, term := range terms {
+		if utf8.RuneCountInString(term) < 3 {
+			return "", false
+		}
+		terms[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(terms, " "), len(terms) > 0
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetShareById(id string) (*model.Share, error) {
+	var s model.Share
+	if err := db.Where(columnName("id")+" = ?", id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get share")
+	}
+	return &s, nil
+}
+
+// GetShares returns the shares created by the user, or all shares if creatorId is 0
+func GetShares(creatorId uint, pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	shareDB := db.Model(&model.Share{})
+	if creatorId != 0 {
+		shareDB = shareDB.Where(columnName("creator_id")+" = ?", creatorId)
+	}
+	if err = shareDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get shares count")
+	}
+	if err = shareDB.Order(columnName("created") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&shares).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find shares")
+	}
+	return shares, count, nil
+}
+
+func CreateShare(s *model.Share) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func UpdateShare(s *model.Share) error {
+	return errors.WithStack(db.Save(s).Error)
+}
+
+func DeleteShareById(id string) error {
+	return errors.WithStack(db.Where(columnName("id")+" = ?", id).Delete(&model.Share{}).Error)
+}
+
+// IncreaseShareAccessCount returns false if the share has reached the max access count
+func IncreaseShareAccessCount(id string) (bool, error) {
+	res := db.Model(&model.Share{}).
+		Where(columnName("id")+" = ? AND ("+columnName("max_access")+" <= 0 OR "+columnName("access_count")+" < "+columnName("max_access")+")", id).
+		UpdateColumn("access_count", gorm.Expr(columnName("access_count")+" + 1"))
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
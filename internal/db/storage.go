@@ -24,6 +24,12 @@ func UpdateStorage(storage *model.Storage) error {
 
 // DeleteStorageById just delete storage from database by id
 func DeleteStorageById(id uint) error {
+	if err := db.Where(columnName("storage_id")+" = ?", id).Delete(&model.StorageHealthLog{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	if err := db.Delete(&model.IndexJob{}, id).Error; err != nil {
+		return errors.WithStack(err)
+	}
 	return errors.WithStack(db.Delete(&model.Storage{}, id).Error)
 }
 
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func CreateStorageHealthLog(l *model.StorageHealthLog) error {
+	return errors.WithStack(db.Create(l).Error)
+}
+
+func GetStorageHealthLogs(storageId uint, pageIndex, pageSize int) (logs []model.StorageHealthLog, count int64, err error) {
+	logDB := db.Model(&model.StorageHealthLog{}).Where(columnName("storage_id")+" = ?", storageId)
+	if err = logDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get storage health logs count")
+	}
+	if err = logDB.Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find storage health logs")
+	}
+	return logs, count, nil
+}
+
+// PruneStorageHealthLogs keep only the latest keep logs of the storage
+func PruneStorageHealthLogs(storageId uint, keep int) error {
+	var ids []uint
+	err := db.Model(&model.StorageHealthLog{}).Where(columnName("storage_id")+" = ?", storageId).
+		Order(columnName("id")+" desc").Offset(keep).Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(db.Where(columnName("storage_id")+" = ? AND "+columnName("id")+" <= ?", storageId, ids[0]).
+		Delete(&model.StorageHealthLog{}).Error)
+}
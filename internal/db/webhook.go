@@ -0,0 +1,64 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetWebhookById(id uint) (*model.Webhook, error) {
+	var w model.Webhook
+	if err := db.First(&w, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get webhook")
+	}
+	return &w, nil
+}
+
+func GetWebhooks() ([]model.Webhook, error) {
+	var webhooks []model.Webhook
+	if err := db.Order(columnName("id")).Find(&webhooks).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find webhooks")
+	}
+	return webhooks, nil
+}
+
+func CreateWebhook(w *model.Webhook) error {
+	return errors.WithStack(db.Create(w).Error)
+}
+
+func UpdateWebhook(w *model.Webhook) error {
+	return errors.WithStack(db.Save(w).Error)
+}
+
+func DeleteWebhookById(id uint) error {
+	if err := db.Where(columnName("webhook_id")+" = ?", id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(db.Delete(&model.Webhook{}, id).Error)
+}
+
+func CreateWebhookDelivery(d *model.WebhookDelivery) error {
+	return errors.WithStack(db.Create(d).Error)
+}
+
+func GetWebhookDeliveries(webhookId uint, pageIndex, pageSize int) (deliveries []model.WebhookDelivery, count int64, err error) {
+	deliveryDB := db.Model(&model.WebhookDelivery{}).Where(columnName("webhook_id")+" = ?", webhookId)
+	if err = deliveryDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get webhook deliveries count")
+	}
+	if err = deliveryDB.Order(columnName("id") + " desc").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find webhook deliveries")
+	}
+	return deliveries, count, nil
+}
+
+// PruneWebhookDeliveries keep only the latest keep deliveries of the webhook
+func PruneWebhookDeliveries(webhookId uint, keep int) error {
+	var ids []uint
+	err := db.Model(&model.WebhookDelivery{}).Where(columnName("webhook_id")+" = ?", webhookId).
+		Order(columnName("id")+" desc").Offset(keep).Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(db.Where(columnName("webhook_id")+" = ? AND "+columnName("id")+" <= ?", webhookId, ids[0]).
+		Delete(&model.WebhookDelivery{}).Error)
+}
@@ -0,0 +1,44 @@
+package driver
+
+import (
+	"context"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+)
+
+// UploadCheckpoint keeps the state of a chunked upload, so that a failed upload can be resumed
+// from the last confirmed chunk instead of byte zero. It's provided by the caller of Put via ctx,
+// e.g. the copy task persists it with the task, the drivers which don't support resuming just ignore it.
+type UploadCheckpoint interface {
+	// Load the saved state into v, return false if there is no state or it can't be decoded
+	Load(v any) bool
+	// Save the state, it should be called each time a chunk is confirmed by the storage
+	Save(v any)
+	// Clear the state, it should be called if the saved state is no longer valid
+	Clear()
+}
+
+func WithUploadCheckpoint(ctx context.Context, cp UploadCheckpoint) context.Context {
+	return context.WithValue(ctx, conf.UploadCheckpointKey, cp)
+}
+
+// GetUploadCheckpoint get the checkpoint from ctx, a no-op one is returned if there is none
+func GetUploadCheckpoint(ctx context.Context) UploadCheckpoint {
+	if cp, ok := ctx.Value(conf.UploadCheckpointKey).(UploadCheckpoint); ok && cp != nil {
+		return cp
+	}
+	return nopCheckpoint{}
+}
+
+type nopCheckpoint struct{}
+
+func (nopCheckpoint) Load(v any) bool { return false }
+func (nopCheckpoint) Save(v any)      {}
+func (nopCheckpoint) Clear()          {}
+
+// HasUploadCheckpoint report whether the state of upload can be saved by the caller of Put,
+// drivers may use a slower but resumable way to upload only in this case
+func HasUploadCheckpoint(ctx context.Context) bool {
+	cp, ok := ctx.Value(conf.UploadCheckpointKey).(UploadCheckpoint)
+	return ok && cp != nil
+}
@@ -208,3 +208,17 @@ type ArchiveDecompressResult interface {
 type Reference interface {
 	InitReference(storage Driver) error
 }
+
+type Watcher interface {
+	// Watch the changes of the storage until ctx is done, it's called in a new goroutine after Init succeeded
+	// notify should be called with the path (the same as Getter, relative to the root) of the folder whose
+	// children have been changed, then the list cache of the folder and its sub-folders will be invalidated
+	// return errs.NotSupport if the changes can't be watched with the current addition, e.g. disabled by user
+	Watch(ctx context.Context, notify func(dir string)) error
+}
+
+type HealthChecker interface {
+	// HealthCheck check if the storage is still usable, e.g. the token is not expired,
+	// it's called periodically instead of listing the root folder
+	HealthCheck(ctx context.Context) error
+}
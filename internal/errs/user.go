@@ -8,3 +8,5 @@ var (
 	WrongPassword      = errors.New("password is incorrect")
 	DeleteAdminOrGuest = errors.New("cannot delete admin or guest")
 )
+
+var QuotaExceeded = errors.New("quota exceeded")
@@ -0,0 +1,157 @@
+// Package event is the in-process bus of the events of the objects, tasks, storages and users,
+// the events are dispatched in order in a single goroutine, so the subscribers should not block
+package event
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ObjCreated           = "obj.created"
+	ObjUpdated           = "obj.updated"
+	ObjDeleted           = "obj.deleted"
+	TaskStateChanged     = "task.state_changed"
+	StorageStatusChanged = "storage.status_changed"
+	UserLogin            = "user.login"
+	// Ping is only sent to test the webhooks
+	Ping = "ping"
+)
+
+// Types are the types of the events which can be subscribed
+var Types = []string{ObjCreated, ObjUpdated, ObjDeleted, TaskStateChanged, StorageStatusChanged, UserLogin}
+
+type Event struct {
+	ID   string    `json:"id"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data"`
+}
+
+type ObjData struct {
+	Path string `json:"path"`
+	// SrcPath is the path before moved or renamed
+	SrcPath string `json:"src_path,omitempty"`
+	IsDir   bool   `json:"is_dir"`
+	Size    int64  `json:"size,omitempty"`
+	User    string `json:"user,omitempty"`
+}
+
+type TaskData struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+	User  string `json:"user,omitempty"`
+}
+
+type StorageData struct {
+	MountPath string `json:"mount_path"`
+	Driver    string `json:"driver"`
+	Status    string `json:"status"`
+	Healthy   bool   `json:"healthy"`
+}
+
+type LoginData struct {
+	Username string `json:"username"`
+	IP       string `json:"ip"`
+	Method   string `json:"method"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Handler func(e Event)
+
+type subscriber struct {
+	types   map[string]struct{}
+	handler Handler
+}
+
+var (
+	mu          sync.RWMutex
+	subscribers = make(map[int]*subscriber)
+	nextId      int
+	queue       = make(chan Event, 1024)
+	startOnce   sync.Once
+)
+
+// Subscribe call the handler with the events of the types, or all events if no type is given
+func Subscribe(handler Handler, types ...string) (unsubscribe func()) {
+	startOnce.Do(func() {
+		go dispatch()
+	})
+	s := &subscriber{handler: handler}
+	if len(types) > 0 {
+		s.types = make(map[string]struct{}, len(types))
+		for _, t := range types {
+			s.types[t] = struct{}{}
+		}
+	}
+	mu.Lock()
+	id := nextId
+	nextId++
+	subscribers[id] = s
+	mu.Unlock()
+	return func() {
+		mu.Lock()
+		delete(subscribers, id)
+		mu.Unlock()
+	}
+}
+
+// Publish send the event to the subscribers without blocking, it's dropped if the queue is full
+func Publish(typ string, data any) {
+	mu.RLock()
+	empty := len(subscribers) == 0
+	mu.RUnlock()
+	if empty {
+		return
+	}
+	e := New(typ, data)
+	select {
+	case queue <- e:
+	default:
+		log.Warnf("the event queue is full, drop event %s", e.Type)
+	}
+}
+
+// New returns the event with a new id
+func New(typ string, data any) Event {
+	return Event{
+		ID:   uuid.NewString(),
+		Type: typ,
+		Time: time.Now(),
+		Data: data,
+	}
+}
+
+func dispatch() {
+	for e := range queue {
+		mu.RLock()
+		handlers := make([]Handler, 0, len(subscribers))
+		for _, s := range subscribers {
+			if s.types == nil {
+				handlers = append(handlers, s.handler)
+			} else if _, ok := s.types[e.Type]; ok {
+				handlers = append(handlers, s.handler)
+			}
+		}
+		mu.RUnlock()
+		for _, h := range handlers {
+			call(h, e)
+		}
+	}
+}
+
+func call(h Handler, e Event) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Errorf("panic in the handler of event %s: %v", e.Type, err)
+		}
+	}()
+	h(e)
+}
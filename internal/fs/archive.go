@@ -48,15 +48,17 @@ func (t *ArchiveDownloadTask) GetStatus() string {
 	return t.status
 }
 
-func (t *ArchiveDownloadTask) Run() error {
+func (t *ArchiveDownloadTask) Run() (err error) {
 	t.ReinitCtx()
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
+	defer t.StartRun("archive_download", t.GetName())(&err)
 	uploadTask, err := t.RunWithoutPushUploadTask()
 	if err != nil {
 		return err
 	}
+	uploadTask.ParentID = t.GetID()
 	ArchiveContentUploadTaskManager.Add(uploadTask)
 	return nil
 }
@@ -151,11 +153,12 @@ func (t *ArchiveContentUploadTask) GetStatus() string {
 	return t.status
 }
 
-func (t *ArchiveContentUploadTask) Run() error {
+func (t *ArchiveContentUploadTask) Run() (err error) {
 	t.ReinitCtx()
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
+	defer t.StartRun("archive_upload", t.GetName())(&err)
 	return t.RunWithNextTaskCallback(func(nextTsk *ArchiveContentUploadTask) error {
 		ArchiveContentUploadTaskManager.Add(nextTsk)
 		return nil
@@ -199,7 +202,8 @@ func (t *ArchiveContentUploadTask) RunWithNextTaskCallback(f func(nextTsk *Archi
 			}
 			err = f(&ArchiveContentUploadTask{
 				TaskExtension: task.TaskExtension{
-					Creator: t.GetCreator(),
+					Creator:  t.GetCreator(),
+					ParentID: t.GetID(),
 				},
 				ObjName:      entry.Name(),
 				InPlace:      false,
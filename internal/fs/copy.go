@@ -28,6 +28,8 @@ type CopyTask struct {
 	dstStorage   driver.Driver `json:"-"`
 	SrcStorageMp string        `json:"src_storage_mp"`
 	DstStorageMp string        `json:"dst_storage_mp"`
+	// Checkpoint is the state of chunked upload, so that a retried task can resume the upload
+	Checkpoint *task.UploadCheckpoint `json:"checkpoint,omitempty"`
 }
 
 func (t *CopyTask) GetName() string {
@@ -38,12 +40,12 @@ func (t *CopyTask) GetStatus() string {
 	return t.Status
 }
 
-func (t *CopyTask) Run() error {
+func (t *CopyTask) Run() (err error) {
 	t.ReinitCtx()
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
-	var err error
+	defer t.StartRun("copy", t.GetName())(&err)
 	if t.srcStorage == nil {
 		t.srcStorage, err = op.GetStorageByMountPath(t.SrcStorageMp)
 	}
@@ -138,7 +140,8 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, src
 			dstObjPath := stdpath.Join(dstDirPath, srcObj.GetName())
 			CopyTaskManager.Add(&CopyTask{
 				TaskExtension: task.TaskExtension{
-					Creator: t.GetCreator(),
+					Creator:  t.GetCreator(),
+					ParentID: t.GetID(),
 				},
 				srcStorage:   srcStorage,
 				dstStorage:   dstStorage,
@@ -175,5 +178,16 @@ func copyFileBetween2Storages(tsk *CopyTask, srcStorage, dstStorage driver.Drive
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] stream", srcFilePath)
 	}
-	return op.Put(tsk.Ctx(), dstStorage, dstDirPath, ss, tsk.SetProgress, true)
+	if tsk.Checkpoint == nil {
+		tsk.Checkpoint = &task.UploadCheckpoint{}
+	}
+	// the saved state is discarded if the src file is changed
+	tsk.Checkpoint.Bind(fmt.Sprintf("%s|%d|%d|%s", stdpath.Join(tsk.SrcStorageMp, srcFilePath), srcFile.GetSize(),
+		srcFile.ModTime().Unix(), stdpath.Join(tsk.DstStorageMp, dstDirPath)), tsk.Persist)
+	ctx := driver.WithUploadCheckpoint(tsk.Ctx(), tsk.Checkpoint)
+	err = op.Put(ctx, dstStorage, dstDirPath, ss, tsk.SetProgress, true)
+	if err == nil {
+		tsk.Checkpoint.Clear()
+	}
+	return err
 }
@@ -2,15 +2,17 @@ package fs
 
 import (
 	"context"
-	log "github.com/sirupsen/logrus"
 	"io"
+	stdpath "path"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 // the param named path of functions in this package is a mount path
@@ -57,6 +59,18 @@ func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, m
 	return res, file, nil
 }
 
+// LinkDown is Link for serving the content of the file, which is recorded as a download,
+// Link is also called to get the info of the file, e.g. by FsGet, which is not recorded
+func LinkDown(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
+	res, file, err := Link(ctx, path, args)
+	var size int64
+	if file != nil {
+		size = file.GetSize()
+	}
+	audit.Record(ctx, model.AuditDownload, path, "", size, err)
+	return res, file, err
+}
+
 func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	err := makeDir(ctx, path, lazyCache...)
 	if err != nil {
@@ -67,6 +81,7 @@ func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
 
 func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) error {
 	err := move(ctx, srcPath, dstDirPath, lazyCache...)
+	audit.Record(ctx, model.AuditMove, srcPath, dstDirPath, 0, err)
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
 	}
@@ -75,14 +90,26 @@ func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) er
 
 func Copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool) (task.TaskExtensionInfo, error) {
 	res, err := _copy(ctx, srcObjPath, dstDirPath, lazyCache...)
+	audit.Record(ctx, model.AuditCopy, srcObjPath, dstDirPath, 0, err)
 	if err != nil {
 		log.Errorf("failed copy %s to %s: %+v", srcObjPath, dstDirPath, err)
 	}
 	return res, err
 }
 
+// Sync add a task to make dstPath the same as srcPath, extraneous files in dstPath are deleted if del is set,
+// metaPass is used to list the folders protected by meta password
+func Sync(ctx context.Context, srcPath, dstPath string, del, dryRun bool, metaPass string) (task.TaskExtensionInfo, error) {
+	res, err := _sync(ctx, srcPath, dstPath, del, dryRun, metaPass)
+	if err != nil {
+		log.Errorf("failed sync %s to %s: %+v", srcPath, dstPath, err)
+	}
+	return res, err
+}
+
 func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
 	err := rename(ctx, srcPath, dstName, lazyCache...)
+	audit.Record(ctx, model.AuditRename, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName), 0, err)
 	if err != nil {
 		log.Errorf("failed rename %s to %s: %+v", srcPath, dstName, err)
 	}
@@ -91,6 +118,7 @@ func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 
 func Remove(ctx context.Context, path string) error {
 	err := remove(ctx, path)
+	audit.Record(ctx, model.AuditRemove, path, "", 0, err)
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", path, err)
 	}
@@ -99,6 +127,7 @@ func Remove(ctx context.Context, path string) error {
 
 func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer, lazyCache ...bool) error {
 	err := putDirectly(ctx, dstDirPath, file, lazyCache...)
+	audit.Record(ctx, model.AuditUpload, "", stdpath.Join(dstDirPath, file.GetName()), file.GetSize(), err)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
@@ -107,6 +136,7 @@ func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 
 func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (task.TaskExtensionInfo, error) {
 	t, err := putAsTask(ctx, dstDirPath, file)
+	audit.Record(ctx, model.AuditUpload, "", stdpath.Join(dstDirPath, file.GetName()), file.GetSize(), err)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
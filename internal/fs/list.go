@@ -2,7 +2,9 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 
+	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -15,7 +17,7 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 	meta, _ := ctx.Value("meta").(*model.Meta)
 	user, _ := ctx.Value("user").(*model.User)
 	virtualFiles := op.GetStorageVirtualFilesByPath(path)
-	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	storage, actualPath, err := op.GetStorageAndActualPathFor(ctx, path)
 	if err != nil && len(virtualFiles) == 0 {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
@@ -36,17 +38,55 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 		}
 	}
 
+	if storage != nil {
+		_objs = hideDirs(storage, actualPath, _objs)
+	}
 	om := model.NewObjMerge()
 	if whetherHide(user, meta, path) {
 		om.InitHideReg(meta.Hide)
 	}
 	objs := om.Merge(_objs, virtualFiles...)
-	return objs, nil
+	return filterByACLs(user, path, objs), nil
+}
+
+// filterByACLs hide the objs which the user can't access by the ACLs and the base paths of the groups
+func filterByACLs(user *model.User, path string, objs []model.Obj) []model.Obj {
+	if user == nil || !user.HasLimitedPaths() {
+		return objs
+	}
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		if user.CanAccessPath(stdpath.Join(path, obj.GetName())) {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
+// hideDirs hide the folders keeping the old versions and the removed objects, they can still be accessed by path
+func hideDirs(storage driver.Driver, actualPath string, objs []model.Obj) []model.Obj {
+	var names []string
+	for _, dir := range []string{op.GetVersionsDir(storage), op.GetTrashDir(storage)} {
+		if dir != "" && utils.PathEqual(stdpath.Dir(dir), actualPath) {
+			names = append(names, stdpath.Base(dir))
+		}
+	}
+	if len(names) == 0 {
+		return objs
+	}
+	// the objs may be cached, so don't modify it in place
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		if !utils.SliceContains(names, obj.GetName()) {
+			res = append(res, obj)
+		}
+	}
+	return res
 }
 
 func whetherHide(user *model.User, meta *model.Meta, path string) bool {
 	// if is admin, don't hide
-	if user == nil || user.CanSeeHides() {
+	if user == nil || user.At(path).CanSeeHides() {
 		return false
 	}
 	// if meta is nil, don't hide
@@ -0,0 +1,31 @@
+package fs
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestHideDirs(t *testing.T) {
+	storage := &local.Local{}
+	storage.SetStorage(model.Storage{
+		Trash:      model.Trash{TrashPolicy: "enabled", TrashPath: "/.trash"},
+		Versioning: model.Versioning{VersionsKeep: 1},
+	})
+	objs := []model.Obj{
+		&model.Object{Name: ".trash", IsFolder: true},
+		&model.Object{Name: ".versions", IsFolder: true},
+		&model.Object{Name: "a.txt"},
+	}
+	if res := hideDirs(storage, "/", objs); len(res) != 1 || res[0].GetName() != "a.txt" {
+		t.Errorf("the trash and versions folders should be hidden in root, got %+v", res)
+	}
+	if res := hideDirs(storage, "/sub", objs); len(res) != len(objs) {
+		t.Errorf("the folders with the same names in sub folders should not be hidden, got %+v", res)
+	}
+	storage.SetStorage(model.Storage{Trash: model.Trash{TrashPolicy: "disabled", TrashPath: "/.trash"}})
+	if res := hideDirs(storage, "/", objs); len(res) != len(objs) {
+		t.Errorf("nothing should be hidden if the trash and versioning are disabled, got %+v", res)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/task"
 	"github.com/pkg/errors"
 	"github.com/xhofe/tache"
+	stdpath "path"
 	"time"
 )
 
@@ -28,11 +29,20 @@ func (t *UploadTask) GetStatus() string {
 	return "uploading"
 }
 
-func (t *UploadTask) Run() error {
+func (t *UploadTask) Run() (err error) {
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
-	return op.Put(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true)
+	defer t.StartRun("upload", t.GetName())(&err)
+	if err := op.ReserveQuota(t.Creator, t.file.GetSize()); err != nil {
+		return err
+	}
+	if err := op.Put(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true); err != nil {
+		op.ReleaseQuota(t.Creator, t.file.GetSize())
+		return err
+	}
+	op.AddUsage(t.Creator, t.storage, stdpath.Join(t.dstDirActualPath, t.file.GetName()), t.file.GetSize())
+	return nil
 }
 
 var UploadTaskManager *tache.Manager[*UploadTask]
@@ -46,6 +56,10 @@ func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 	if storage.Config().NoUpload {
 		return nil, errors.WithStack(errs.UploadNotSupported)
 	}
+	taskCreator, _ := ctx.Value("user").(*model.User) // taskCreator is nil when convert failed
+	if err = op.CheckQuota(taskCreator, file.GetSize()); err != nil {
+		return nil, err
+	}
 	if file.NeedStore() {
 		_, err := file.CacheFullInTempFile()
 		if err != nil {
@@ -54,7 +68,6 @@ func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer)
 		//file.SetReader(tempFile)
 		//file.SetTmpFile(tempFile)
 	}
-	taskCreator, _ := ctx.Value("user").(*model.User) // taskCreator is nil when convert failed
 	t := &UploadTask{
 		TaskExtension: task.TaskExtension{
 			Creator: taskCreator,
@@ -77,5 +90,14 @@ func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if storage.Config().NoUpload {
 		return errors.WithStack(errs.UploadNotSupported)
 	}
-	return op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
+	user, _ := ctx.Value("user").(*model.User)
+	if err = op.ReserveQuota(user, file.GetSize()); err != nil {
+		return err
+	}
+	if err = op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...); err != nil {
+		op.ReleaseQuota(user, file.GetSize())
+		return err
+	}
+	op.AddUsage(user, storage, stdpath.Join(dstDirActualPath, file.GetName()), file.GetSize())
+	return nil
 }
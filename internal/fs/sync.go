@@ -0,0 +1,389 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+const (
+	SyncActionCopy   = "copy"
+	SyncActionDelete = "delete"
+	SyncActionSkip   = "skip"
+)
+
+type SyncAction struct {
+	Type string `json:"type"`
+	// Path is relative to the src and dst dir
+	Path   string `json:"path"`
+	IsDir  bool   `json:"is_dir"`
+	Reason string `json:"reason"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SyncTask makes the dst dir the same as the src dir, only the new and changed
+// files are copied (by CopyTaskManager), and the extraneous ones are deleted if Delete is set
+type SyncTask struct {
+	task.TaskExtension
+	Status  string `json:"-"`
+	SrcPath string `json:"src_path"`
+	DstPath string `json:"dst_path"`
+	Delete  bool   `json:"delete"`
+	// DryRun only plan the actions without doing them
+	DryRun  bool         `json:"dry_run"`
+	Actions []SyncAction `json:"actions"`
+	// MetaPass is used to list the folders protected by meta password, it's not persisted
+	MetaPass string `json:"-"`
+	mu       sync.Mutex
+}
+
+func (t *SyncTask) GetName() string {
+	name := fmt.Sprintf("sync [%s] to [%s]", t.SrcPath, t.DstPath)
+	if t.DryRun {
+		name += " (dry run)"
+	}
+	return name
+}
+
+func (t *SyncTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *SyncTask) GetDetail() any {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]SyncAction(nil), t.Actions...)
+}
+
+func (t *SyncTask) Run() (err error) {
+	t.ReinitCtx()
+	t.ClearEndTime()
+	t.SetStartTime(time.Now())
+	defer func() { t.SetEndTime(time.Now()) }()
+	defer t.StartRun("sync", t.GetName())(&err)
+	t.mu.Lock()
+	t.Actions = nil
+	t.mu.Unlock()
+	// the user is needed by the copy tasks added
+	ctx := context.WithValue(t.Ctx(), "user", t.GetCreator())
+	t.Status = "listing src objs"
+	srcObj, err := get(ctx, t.SrcPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s]", t.SrcPath)
+	}
+	if !srcObj.IsDir() {
+		return errors.Errorf("src [%s] is not a dir", t.SrcPath)
+	}
+	srcObjs, srcPaths, err := syncListTree(ctx, t.SrcPath, t.MetaPass)
+	if err != nil {
+		return err
+	}
+	t.Status = "listing dst objs"
+	dstObjs := map[string]model.Obj{}
+	var dstPaths []string
+	if _, err = get(ctx, t.DstPath); err == nil {
+		dstObjs, dstPaths, err = syncListTree(ctx, t.DstPath, t.MetaPass)
+		if err != nil {
+			return err
+		}
+	} else if !errs.IsObjectNotFound(err) {
+		return errors.WithMessagef(err, "failed get dst [%s]", t.DstPath)
+	}
+	t.Status = "comparing"
+	actions := planSync(srcObjs, srcPaths, dstObjs, dstPaths, t.Delete)
+	t.mu.Lock()
+	t.Actions = actions
+	t.mu.Unlock()
+	if t.DryRun {
+		t.SetProgress(100)
+		t.Status = syncSummary(actions, "planned")
+		return nil
+	}
+	var failed int
+	setErr := func(action *SyncAction, err error) {
+		failed++
+		t.mu.Lock()
+		action.Error = err.Error()
+		t.mu.Unlock()
+	}
+	// the copies between storages are done by the copy tasks, which are waited for before the sync is done
+	copies := map[*SyncAction][]task.TaskExtensionInfo{}
+	var tasks []tache.TaskBase
+	for i := range actions {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		action := &actions[i]
+		t.Status = fmt.Sprintf("%s %s", action.Type, action.Path)
+		copyTasks, err := t.do(ctx, action, srcObjs, srcPaths)
+		if err != nil {
+			setErr(action, err)
+		}
+		copies[action] = copyTasks
+		for _, copyTask := range copyTasks {
+			tasks = append(tasks, copyTask)
+		}
+		t.SetProgress(float64(i+1) / float64(len(actions)) * 100)
+	}
+	if len(tasks) > 0 {
+		t.Status = fmt.Sprintf("waiting for %d copy tasks", len(tasks))
+		if err = task.WaitTasks(ctx, tasks...); err != nil {
+			for _, copyTask := range tasks {
+				copyTask.Cancel()
+			}
+			return err
+		}
+		for action, copyTasks := range copies {
+			for _, copyTask := range copyTasks {
+				if err = task.GetTaskErr(copyTask); err != nil && action.Error == "" {
+					setErr(action, errors.WithMessage(err, copyTask.GetName()))
+				}
+			}
+		}
+	}
+	t.SetProgress(100)
+	t.Status = syncSummary(actions, "done")
+	if failed > 0 {
+		return errors.Errorf("%d of %d actions failed", failed, len(actions))
+	}
+	return nil
+}
+
+// do the action, the copy tasks are returned if the copies are done in background
+func (t *SyncTask) do(ctx context.Context, action *SyncAction, srcObjs map[string]model.Obj, srcPaths []string) ([]task.TaskExtensionInfo, error) {
+	srcPath, dstPath := stdpath.Join(t.SrcPath, action.Path), stdpath.Join(t.DstPath, action.Path)
+	switch action.Type {
+	case SyncActionDelete:
+		return nil, remove(ctx, dstPath)
+	case SyncActionCopy:
+		if !action.IsDir || sameStorage(srcPath, dstPath) {
+			copyTask, err := _copy(ctx, srcPath, stdpath.Dir(dstPath))
+			if copyTask == nil {
+				return nil, err
+			}
+			return []task.TaskExtensionInfo{copyTask}, err
+		}
+		// the copy task of a dir adds the tasks of its children, which can't be waited for,
+		// so the dir is copied file by file
+		return t.copyDir(ctx, action.Path, srcObjs, srcPaths)
+	}
+	return nil, nil
+}
+
+func (t *SyncTask) copyDir(ctx context.Context, dir string, srcObjs map[string]model.Obj, srcPaths []string) ([]task.TaskExtensionInfo, error) {
+	var tasks []task.TaskExtensionInfo
+	if err := makeDir(ctx, stdpath.Join(t.DstPath, dir)); err != nil {
+		return nil, err
+	}
+	// the paths are in pre-order, so the parents are made before their children
+	for _, p := range srcPaths {
+		if p == dir || !utils.IsSubPath(dir, p) {
+			continue
+		}
+		dstPath := stdpath.Join(t.DstPath, p)
+		if srcObjs[p].IsDir() {
+			if err := makeDir(ctx, dstPath); err != nil {
+				return tasks, err
+			}
+			continue
+		}
+		copyTask, err := _copy(ctx, stdpath.Join(t.SrcPath, p), stdpath.Dir(dstPath))
+		if copyTask != nil {
+			tasks = append(tasks, copyTask)
+		}
+		if err != nil {
+			return tasks, err
+		}
+	}
+	return tasks, nil
+}
+
+func sameStorage(srcPath, dstPath string) bool {
+	srcStorage, _, err := op.GetStorageAndActualPath(srcPath)
+	if err != nil {
+		return false
+	}
+	dstStorage, _, err := op.GetStorageAndActualPath(dstPath)
+	return err == nil && srcStorage.GetStorage() == dstStorage.GetStorage()
+}
+
+func syncSummary(actions []SyncAction, verb string) string {
+	count := map[string]int{}
+	for _, action := range actions {
+		count[action.Type]++
+	}
+	return fmt.Sprintf("%s: %d to copy, %d to delete, %d skipped", verb,
+		count[SyncActionCopy], count[SyncActionDelete], count[SyncActionSkip])
+}
+
+// syncListTree returns all the objs under root visible to the user in ctx by their paths relative
+// to root, the paths are in pre-order so that a dir is always before its children.
+// unlike WalkFS, the errors of listing are returned, otherwise a dir failed
+// to be listed would be treated as empty and the dst would be deleted
+func syncListTree(ctx context.Context, root, password string) (map[string]model.Obj, []string, error) {
+	user, _ := ctx.Value("user").(*model.User)
+	objs := map[string]model.Obj{}
+	var paths []string
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		fullPath := stdpath.Join(root, dir)
+		meta, err := getNearestMeta(user, fullPath)
+		if err != nil {
+			return err
+		}
+		if user != nil && !common.CanAccess(user, meta, fullPath, password) {
+			return errors.WithMessagef(errs.PermissionDenied, "failed list [%s]", fullPath)
+		}
+		list, err := List(context.WithValue(ctx, "meta", meta), fullPath, &ListArgs{Refresh: true})
+		if err != nil {
+			return errors.WithMessagef(err, "failed list [%s]", fullPath)
+		}
+		for _, obj := range list {
+			p := stdpath.Join(dir, obj.GetName())
+			objs[p] = obj
+			paths = append(paths, p)
+			if obj.IsDir() {
+				if err = walk(p); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return objs, paths, walk("/")
+}
+
+func planSync(srcObjs map[string]model.Obj, srcPaths []string, dstObjs map[string]model.Obj, dstPaths []string, del bool) []SyncAction {
+	var actions []SyncAction
+	// the deletions are done first, so that a dir can be replaced by a file with the same name
+	if del {
+		var deletedDir string
+		for _, p := range dstPaths {
+			if deletedDir != "" && utils.IsSubPath(deletedDir, p) {
+				continue
+			}
+			dst := dstObjs[p]
+			src, ok := srcObjs[p]
+			reason := "extraneous"
+			if ok {
+				if src.IsDir() == dst.IsDir() {
+					continue
+				}
+				reason = "type changed"
+			}
+			actions = append(actions, SyncAction{Type: SyncActionDelete, Path: p, IsDir: dst.IsDir(), Reason: reason})
+			if dst.IsDir() {
+				deletedDir = p
+			}
+		}
+	}
+	var copiedDir string
+	for _, p := range srcPaths {
+		if copiedDir != "" && utils.IsSubPath(copiedDir, p) {
+			continue
+		}
+		src := srcObjs[p]
+		dst, ok := dstObjs[p]
+		if ok && src.IsDir() != dst.IsDir() && !del {
+			actions = append(actions, SyncAction{Type: SyncActionSkip, Path: p, IsDir: src.IsDir(), Reason: "type changed"})
+			if src.IsDir() {
+				copiedDir = p
+			}
+			continue
+		}
+		var reason string
+		if !ok || src.IsDir() != dst.IsDir() {
+			reason = "new"
+		} else if !src.IsDir() {
+			reason = syncChanged(src, dst)
+		}
+		if reason == "" {
+			continue
+		}
+		// a new dir is copied as a whole
+		actions = append(actions, SyncAction{Type: SyncActionCopy, Path: p, IsDir: src.IsDir(), Reason: reason})
+		if src.IsDir() {
+			copiedDir = p
+		}
+	}
+	return actions
+}
+
+// syncChanged returns why the dst file is considered different from the src file, or "" if not.
+// the hashes are trusted if both sides have the same type of hash, because most storages
+// don't keep the modified time of the uploaded files
+func syncChanged(src, dst model.Obj) string {
+	if src.GetSize() != dst.GetSize() {
+		return "size changed"
+	}
+	dstHash := dst.GetHash()
+	for ht, h := range src.GetHash().All() {
+		if dh := dstHash.GetHash(ht); dh != "" && h != "" {
+			if dh != h {
+				return "hash changed"
+			}
+			return ""
+		}
+	}
+	if src.ModTime().After(dst.ModTime()) {
+		return "modified"
+	}
+	return ""
+}
+
+var SyncTaskManager *tache.Manager[*SyncTask]
+
+// getNearestMeta get the nearest meta of path for user, the group metas of user are applied
+func getNearestMeta(user *model.User, path string) (*model.Meta, error) {
+	var meta *model.Meta
+	var err error
+	if user != nil {
+		meta, err = op.GetNearestMetaForUser(user, path)
+	} else {
+		meta, err = op.GetNearestMeta(path)
+	}
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return nil, err
+	}
+	return meta, nil
+}
+
+func _sync(ctx context.Context, srcPath, dstPath string, del, dryRun bool, metaPass string) (task.TaskExtensionInfo, error) {
+	if utils.IsSubPath(srcPath, dstPath) || utils.IsSubPath(dstPath, srcPath) {
+		return nil, errors.New("the src and dst dir can't contain each other")
+	}
+	srcObj, err := get(ctx, srcPath)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed get src [%s]", srcPath)
+	}
+	if !srcObj.IsDir() {
+		return nil, errors.Errorf("src [%s] is not a dir", srcPath)
+	}
+	taskCreator, _ := ctx.Value("user").(*model.User)
+	t := &SyncTask{
+		TaskExtension: task.TaskExtension{
+			Creator: taskCreator,
+		},
+		SrcPath:  srcPath,
+		DstPath:  dstPath,
+		Delete:   del,
+		DryRun:   dryRun,
+		MetaPass: metaPass,
+	}
+	SyncTaskManager.Add(t)
+	return t, nil
+}
@@ -0,0 +1,71 @@
+package fs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func TestPlanSync(t *testing.T) {
+	now := time.Now()
+	file := func(name string, size int64, modified time.Time, md5 string) model.Obj {
+		obj := &model.Object{Name: name, Size: size, Modified: modified}
+		if md5 != "" {
+			obj.HashInfo = utils.NewHashInfo(utils.MD5, md5)
+		}
+		return obj
+	}
+	dir := func(name string) model.Obj {
+		return &model.Object{Name: name, IsFolder: true}
+	}
+	src := map[string]model.Obj{
+		"/same":      file("same", 1, now, ""),
+		"/size":      file("size", 2, now, ""),
+		"/hash":      file("hash", 1, now.Add(time.Hour), "a"),
+		"/newer":     file("newer", 1, now.Add(time.Hour), ""),
+		"/new":       dir("new"),
+		"/new/a":     file("a", 1, now, ""),
+		"/conflict":  file("conflict", 1, now, ""),
+		"/unchanged": file("unchanged", 1, now.Add(time.Hour), "a"),
+	}
+	srcPaths := []string{"/same", "/size", "/hash", "/newer", "/new", "/new/a", "/conflict", "/unchanged"}
+	dst := map[string]model.Obj{
+		"/same":       file("same", 1, now, ""),
+		"/size":       file("size", 1, now, ""),
+		"/hash":       file("hash", 1, now, "b"),
+		"/newer":      file("newer", 1, now, ""),
+		"/conflict":   dir("conflict"),
+		"/conflict/a": file("a", 1, now, ""),
+		"/unchanged":  file("unchanged", 1, now, "a"),
+		"/extra":      dir("extra"),
+		"/extra/a":    file("a", 1, now, ""),
+	}
+	dstPaths := []string{"/same", "/size", "/hash", "/newer", "/conflict", "/conflict/a", "/unchanged", "/extra", "/extra/a"}
+
+	expected := []SyncAction{
+		{Type: SyncActionCopy, Path: "/size", Reason: "size changed"},
+		{Type: SyncActionCopy, Path: "/hash", Reason: "hash changed"},
+		{Type: SyncActionCopy, Path: "/newer", Reason: "modified"},
+		{Type: SyncActionCopy, Path: "/new", IsDir: true, Reason: "new"},
+		{Type: SyncActionSkip, Path: "/conflict", Reason: "type changed"},
+	}
+	if actions := planSync(src, srcPaths, dst, dstPaths, false); !reflect.DeepEqual(actions, expected) {
+		t.Errorf("planSync() = %+v, want %+v", actions, expected)
+	}
+
+	expected = []SyncAction{
+		{Type: SyncActionDelete, Path: "/conflict", IsDir: true, Reason: "type changed"},
+		{Type: SyncActionDelete, Path: "/extra", IsDir: true, Reason: "extraneous"},
+		{Type: SyncActionCopy, Path: "/size", Reason: "size changed"},
+		{Type: SyncActionCopy, Path: "/hash", Reason: "hash changed"},
+		{Type: SyncActionCopy, Path: "/newer", Reason: "modified"},
+		{Type: SyncActionCopy, Path: "/new", IsDir: true, Reason: "new"},
+		{Type: SyncActionCopy, Path: "/conflict", Reason: "new"},
+	}
+	if actions := planSync(src, srcPaths, dst, dstPaths, true); !reflect.DeepEqual(actions, expected) {
+		t.Errorf("planSync() with delete = %+v, want %+v", actions, expected)
+	}
+}
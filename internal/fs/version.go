@@ -0,0 +1,34 @@
+package fs
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
+)
+
+// ListVersions returns the old versions of the file, the paths of them are mount paths
+func ListVersions(ctx context.Context, path string) ([]model.FileVersion, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	versions, err := op.ListVersions(ctx, storage, actualPath)
+	if err != nil {
+		return nil, err
+	}
+	for i := range versions {
+		versions[i].Path = stdpath.Join(op.GetVirtualMountPath(storage), versions[i].Path)
+	}
+	return versions, nil
+}
+
+func RestoreVersion(ctx context.Context, path, id string) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	return op.RestoreVersion(ctx, storage, actualPath, id)
+}
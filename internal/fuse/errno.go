@@ -0,0 +1,44 @@
+package fuse
+
+import (
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// errKind is the kind of the error of alist, which is converted to the errno of the platform
+type errKind int
+
+const (
+	errNone errKind = iota
+	errNotFound
+	errAccess
+	errNotDir
+	errIsDir
+	errReadOnly
+	errNotSupported
+	errIO
+)
+
+func errKindOf(err error) errKind {
+	if err == nil {
+		return errNone
+	}
+	cause := errors.Cause(err)
+	switch {
+	case errs.IsNotFoundError(err):
+		return errNotFound
+	case errors.Is(cause, errs.PermissionDenied), errors.Is(cause, errs.RelativePath):
+		return errAccess
+	case errors.Is(cause, errs.NotFolder):
+		return errNotDir
+	case errors.Is(cause, errs.NotFile):
+		return errIsDir
+	case errors.Is(cause, errs.UploadNotSupported):
+		return errReadOnly
+	case errs.IsNotImplement(err), errs.IsNotSupportError(err):
+		return errNotSupported
+	}
+	log.Errorf("[fuse] %+v", err)
+	return errIO
+}
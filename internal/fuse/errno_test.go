@@ -0,0 +1,29 @@
+package fuse
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/pkg/errors"
+)
+
+func TestErrKindOf(t *testing.T) {
+	tests := []struct {
+		err    error
+		expect errKind
+	}{
+		{nil, errNone},
+		{errors.WithStack(errs.ObjectNotFound), errNotFound},
+		{errors.WithMessage(errs.PermissionDenied, "failed"), errAccess},
+		{errs.NotFolder, errNotDir},
+		{errs.NotFile, errIsDir},
+		{errs.UploadNotSupported, errReadOnly},
+		{errs.NotImplement, errNotSupported},
+		{errors.New("unknown"), errIO},
+	}
+	for _, tt := range tests {
+		if got := errKindOf(tt.err); got != tt.expect {
+			t.Errorf("errKindOf(%v) = %d, want %d", tt.err, got, tt.expect)
+		}
+	}
+}
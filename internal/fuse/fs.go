@@ -1,170 +1,364 @@
-package fuse
+//go:build fuse
 
-import "github.com/winfsp/cgofuse/fuse"
+package fuse
 
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	stdpath "path"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// Fs expose the storages of alist as a fuse filesystem, all the operations
+// are done with the identity of user, so BasePath and permissions are respected
 type Fs struct {
-	RootFolder string
 	fuse.FileSystemBase
-}
-
-func (fs *Fs) Init() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Destroy() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mknod(path string, mode uint32, dev uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mkdir(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Unlink(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rmdir(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Link(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Symlink(target string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readlink(path string) (int, string) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rename(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chmod(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chown(path string, uid uint32, gid uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Access(path string, mask uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Open(path string, flags int) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Truncate(path string, size int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Flush(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Release(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsync(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Opendir(path string) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Releasedir(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Setxattr(path string, name string, value []byte, flags int) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getxattr(path string, name string) (int, []byte) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Removexattr(path string, name string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Listxattr(path string, fill func(name string) bool) int {
-	//TODO implement me
-	panic("implement me")
+	// RootFolder is the folder (relative to the base path of user) to be mounted
+	RootFolder string
+	ReadOnly   bool
+
+	user     *model.User
+	metaPass string
+	ctx      context.Context
+	uid, gid uint32
+
+	nextFh  atomic.Uint64
+	handles generic_sync.MapOf[uint64, *handle]
+	// files opened for writing, so that they can be stat before uploaded
+	writing generic_sync.MapOf[string, *handle]
+}
+
+func NewFs(user *model.User, rootFolder, metaPass string) *Fs {
+	ctx := context.WithValue(context.Background(), "user", user)
+	ctx = context.WithValue(ctx, "meta_pass", metaPass)
+	ctx = context.WithValue(ctx, conf.ProtocolKey, model.ProtocolFuse)
+	return &Fs{
+		RootFolder: utils.FixAndCleanPath(rootFolder),
+		user:       user,
+		metaPass:   metaPass,
+		ctx:        ctx,
+		uid:        uint32(os.Getuid()),
+		gid:        uint32(os.Getgid()),
+	}
+}
+
+func (f *Fs) Destroy() {
+	f.handles.Range(func(fh uint64, h *handle) bool {
+		if err := h.close(); err != nil {
+			log.Warnf("[fuse] failed to close %s: %+v", h.reqPath, err)
+		}
+		return true
+	})
+	f.handles.Clear()
+	f.writing.Clear()
+}
+
+func (f *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
+	// the capacity of storages is unknown, so report a large enough one
+	*stat = fuse.Statfs_t{
+		Bsize:   blockSize,
+		Frsize:  blockSize,
+		Blocks:  1 << 30,
+		Bfree:   1 << 30,
+		Bavail:  1 << 30,
+		Files:   1 << 30,
+		Ffree:   1 << 30,
+		Favail:  1 << 30,
+		Namemax: 255,
+	}
+	return 0
+}
+
+func (f *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
+	reqPath, err := f.reqPath(path)
+	if err != nil {
+		return errno(err)
+	}
+	if h, ok := f.writing.Load(reqPath); ok {
+		f.fillStat(&model.Object{Name: stdpath.Base(reqPath), Size: h.Size(), Modified: time.Now()}, stat)
+		return 0
+	}
+	ctx, err := f.readCtx(reqPath)
+	if err != nil {
+		return errno(err)
+	}
+	obj, err := fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	f.fillStat(obj, stat)
+	return 0
+}
+
+func (f *Fs) Opendir(path string) (int, uint64) {
+	reqPath, err := f.reqPath(path)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if _, err = f.readCtx(reqPath); err != nil {
+		return errno(err), ^uint64(0)
+	}
+	return 0, 0
+}
+
+func (f *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
+	reqPath, err := f.reqPath(path)
+	if err != nil {
+		return errno(err)
+	}
+	ctx, err := f.readCtx(reqPath)
+	if err != nil {
+		return errno(err)
+	}
+	objs, err := fs.List(ctx, reqPath, &fs.ListArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	fill(".", nil, 0)
+	fill("..", nil, 0)
+	for _, obj := range objs {
+		stat := &fuse.Stat_t{}
+		f.fillStat(obj, stat)
+		if !fill(obj.GetName(), stat, 0) {
+			break
+		}
+	}
+	return 0
+}
+
+func (f *Fs) Mkdir(path string, mode uint32) int {
+	reqPath, err := f.reqPath(path)
+	if err != nil {
+		return errno(err)
+	}
+	if err = f.canWrite(reqPath); err != nil {
+		return errno(err)
+	}
+	return errno(fs.MakeDir(f.ctx, reqPath))
+}
+
+func (f *Fs) remove(path string) int {
+	if f.ReadOnly {
+		return -fuse.EACCES
+	}
+	reqPath, err := f.reqPath(path)
+	if err != nil {
+		return errno(err)
+	}
+	if !f.user.At(reqPath).CanRemove() {
+		return -fuse.EACCES
+	}
+	return errno(fs.Remove(f.ctx, reqPath))
+}
+
+func (f *Fs) Unlink(path string) int {
+	return f.remove(path)
+}
+
+func (f *Fs) Rmdir(path string) int {
+	return f.remove(path)
+}
+
+func (f *Fs) Rename(oldpath string, newpath string) int {
+	if f.ReadOnly {
+		return -fuse.EACCES
+	}
+	srcPath, err := f.reqPath(oldpath)
+	if err != nil {
+		return errno(err)
+	}
+	dstPath, err := f.reqPath(newpath)
+	if err != nil {
+		return errno(err)
+	}
+	srcDir, srcBase := stdpath.Split(srcPath)
+	dstDir, dstBase := stdpath.Split(dstPath)
+	if srcDir == dstDir {
+		if !f.user.At(srcPath).CanRename() {
+			return -fuse.EACCES
+		}
+		return errno(fs.Rename(f.ctx, srcPath, dstBase))
+	}
+	srcUser, dstUser := f.user.At(srcPath), f.user.At(dstPath)
+	if !srcUser.CanMove() || !dstUser.CanMove() || (srcBase != dstBase && !dstUser.CanRename()) {
+		return -fuse.EACCES
+	}
+	if err = fs.Move(f.ctx, srcPath, dstDir); err != nil {
+		return errno(err)
+	}
+	if srcBase != dstBase {
+		return errno(fs.Rename(f.ctx, stdpath.Join(dstDir, srcBase), dstBase))
+	}
+	return 0
+}
+
+func (f *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
+	reqPath, err := f.reqPath(path)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if err = f.canWrite(reqPath); err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if flags&fuse.O_EXCL != 0 {
+		if _, err = fs.Get(f.ctx, reqPath, &fs.GetArgs{NoLog: true}); err == nil {
+			return -fuse.EEXIST, ^uint64(0)
+		}
+	}
+	h, err := newWriteHandle(f.ctx, reqPath, nil, true)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) Open(path string, flags int) (int, uint64) {
+	reqPath, err := f.reqPath(path)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	ctx, err := f.readCtx(reqPath)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	obj, err := fs.Get(ctx, reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if obj.IsDir() {
+		return -fuse.EISDIR, ^uint64(0)
+	}
+	if flags&fuse.O_ACCMODE == fuse.O_RDONLY {
+		return 0, f.addHandle(newReadHandle(ctx, reqPath, obj))
+	}
+	if err = f.canWrite(reqPath); err != nil {
+		return errno(err), ^uint64(0)
+	}
+	h, err := newWriteHandle(ctx, reqPath, obj, flags&fuse.O_TRUNC != 0)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) addHandle(h *handle) uint64 {
+	fh := f.nextFh.Add(1)
+	f.handles.Store(fh, h)
+	if h.writable() {
+		f.writing.Store(h.reqPath, h)
+	}
+	return fh
+}
+
+func (f *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
+	h, ok := f.handles.Load(fh)
+	if !ok {
+		return -fuse.EBADF
+	}
+	n, err := h.ReadAt(buff, ofst)
+	if n == 0 && err != nil && !errors.Is(err, io.EOF) {
+		return errno(err)
+	}
+	return n
+}
+
+func (f *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
+	h, ok := f.handles.Load(fh)
+	if !ok {
+		return -fuse.EBADF
+	}
+	if !h.writable() {
+		return -fuse.EBADF
+	}
+	n, err := h.WriteAt(buff, ofst)
+	if err != nil {
+		return errno(err)
+	}
+	return n
+}
+
+func (f *Fs) Truncate(path string, size int64, fh uint64) int {
+	if h, ok := f.handles.Load(fh); ok && h.writable() {
+		return errno(h.Truncate(size))
+	}
+	reqPath, err := f.reqPath(path)
+	if err != nil {
+		return errno(err)
+	}
+	if h, ok := f.writing.Load(reqPath); ok {
+		return errno(h.Truncate(size))
+	}
+	// only truncating to zero is supported without an opened file
+	if size != 0 {
+		return -fuse.ENOSYS
+	}
+	if err = f.canWrite(reqPath); err != nil {
+		return errno(err)
+	}
+	dir, name := stdpath.Split(reqPath)
+	return errno(fs.PutDirectly(f.ctx, dir, &stream.FileStream{
+		Ctx: f.ctx,
+		Obj: &model.Object{
+			Name:     name,
+			Modified: time.Now(),
+		},
+		Mimetype: utils.GetMimeType(name),
+		Reader:   bytes.NewReader(nil),
+	}))
+}
+
+func (f *Fs) Release(path string, fh uint64) int {
+	h, ok := f.handles.Load(fh)
+	if !ok {
+		return -fuse.EBADF
+	}
+	f.handles.Delete(fh)
+	err := h.commit()
+	// the file is still stat from the handle until it's uploaded
+	if w, ok := f.writing.Load(h.reqPath); ok && w == h {
+		f.writing.Delete(h.reqPath)
+	}
+	if err1 := h.close(); err1 != nil {
+		log.Warnf("[fuse] failed to close %s: %+v", h.reqPath, err1)
+	}
+	return errno(err)
+}
+
+func (f *Fs) Flush(path string, fh uint64) int {
+	return 0
+}
+
+func (f *Fs) Fsync(path string, datasync bool, fh uint64) int {
+	return 0
+}
+
+func (f *Fs) Releasedir(path string, fh uint64) int {
+	return 0
+}
+
+func (f *Fs) Chmod(path string, mode uint32) int {
+	return 0
+}
+
+func (f *Fs) Chown(path string, uid uint32, gid uint32) int {
+	return 0
+}
+
+func (f *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
+	return 0
 }
 
 var _ fuse.FileSystemInterface = (*Fs)(nil)
@@ -0,0 +1,114 @@
+//go:build fuse
+
+package fuse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/winfsp/cgofuse/fuse"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestFs(t *testing.T) {
+	conf.Conf.TempDir = t.TempDir()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: "/fuse",
+		Addition:  fmt.Sprintf(`{"root_folder_path":%q}`, root),
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	user := &model.User{Username: "fuse", BasePath: "/fuse", Permission: 0xff << 3}
+	f := NewFs(user, "/", "")
+
+	var stat fuse.Stat_t
+	if res := f.Getattr("/a.txt", &stat, 0); res != 0 || stat.Size != 5 || stat.Mode&fuse.S_IFREG == 0 {
+		t.Fatalf("Getattr() = %d, %+v", res, stat)
+	}
+	res, fh := f.Open("/a.txt", fuse.O_RDONLY)
+	if res != 0 {
+		t.Fatalf("Open() = %d", res)
+	}
+	buf := make([]byte, 10)
+	if n := f.Read("/a.txt", buf, 1, fh); n != 4 || string(buf[:n]) != "ello" {
+		t.Errorf("Read() = %d, %q", n, buf[:n])
+	}
+	if res = f.Release("/a.txt", fh); res != 0 {
+		t.Errorf("Release() = %d", res)
+	}
+
+	if res = f.Mkdir("/dir", 0755); res != 0 {
+		t.Fatalf("Mkdir() = %d", res)
+	}
+	res, fh = f.Create("/dir/b.txt", fuse.O_WRONLY, 0644)
+	if res != 0 {
+		t.Fatalf("Create() = %d", res)
+	}
+	if n := f.Write("/dir/b.txt", []byte("world"), 0, fh); n != 5 {
+		t.Errorf("Write() = %d", n)
+	}
+	// the file being written can be stat before it's uploaded
+	if res = f.Getattr("/dir/b.txt", &stat, 0); res != 0 || stat.Size != 5 {
+		t.Errorf("Getattr() of the writing file = %d, %+v", res, stat)
+	}
+	if res = f.Release("/dir/b.txt", fh); res != 0 {
+		t.Fatalf("Release() = %d", res)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "dir", "b.txt")); err != nil || string(data) != "world" {
+		t.Errorf("the written file = %q, %v", data, err)
+	}
+
+	if res = f.Rename("/dir/b.txt", "/c.txt"); res != 0 {
+		t.Fatalf("Rename() = %d", res)
+	}
+	var names []string
+	res = f.Readdir("/", func(name string, stat *fuse.Stat_t, ofst int64) bool {
+		names = append(names, name)
+		return true
+	}, 0, 0)
+	slices.Sort(names)
+	if res != 0 || !slices.Equal(names, []string{".", "..", "a.txt", "c.txt", "dir"}) {
+		t.Errorf("Readdir() = %d, %v", res, names)
+	}
+
+	if res = f.Unlink("/a.txt"); res != 0 {
+		t.Errorf("Unlink() = %d", res)
+	}
+	if res = f.Getattr("/a.txt", &stat, 0); res != -fuse.ENOENT {
+		t.Errorf("Getattr() of the removed file = %d", res)
+	}
+	// the paths out of the base path of user are rejected
+	if res = f.Getattr("/../other", &stat, 0); res == 0 {
+		t.Errorf("Getattr() out of the base path should fail")
+	}
+
+	f.ReadOnly = true
+	if res = f.Mkdir("/ro", 0755); res != -fuse.EACCES {
+		t.Errorf("Mkdir() of the read only fs = %d", res)
+	}
+}
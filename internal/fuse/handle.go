@@ -0,0 +1,225 @@
+package fuse
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// blockSize is the granularity of the local read cache
+const blockSize = 4 * 1024 * 1024
+
+type handle struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	reqPath string
+	obj     model.Obj
+
+	// for reading, the remote file is fetched lazily block by block into cache
+	ss     *stream.SeekableStream
+	cache  *os.File
+	cached []bool
+
+	// for writing, the whole content is kept in tmp and uploaded on release
+	tmp   *os.File
+	size  int64
+	dirty bool
+}
+
+func (h *handle) writable() bool {
+	return h.tmp != nil
+}
+
+func newReadHandle(ctx context.Context, reqPath string, obj model.Obj) *handle {
+	return &handle{ctx: ctx, reqPath: reqPath, obj: obj}
+}
+
+func newWriteHandle(ctx context.Context, reqPath string, obj model.Obj, trunc bool) (*handle, error) {
+	tmp, err := os.CreateTemp(conf.Conf.TempDir, "fuse-*")
+	if err != nil {
+		return nil, err
+	}
+	h := &handle{ctx: ctx, reqPath: reqPath, obj: obj, tmp: tmp, dirty: trunc}
+	// the file is opened for writing without O_TRUNC, so the old content should be kept
+	if !trunc && obj != nil && obj.GetSize() > 0 {
+		if err = h.openStream(); err == nil {
+			var r io.Reader
+			r, err = h.ss.RangeRead(http_range.Range{Start: 0, Length: obj.GetSize()})
+			if err == nil {
+				h.size, err = utils.CopyWithBuffer(tmp, r)
+				if c, ok := r.(io.Closer); ok {
+					_ = c.Close()
+				}
+			}
+		}
+		if err != nil {
+			_ = h.close()
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func (h *handle) openStream() error {
+	if h.ss != nil {
+		return nil
+	}
+	link, obj, err := fs.LinkDown(h.ctx, h.reqPath, model.LinkArgs{})
+	if err != nil {
+		return err
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: obj, Ctx: h.ctx}, link)
+	if err != nil {
+		return err
+	}
+	h.ss = ss
+	return nil
+}
+
+// ReadAt read from the local cache, only the missing blocks are fetched from the storage
+func (h *handle) ReadAt(p []byte, off int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.writable() {
+		if off >= h.size {
+			return 0, io.EOF
+		}
+		return h.tmp.ReadAt(p[:min(int64(len(p)), h.size-off)], off)
+	}
+	size := h.obj.GetSize()
+	if off >= size {
+		return 0, io.EOF
+	}
+	p = p[:min(int64(len(p)), size-off)]
+	if err := h.openStream(); err != nil {
+		return 0, err
+	}
+	// files of local storage can be read directly
+	if f := h.ss.GetFile(); f != nil {
+		return f.ReadAt(p, off)
+	}
+	if h.cache == nil {
+		cache, err := os.CreateTemp(conf.Conf.TempDir, "fuse-cache-*")
+		if err != nil {
+			return 0, err
+		}
+		h.cache = cache
+		h.cached = make([]bool, (size+blockSize-1)/blockSize)
+	}
+	first, last := off/blockSize, (off+int64(len(p))-1)/blockSize
+	for first <= last && h.cached[first] {
+		first++
+	}
+	for last >= first && h.cached[last] {
+		last--
+	}
+	if first <= last {
+		if err := h.fetch(first, last); err != nil {
+			return 0, err
+		}
+	}
+	return h.cache.ReadAt(p, off)
+}
+
+// fetch the blocks in [first, last] with a single range request
+func (h *handle) fetch(first, last int64) error {
+	start := first * blockSize
+	length := min((last+1)*blockSize, h.obj.GetSize()) - start
+	r, err := h.ss.RangeRead(http_range.Range{Start: start, Length: length})
+	if err != nil {
+		return err
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer c.Close()
+	}
+	n, err := utils.CopyWithBuffer(io.NewOffsetWriter(h.cache, start), io.LimitReader(r, length))
+	if err != nil {
+		return err
+	}
+	if n != length {
+		return io.ErrUnexpectedEOF
+	}
+	for i := first; i <= last; i++ {
+		h.cached[i] = true
+	}
+	return nil
+}
+
+func (h *handle) WriteAt(p []byte, off int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n, err := h.tmp.WriteAt(p, off)
+	h.size = max(h.size, off+int64(n))
+	h.dirty = true
+	return n, err
+}
+
+func (h *handle) Truncate(size int64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.tmp.Truncate(size); err != nil {
+		return err
+	}
+	h.size = size
+	h.dirty = true
+	return nil
+}
+
+func (h *handle) Size() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.size
+}
+
+// commit upload the content to the storage if it has been changed
+func (h *handle) commit() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.writable() || !h.dirty {
+		return nil
+	}
+	if _, err := h.tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	dir, name := stdpath.Split(h.reqPath)
+	s := &stream.FileStream{
+		Ctx: h.ctx,
+		Obj: &model.Object{
+			Name:     name,
+			Size:     h.size,
+			Modified: time.Now(),
+		},
+		Mimetype: utils.GetMimeType(name),
+	}
+	// the tmp file will be removed by the stream after putting
+	s.SetTmpFile(h.tmp)
+	h.tmp = nil
+	h.dirty = false
+	return fs.PutDirectly(h.ctx, dir, s)
+}
+
+func (h *handle) close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var err error
+	if h.ss != nil {
+		err = h.ss.Close()
+	}
+	for _, f := range []*os.File{h.cache, h.tmp} {
+		if f != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}
+	return err
+}
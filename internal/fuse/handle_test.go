@@ -0,0 +1,74 @@
+package fuse
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+)
+
+func TestHandleReadAt(t *testing.T) {
+	if conf.Conf == nil {
+		conf.Conf = conf.DefaultConfig()
+	}
+	conf.Conf.TempDir = t.TempDir()
+	data := make([]byte, 2*blockSize+10)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	var ranges []http_range.Range
+	link := &model.Link{RangeReadCloser: &model.RangeReadCloser{
+		RangeReader: func(ctx context.Context, httpRange http_range.Range) (io.ReadCloser, error) {
+			ranges = append(ranges, httpRange)
+			return io.NopCloser(bytes.NewReader(data[httpRange.Start : httpRange.Start+httpRange.Length])), nil
+		},
+	}}
+	obj := &model.Object{Name: "a.bin", Size: int64(len(data))}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: obj, Ctx: context.Background()}, link)
+	if err != nil {
+		t.Fatalf("failed to create stream: %+v", err)
+	}
+	h := newReadHandle(context.Background(), "/a.bin", obj)
+	h.ss = ss
+	defer h.close()
+
+	read := func(off int64, n int) {
+		t.Helper()
+		p := make([]byte, n)
+		got, err := h.ReadAt(p, off)
+		if err != nil {
+			t.Fatalf("ReadAt(%d) failed: %+v", off, err)
+		}
+		if !bytes.Equal(p[:got], data[off:off+int64(got)]) || got != min(n, len(data)-int(off)) {
+			t.Errorf("ReadAt(%d) got wrong data of %d bytes", off, got)
+		}
+	}
+	expect := func(want ...http_range.Range) {
+		t.Helper()
+		if len(ranges) != len(want) {
+			t.Fatalf("expected %d range requests, got %+v", len(want), ranges)
+		}
+		for i := range want {
+			if ranges[i] != want[i] {
+				t.Errorf("expected range request %+v, got %+v", want[i], ranges[i])
+			}
+		}
+	}
+
+	read(0, 10)
+	expect(http_range.Range{Start: 0, Length: blockSize})
+	// the cached block is not fetched again
+	read(5, 10)
+	expect(http_range.Range{Start: 0, Length: blockSize})
+	// only the missing blocks are fetched with a single request
+	read(blockSize-5, blockSize+100)
+	expect(http_range.Range{Start: 0, Length: blockSize}, http_range.Range{Start: blockSize, Length: blockSize + 10})
+	if n, err := h.ReadAt(make([]byte, 10), int64(len(data))); n != 0 || err != io.EOF {
+		t.Errorf("ReadAt() at the end = %d, %v", n, err)
+	}
+}
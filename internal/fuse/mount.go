@@ -1,9 +1,33 @@
+//go:build fuse
+
 package fuse
 
-import "github.com/winfsp/cgofuse/fuse"
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+type MountArgs struct {
+	// RootFolder is the folder to be mounted, relative to the base path of user
+	RootFolder string
+	// MetaPass is used to access the folders protected by meta password
+	MetaPass string
+	ReadOnly bool
+	// Options are passed to fuse directly, e.g. allow_other
+	Options []string
+}
 
-func Mount(mountSrc, mountDst string, opts []string) {
-	fs := &Fs{RootFolder: mountSrc}
+// Mount the storages visible to user at mountPoint, it blocks until unmounted
+func Mount(user *model.User, mountPoint string, args MountArgs) bool {
+	fs := NewFs(user, args.RootFolder, args.MetaPass)
+	fs.ReadOnly = args.ReadOnly
+	opts := []string{"-o", "fsname=alist", "-o", "subtype=alist"}
+	if args.ReadOnly {
+		opts = append(opts, "-o", "ro")
+	}
+	for _, opt := range args.Options {
+		opts = append(opts, "-o", opt)
+	}
 	host := fuse.NewFileSystemHost(fs)
-	go host.Mount(mountDst, opts)
+	return host.Mount(mountPoint, opts)
 }
@@ -0,0 +1,94 @@
+//go:build fuse
+
+package fuse
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// reqPath convert the path given by fuse to the path in alist, the base path of user is applied
+func (f *Fs) reqPath(path string) (string, error) {
+	return f.user.JoinPath(stdpath.Join(f.RootFolder, path))
+}
+
+// readCtx check if the user can read the reqPath and return the context with meta
+func (f *Fs) readCtx(reqPath string) (context.Context, error) {
+	meta, err := op.GetNearestMetaForUser(f.user, reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return nil, err
+	}
+	if !common.CanAccess(f.user, meta, reqPath, f.metaPass) {
+		return nil, errs.PermissionDenied
+	}
+	return context.WithValue(f.ctx, "meta", meta), nil
+}
+
+// canWrite check if the user can create or overwrite objects in the parent of reqPath
+func (f *Fs) canWrite(reqPath string) error {
+	if f.ReadOnly {
+		return errs.PermissionDenied
+	}
+	if f.user.At(reqPath).CanWrite() {
+		return nil
+	}
+	meta, err := op.GetNearestMetaForUser(f.user, stdpath.Dir(reqPath))
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return err
+	}
+	if !common.CanWrite(meta, stdpath.Dir(reqPath)) {
+		return errs.PermissionDenied
+	}
+	return nil
+}
+
+func (f *Fs) fillStat(obj model.Obj, stat *fuse.Stat_t) {
+	*stat = fuse.Stat_t{}
+	var perm uint32 = 0755
+	if f.ReadOnly {
+		perm = 0555
+	}
+	if obj.IsDir() {
+		stat.Mode = fuse.S_IFDIR | perm
+		stat.Nlink = 2
+	} else {
+		stat.Mode = fuse.S_IFREG | (perm &^ 0111)
+		stat.Nlink = 1
+		stat.Size = obj.GetSize()
+		stat.Blksize = blockSize
+		stat.Blocks = (obj.GetSize() + 511) / 512
+	}
+	stat.Uid = f.uid
+	stat.Gid = f.gid
+	mtime := fuse.NewTimespec(obj.ModTime())
+	stat.Mtim = mtime
+	stat.Atim = mtime
+	stat.Ctim = mtime
+	if !obj.CreateTime().IsZero() {
+		stat.Birthtim = fuse.NewTimespec(obj.CreateTime())
+	} else {
+		stat.Birthtim = mtime
+	}
+}
+
+var errnos = map[errKind]int{
+	errNotFound:     -fuse.ENOENT,
+	errAccess:       -fuse.EACCES,
+	errNotDir:       -fuse.ENOTDIR,
+	errIsDir:        -fuse.EISDIR,
+	errReadOnly:     -fuse.EROFS,
+	errNotSupported: -fuse.ENOSYS,
+	errIO:           -fuse.EIO,
+}
+
+// errno convert the error of alist to the negative errno which fuse expects
+func errno(err error) int {
+	return errnos[errKindOf(err)]
+}
@@ -0,0 +1,148 @@
+package listcache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+var bucketName = []byte("list_cache")
+
+// bolt persist the listings to a local file, the value is the expiration
+// (unix nano, 8 bytes) followed by the encoded objs
+type boltCache struct {
+	db *bolt.DB
+}
+
+func NewBolt(file string) (ListCache, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// the file is locked by another process (e.g. the server and mount command are running together)
+	db, err := bolt.Open(file, 0600, &bolt.Options{Timeout: 3 * time.Second})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open list cache file %s", file)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.WithStack(err)
+	}
+	return &boltCache{db: db}, nil
+}
+
+func (b *boltCache) Get(key string) ([]model.Obj, bool) {
+	var data []byte
+	expired := false
+	_ = b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketName).Get([]byte(key))
+		if len(v) < 8 {
+			return nil
+		}
+		if time.Now().UnixNano() >= int64(binary.BigEndian.Uint64(v[:8])) {
+			expired = true
+			return nil
+		}
+		// the value is only valid during the transaction
+		data = append([]byte(nil), v[8:]...)
+		return nil
+	})
+	if expired {
+		b.Del(key)
+	}
+	if data == nil {
+		return nil, false
+	}
+	objs, err := decodeObjs(data)
+	if err != nil {
+		log.Debugf("failed to decode list cache of %s: %+v", key, err)
+		return nil, false
+	}
+	return objs, true
+}
+
+func (b *boltCache) Set(key string, objs []model.Obj, ex time.Duration) {
+	if ex <= 0 {
+		b.Del(key)
+		return
+	}
+	data, err := encodeObjs(objs)
+	if err != nil {
+		if errors.Is(err, errUnregisteredType) {
+			log.Debugf("skip list cache of %s: %v", key, err)
+		} else {
+			log.Warnf("failed to encode list cache of %s: %+v", key, err)
+		}
+		b.Del(key)
+		return
+	}
+	v := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint64(v, uint64(time.Now().Add(ex).UnixNano()))
+	v = append(v, data...)
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Put([]byte(key), v)
+	})
+	if err != nil {
+		log.Warnf("failed to set list cache of %s: %+v", key, err)
+	}
+}
+
+func (b *boltCache) Del(key string) {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Delete([]byte(key))
+	})
+	if err != nil {
+		log.Warnf("failed to del list cache of %s: %+v", key, err)
+	}
+}
+
+func (b *boltCache) DelPrefix(key string) {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		c := bucket.Cursor()
+		prefix := []byte(key)
+		// deleting with the cursor while iterating may skip keys, so collect them first
+		var keys [][]byte
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			if hasPathPrefix(string(k), key) {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+		}
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warnf("failed to del list cache under %s: %+v", key, err)
+	}
+}
+
+func (b *boltCache) Clear() {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bucketName); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		_, err := tx.CreateBucket(bucketName)
+		return err
+	})
+	if err != nil {
+		log.Warnf("failed to clear list cache: %+v", err)
+	}
+}
+
+func (b *boltCache) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,74 @@
+package listcache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type driverObj struct {
+	model.Object
+	FileID string
+}
+
+type unregisteredObj struct {
+	model.Object
+}
+
+func init() {
+	RegisterObjType(&driverObj{})
+}
+
+func TestBolt(t *testing.T) {
+	c, err := NewBolt(filepath.Join(t.TempDir(), "list_cache.db"))
+	if err != nil {
+		t.Fatalf("failed to open bolt: %+v", err)
+	}
+	defer c.Close()
+	objs := []model.Obj{
+		&model.Object{Name: "dir", IsFolder: true},
+		&driverObj{Object: model.Object{Name: "a.txt", Size: 10, HashInfo: utils.NewHashInfo(utils.MD5, "0cc175b9c0f1b6a831c399e269772661")}, FileID: "1"},
+	}
+	model.WrapObjsName(objs)
+	c.Set("/a", objs, time.Minute)
+	got, ok := c.Get("/a")
+	if !ok || len(got) != 2 {
+		t.Fatalf("expect 2 objs, got %+v", got)
+	}
+	if _, ok := got[0].(*model.ObjWrapName); !ok {
+		t.Errorf("expect the name wrapper to be kept, got %T", got[0])
+	}
+	obj, ok := model.UnwrapObj(got[1]).(*driverObj)
+	if !ok || obj.FileID != "1" || obj.GetName() != "a.txt" || obj.GetSize() != 10 {
+		t.Errorf("expect the driver obj to be decoded, got %#v", got[1])
+	}
+	if hash := got[1].GetHash(); hash.GetHash(utils.MD5) != "0cc175b9c0f1b6a831c399e269772661" {
+		t.Errorf("expect the md5 to be kept, got %s", hash)
+	}
+	c.Set("/c", []model.Obj{&unregisteredObj{}}, time.Minute)
+	if _, ok := c.Get("/c"); ok {
+		t.Errorf("expect the objs of an unregistered type not to be stored")
+	}
+	c.Set("/b", objs, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	if _, ok := c.Get("/b"); ok {
+		t.Errorf("expect /b to be expired")
+	}
+	c.Set("/a/b", objs, time.Minute)
+	c.Set("/ab", objs, time.Minute)
+	c.DelPrefix("/a")
+	if _, ok := c.Get("/a/b"); ok {
+		t.Errorf("expect /a/b to be deleted with /a")
+	}
+	if _, ok := c.Get("/ab"); !ok {
+		t.Errorf("expect /ab to be kept")
+	}
+	c.Set("/a", objs, time.Minute)
+	c.Clear()
+	if _, ok := c.Get("/a"); ok {
+		t.Errorf("expect /a to be cleared")
+	}
+}
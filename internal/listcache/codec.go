@@ -0,0 +1,137 @@
+package listcache
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// the objs returned by drivers are of their own types, so the type of each obj
+// is recorded when it is encoded and used to decode it back to the same type.
+// only the exported fields and the hash are kept, so a driver should only register
+// the types that can be rebuilt from them. the objs of the unregistered types are
+// not persisted.
+var objTypes sync.Map // name -> reflect.Type
+
+var hashInfoType = reflect.TypeOf(utils.HashInfo{})
+
+var errUnregisteredType = errors.New("unregistered obj type")
+
+func init() {
+	for _, obj := range []model.Obj{
+		&model.Object{},
+		&model.ObjThumb{},
+		&model.ObjectURL{},
+		&model.ObjThumbURL{},
+	} {
+		RegisterObjType(obj)
+	}
+}
+
+// RegisterObjType register the type of obj so that the objs of this type can be
+// stored in and decoded from persistent backends, it should be called in init
+func RegisterObjType(obj model.Obj) {
+	t := reflect.TypeOf(obj)
+	objTypes.LoadOrStore(typeName(t), t)
+}
+
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		return "*" + typeName(t.Elem())
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+type entry struct {
+	// Type is the name of the type of the obj
+	Type string `json:"t"`
+	// Names are the names wrapped by model.ObjWrapName, from outer to inner
+	Names []string `json:"n,omitempty"`
+	Obj   []byte   `json:"o"`
+	// Hash is the utils.HashInfo of the obj, whose map is unexported
+	Hash string `json:"h,omitempty"`
+}
+
+func encodeObjs(objs []model.Obj) ([]byte, error) {
+	entries := make([]entry, 0, len(objs))
+	for _, obj := range objs {
+		var e entry
+		for {
+			w, ok := obj.(*model.ObjWrapName)
+			if !ok {
+				break
+			}
+			e.Names = append(e.Names, w.Name)
+			obj = w.Obj
+		}
+		if _, ok := obj.(model.ObjUnwrap); ok {
+			return nil, errors.Errorf("unsupported wrapped obj: %T", obj)
+		}
+		e.Type = typeName(reflect.TypeOf(obj))
+		if _, ok := objTypes.Load(e.Type); !ok {
+			return nil, errors.WithMessage(errUnregisteredType, e.Type)
+		}
+		data, err := utils.Json.Marshal(obj)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		e.Obj = data
+		if hash := obj.GetHash(); len(hash.Export()) > 0 {
+			e.Hash = hash.String()
+		}
+		entries = append(entries, e)
+	}
+	data, err := utils.Json.Marshal(entries)
+	return data, errors.WithStack(err)
+}
+
+func decodeObjs(data []byte) ([]model.Obj, error) {
+	var entries []entry
+	if err := utils.Json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	objs := make([]model.Obj, 0, len(entries))
+	for _, e := range entries {
+		t, ok := objTypes.Load(e.Type)
+		if !ok {
+			return nil, errors.Errorf("unknown obj type: %s", e.Type)
+		}
+		obj, err := decodeObj(t.(reflect.Type), e)
+		if err != nil {
+			return nil, err
+		}
+		for i := len(e.Names) - 1; i >= 0; i-- {
+			obj = &model.ObjWrapName{Name: e.Names[i], Obj: obj}
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+func decodeObj(t reflect.Type, e entry) (model.Obj, error) {
+	ptr := t.Kind() == reflect.Pointer
+	if ptr {
+		t = t.Elem()
+	}
+	v := reflect.New(t)
+	if err := utils.Json.Unmarshal(e.Obj, v.Interface()); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if e.Hash != "" {
+		// the types whose GetHash is computed from their own fields have no HashInfo field
+		if f := v.Elem().FieldByName("HashInfo"); f.IsValid() && f.CanSet() && f.Type() == hashInfoType {
+			f.Set(reflect.ValueOf(utils.FromString(e.Hash)))
+		}
+	}
+	if !ptr {
+		v = v.Elem()
+	}
+	obj, ok := v.Interface().(model.Obj)
+	if !ok {
+		return nil, errors.Errorf("invalid obj type: %s", e.Type)
+	}
+	return obj, nil
+}
@@ -0,0 +1,74 @@
+package listcache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+)
+
+// ListCache caches the objs listed from storages, the key is the mount path of the dir
+type ListCache interface {
+	Get(key string) ([]model.Obj, bool)
+	// Set the objs of key, if ex <= 0 the key will not be cached
+	Set(key string, objs []model.Obj, ex time.Duration)
+	Del(key string)
+	// DelPrefix delete the key and all the keys under it, e.g. /a and /a/b but not /ab
+	DelPrefix(key string)
+	Clear()
+	Close() error
+}
+
+type memory struct {
+	c cache.ICache[[]model.Obj]
+	// go-cache can't iterate the keys, so they are recorded for DelPrefix
+	keys generic_sync.MapOf[string, struct{}]
+}
+
+// NewMemory create a ListCache in memory, all the listings will be lost after restart
+func NewMemory() ListCache {
+	return &memory{c: cache.NewMemCache(cache.WithShards[[]model.Obj](64))}
+}
+
+func (m *memory) Get(key string) ([]model.Obj, bool) {
+	return m.c.Get(key)
+}
+
+func (m *memory) Set(key string, objs []model.Obj, ex time.Duration) {
+	m.c.Set(key, objs, cache.WithEx[[]model.Obj](ex))
+	m.keys.Store(key, struct{}{})
+}
+
+func (m *memory) Del(key string) {
+	m.c.Del(key)
+	m.keys.Delete(key)
+}
+
+func (m *memory) DelPrefix(key string) {
+	m.keys.Range(func(k string, _ struct{}) bool {
+		// the expired keys are also removed here
+		if hasPathPrefix(k, key) || !m.c.Exists(k) {
+			m.Del(k)
+		}
+		return true
+	})
+}
+
+func (m *memory) Clear() {
+	m.c.Clear()
+	m.keys.Clear()
+}
+
+func (m *memory) Close() error {
+	m.Clear()
+	return nil
+}
+
+func hasPathPrefix(key, prefix string) bool {
+	if !strings.HasPrefix(key, prefix) {
+		return false
+	}
+	return len(key) == len(prefix) || strings.HasSuffix(prefix, "/") || key[len(prefix)] == '/'
+}
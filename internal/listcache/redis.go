@@ -0,0 +1,111 @@
+package listcache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+// redisCache store the listings in a server speaking the redis protocol,
+// so that it can be shared by several instances
+type redisCache struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedis(options *redis.Options, prefix string) (ListCache, error) {
+	client := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, errors.Wrapf(err, "failed to connect redis %s", options.Addr)
+	}
+	return &redisCache{client: client, prefix: prefix}, nil
+}
+
+func (r *redisCache) key(key string) string {
+	return r.prefix + key
+}
+
+func (r *redisCache) Get(key string) ([]model.Obj, bool) {
+	data, err := r.client.Get(context.Background(), r.key(key)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Warnf("failed to get list cache of %s: %+v", key, err)
+		}
+		return nil, false
+	}
+	objs, err := decodeObjs(data)
+	if err != nil {
+		log.Debugf("failed to decode list cache of %s: %+v", key, err)
+		return nil, false
+	}
+	return objs, true
+}
+
+func (r *redisCache) Set(key string, objs []model.Obj, ex time.Duration) {
+	if ex <= 0 {
+		r.Del(key)
+		return
+	}
+	data, err := encodeObjs(objs)
+	if err != nil {
+		if errors.Is(err, errUnregisteredType) {
+			log.Debugf("skip list cache of %s: %v", key, err)
+		} else {
+			log.Warnf("failed to encode list cache of %s: %+v", key, err)
+		}
+		r.Del(key)
+		return
+	}
+	if err = r.client.Set(context.Background(), r.key(key), data, ex).Err(); err != nil {
+		log.Warnf("failed to set list cache of %s: %+v", key, err)
+	}
+}
+
+func (r *redisCache) Del(key string) {
+	if err := r.client.Del(context.Background(), r.key(key)).Err(); err != nil {
+		log.Warnf("failed to del list cache of %s: %+v", key, err)
+	}
+}
+
+func (r *redisCache) DelPrefix(key string) {
+	r.Del(key)
+	r.delMatch(globEscaper.Replace(r.key(strings.TrimSuffix(key, "/"))) + "/*")
+}
+
+// Clear only delete the keys with the prefix, other data in the same db is kept
+func (r *redisCache) Clear() {
+	r.delMatch(globEscaper.Replace(r.prefix) + "*")
+}
+
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+func (r *redisCache) delMatch(pattern string) {
+	ctx := context.Background()
+	iter := r.client.Scan(ctx, 0, pattern, 1000).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) >= 1000 {
+			r.client.Del(ctx, keys...)
+			keys = keys[:0]
+		}
+	}
+	if len(keys) > 0 {
+		r.client.Del(ctx, keys...)
+	}
+	if err := iter.Err(); err != nil {
+		log.Warnf("failed to del list cache matching %s: %+v", pattern, err)
+	}
+}
+
+func (r *redisCache) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,67 @@
+// Package metrics holds the prometheus metrics collected all over alist
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "alist"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "The number of http requests by route and protocol.",
+	}, []string{"protocol", "method", "route", "status"})
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "The latency of http requests by route and protocol.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"protocol", "method", "route"})
+
+	OpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "op_duration_seconds",
+		Help:      "The latency of list and link operations by driver and storage.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"op", "driver", "storage"})
+	OpErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "op_errors_total",
+		Help:      "The number of failed list and link operations by driver and storage.",
+	}, []string{"op", "driver", "storage"})
+
+	ListCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "list_cache_hits_total",
+		Help:      "The number of lists served from the list cache.",
+	})
+	ListCacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "list_cache_misses_total",
+		Help:      "The number of lists not found in the list cache.",
+	})
+
+	ProxiedBytes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "proxied_bytes_total",
+		Help:      "The bytes sent to the clients by proxy.",
+	})
+	RateLimitedBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_bytes_total",
+		Help:      "The bytes passed through the rate limiters.",
+	}, []string{"limiter"})
+)
+
+// ObserveOp record the latency of the op started at start, and the error if any
+func ObserveOp(op, driver, storage string, start time.Time, err error) {
+	OpDuration.WithLabelValues(op, driver, storage).Observe(time.Since(start).Seconds())
+	if err != nil {
+		OpErrors.WithLabelValues(op, driver, storage).Inc()
+	}
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// ACL grants the permission bits under the path to a user or a group.
+// The users with any ACL can only access the paths granted to them and the parents of them
+type ACL struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	UserID  uint   `json:"user_id" gorm:"index"`
+	GroupID uint   `json:"group_id" gorm:"index"`
+	Path    string `json:"path" binding:"required"`
+	// Permission has the same bits as User.Permission, it replaces the permission
+	// of the user under the path
+	Permission int32 `json:"permission"`
+}
+
+func (a *ACL) Validate() error {
+	if (a.UserID == 0) == (a.GroupID == 0) {
+		return errors.New("either user_id or group_id is required")
+	}
+	return nil
+}
+
+// nearestACLPermission returns the union of the permission of the nearest ACLs of the path
+func nearestACLPermission(acls []ACL, path string) (int32, bool) {
+	var permission int32
+	nearest := ""
+	for _, a := range acls {
+		if !utils.IsSubPath(a.Path, path) {
+			continue
+		}
+		aclPath := utils.FixAndCleanPath(a.Path)
+		if len(aclPath) > len(nearest) {
+			nearest, permission = aclPath, a.Permission
+		} else if aclPath == nearest {
+			permission |= a.Permission
+		}
+	}
+	return permission, nearest != ""
+}
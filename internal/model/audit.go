@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+const (
+	AuditRemove   = "remove"
+	AuditMove     = "move"
+	AuditRename   = "rename"
+	AuditCopy     = "copy"
+	AuditUpload   = "upload"
+	AuditDownload = "download"
+)
+
+const (
+	ProtocolWeb    = "web"
+	ProtocolWebDAV = "webdav"
+	ProtocolFTP    = "ftp"
+	ProtocolSFTP   = "sftp"
+	ProtocolS3     = "s3"
+	ProtocolFuse   = "fuse"
+)
+
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Time      time.Time `json:"time" gorm:"index"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username" gorm:"index"`
+	IP        string    `json:"ip"`
+	Protocol  string    `json:"protocol"`
+	Operation string    `json:"operation" gorm:"index"`
+	SrcPath   string    `json:"src_path"`
+	DstPath   string    `json:"dst_path"`
+	Size      int64     `json:"size"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error" gorm:"type:text"`
+}
+
+type AuditLogFilter struct {
+	Username  string `json:"username" form:"username"`
+	Protocol  string `json:"protocol" form:"protocol"`
+	Operation string `json:"operation" form:"operation"`
+	// Path matches the logs whose src or dst path is under it
+	Path  string     `json:"path" form:"path"`
+	Start *time.Time `json:"start" form:"start"`
+	End   *time.Time `json:"end" form:"end"`
+	// Success is "true" or "false" to filter by the result, empty means all
+	Success string `json:"success" form:"success"`
+}
@@ -0,0 +1,40 @@
+package model
+
+const (
+	BalanceRoundRobin  = "round_robin"
+	BalanceWeighted    = "weighted"
+	BalanceLeastActive = "least_active"
+	BalanceLatency     = "latency"
+	BalanceFailover    = "failover"
+)
+
+// BalanceGroup decides how the requests to MountPath are balanced between its members,
+// the storages mounted at MountPath with the .balance suffix are the members as well
+type BalanceGroup struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	MountPath string `json:"mount_path" gorm:"unique" binding:"required"`
+	// Strategy is one of round_robin, weighted, least_active, latency and failover
+	Strategy string          `json:"strategy"`
+	Members  []BalanceMember `json:"members" gorm:"type:text;serializer:json"`
+	// StickyTTL is the seconds that a client keeps using the same member, 0 means disabled
+	StickyTTL int    `json:"sticky_ttl"`
+	Remark    string `json:"remark"`
+}
+
+type BalanceMember struct {
+	StorageID uint `json:"storage_id"`
+	// Weight is only used by the weighted strategy, it's regarded as 1 if not positive
+	Weight int `json:"weight"`
+}
+
+// BalanceStats is the runtime stats of a member used to balance the requests
+type BalanceStats struct {
+	StorageID uint   `json:"storage_id"`
+	MountPath string `json:"mount_path"`
+	// Active is the number of the ops and the proxied downloads in progress
+	Active int64 `json:"active"`
+	// Latency is the moving average of the latency of the ops in milliseconds
+	Latency  float64 `json:"latency"`
+	Failures int     `json:"failures"`
+	Skipped  bool    `json:"skipped"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// FileRequest is an anonymous upload-only endpoint bound to a folder
+type FileRequest struct {
+	Token string `json:"token" gorm:"primaryKey;size:32"`
+	// Path is the full path of the folder, i.e. with the base path of the creator
+	Path      string `json:"path" binding:"required"`
+	CreatorID uint   `json:"creator_id" gorm:"index"`
+	// MaxSize is the max size of each file in bytes, 0 means unlimited
+	MaxSize int64 `json:"max_size"`
+	// AllowedExts is the allowed extensions separated by comma, empty means all
+	AllowedExts string     `json:"allowed_exts"`
+	Expires     *time.Time `json:"expires"`
+	Disabled    bool       `json:"disabled"`
+	UploadCount int        `json:"upload_count"`
+	Created     time.Time  `json:"created"`
+}
+
+func (r *FileRequest) Validate() error {
+	if r.Disabled {
+		return errors.New("file request is disabled")
+	}
+	if r.Expires != nil && time.Now().After(*r.Expires) {
+		return errors.New("file request is expired")
+	}
+	return nil
+}
+
+func (r *FileRequest) AllowExt(name string) bool {
+	if strings.TrimSpace(r.AllowedExts) == "" {
+		return true
+	}
+	ext := strings.ToLower(utils.Ext(name))
+	for _, e := range strings.Split(r.AllowedExts, ",") {
+		if strings.TrimPrefix(strings.ToLower(strings.TrimSpace(e)), ".") == ext {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// Group gives its permission bits, base path and meta overrides to all its members
+type Group struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"unique" binding:"required"`
+	// BasePath is used by the members whose own base path is the root
+	BasePath string `json:"base_path"`
+	// Permission has the same bits as User.Permission
+	Permission int32  `json:"permission"`
+	Disabled   bool   `json:"disabled"`
+	Remark     string `json:"remark"`
+	// Quota limits the total usage of all the members
+	Quota
+}
+
+type UserGroup struct {
+	UserID  uint `gorm:"primaryKey;autoIncrement:false"`
+	GroupID uint `gorm:"primaryKey;autoIncrement:false;index"`
+}
+
+// GroupMeta overrides the access rules of the nearest Meta for the members of the group
+type GroupMeta struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	GroupID  uint   `json:"group_id" gorm:"index" binding:"required"`
+	Path     string `json:"path" binding:"required"`
+	Password string `json:"password"`
+	PSub     bool   `json:"p_sub"`
+	Write    bool   `json:"write"`
+	WSub     bool   `json:"w_sub"`
+	Hide     string `json:"hide"`
+	HSub     bool   `json:"h_sub"`
+}
+
+// Override returns a copy of the meta with the access rules replaced by the group meta,
+// the readme and header of the meta are kept if they apply to the path of the group meta
+func (m *GroupMeta) Override(meta *Meta) *Meta {
+	res := &Meta{}
+	if meta != nil {
+		*res = *meta
+		if !utils.PathEqual(meta.Path, m.Path) {
+			if !meta.RSub {
+				res.Readme = ""
+			}
+			if !meta.HeaderSub {
+				res.Header = ""
+			}
+		}
+	}
+	res.Path = m.Path
+	res.Password, res.PSub = m.Password, m.PSub
+	res.Write, res.WSub = m.Write, m.WSub
+	res.Hide, res.HSub = m.Hide, m.HSub
+	return res
+}
+
+// mergeGroupMetas merge the group metas of the same path from several groups,
+// the members get the union of the access, so the most permissive rules win
+func mergeGroupMetas(metas []*GroupMeta) *GroupMeta {
+	res := *metas[0]
+	for _, m := range metas[1:] {
+		if m.Password == "" {
+			res.Password = ""
+		}
+		if m.Write {
+			res.Write, res.WSub = true, res.WSub || m.WSub
+		}
+		if m.Hide == "" {
+			res.Hide = ""
+		}
+	}
+	return &res
+}
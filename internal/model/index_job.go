@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+const (
+	IndexJobRunning   = "running"
+	IndexJobSucceeded = "succeeded"
+	IndexJobFailed    = "failed"
+	IndexJobCanceled  = "canceled"
+)
+
+// IndexJob is the incremental search index job of a storage
+type IndexJob struct {
+	StorageID uint   `json:"storage_id" gorm:"primaryKey;autoIncrement:false"`
+	MountPath string `json:"mount_path"`
+	// Status is one of running, succeeded, failed and canceled
+	Status string `json:"status"`
+	// Cursor is the last folder whose children have been indexed, the folders are crawled
+	// in depth-first order by name, so the job is resumed after it
+	Cursor   string     `json:"cursor" gorm:"type:text"`
+	Folders  uint64     `json:"folders"`
+	Added    uint64     `json:"added"`
+	Removed  uint64     `json:"removed"`
+	Error    string     `json:"error" gorm:"type:text"`
+	Started  *time.Time `json:"started"`
+	Finished *time.Time `json:"finished"`
+}
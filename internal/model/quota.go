@@ -0,0 +1,37 @@
+package model
+
+// Quota limits the bytes and the number of files a user can upload, 0 means unlimited
+type Quota struct {
+	QuotaBytes int64 `json:"quota_bytes"`
+	QuotaFiles int64 `json:"quota_files"`
+}
+
+// Exceeded check whether the usage will exceed the quota after adding the bytes and files
+func (q Quota) Exceeded(usage *UserUsage, bytes, files int64) bool {
+	if q.QuotaBytes > 0 && usage.UsedBytes+bytes > q.QuotaBytes {
+		return true
+	}
+	if q.QuotaFiles > 0 && usage.UsedFiles+files > q.QuotaFiles {
+		return true
+	}
+	return false
+}
+
+// UserUsage is the bytes and the number of files uploaded by the user, which are still stored,
+// it's kept along with the UploadedFile records so that the quotas are checked without summing them up
+type UserUsage struct {
+	UserID    uint  `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	UsedBytes int64 `json:"used_bytes"`
+	UsedFiles int64 `json:"used_files"`
+}
+
+// UploadedFile is a file uploaded by the user, the usage is counted by them, so that
+// it's released from the uploader no matter who removes or overwrites the file
+type UploadedFile struct {
+	ID        uint `json:"id" gorm:"primaryKey"`
+	StorageID uint `json:"storage_id" gorm:"uniqueIndex:idx_uploaded_file_path"`
+	// Path is the actual path in the storage, it follows the file when moved, e.g. into the trash
+	Path   string `json:"path" gorm:"uniqueIndex:idx_uploaded_file_path"`
+	UserID uint   `json:"user_id" gorm:"index"`
+	Size   int64  `json:"size"`
+}
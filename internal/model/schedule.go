@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+const (
+	ScheduleCopy            = "copy"
+	ScheduleSync            = "sync"
+	ScheduleDecompress      = "decompress"
+	ScheduleBuildIndex      = "build_index"
+	ScheduleUpdateIndex     = "update_index"
+	ScheduleOfflineDownload = "offline_download"
+	ScheduleClearDoneTasks  = "clear_done_tasks"
+)
+
+const (
+	ScheduleRunning   = "running"
+	ScheduleSucceeded = "succeeded"
+	ScheduleFailed    = "failed"
+)
+
+type ScheduledJob struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"unique" binding:"required"`
+	// Cron is a standard cron expression, an optional seconds field and descriptors like @every 1h are supported
+	Cron string `json:"cron" binding:"required"`
+	Type string `json:"type" binding:"required"`
+	// Args is the json of the arguments of the type
+	Args     string `json:"args" gorm:"type:text"`
+	Disabled bool   `json:"disabled"`
+
+	LastRunTime *time.Time `json:"last_run_time"`
+	LastStatus  string     `json:"last_status"`
+	LastMessage string     `json:"last_message" gorm:"type:text"`
+	NextRunTime *time.Time `json:"next_run_time" gorm:"-"`
+}
+
+type ScheduledJobRun struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	JobID     uint       `json:"job_id" gorm:"index"`
+	StartTime time.Time  `json:"start_time"`
+	EndTime   *time.Time `json:"end_time"`
+	Status    string     `json:"status"`
+	Message   string     `json:"message" gorm:"type:text"`
+}
@@ -2,6 +2,8 @@ package model
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -12,19 +14,74 @@ type IndexProgress struct {
 	Error        string     `json:"error"`
 }
 
+// the modes of matching the keywords in SearchReq
+const (
+	// SearchPhrase matches the keywords as a whole in the name or content
+	SearchPhrase = "phrase"
+	// SearchWildcard matches the whole name by the keywords with * and ?
+	SearchWildcard = "wildcard"
+	// SearchRegex matches the name by the keywords as a regular expression
+	SearchRegex = "regex"
+)
+
 type SearchReq struct {
 	Parent   string `json:"parent"`
 	Keywords string `json:"keywords"`
 	// 0 for all, 1 for dir, 2 for file
 	Scope int `json:"scope"`
+	// Mode is empty for the default matching of the searcher, or one of phrase, wildcard and regex
+	Mode string `json:"mode"`
+	// MinSize and MaxSize are in bytes, 0 means no limit
+	MinSize        int64      `json:"min_size"`
+	MaxSize        int64      `json:"max_size"`
+	ModifiedAfter  *time.Time `json:"modified_after"`
+	ModifiedBefore *time.Time `json:"modified_before"`
+	// Exts are the extensions of the files without dot, such as mp4
+	Exts []string `json:"exts"`
+	// Types are the types of the objs by the type settings, such as conf.VIDEO and conf.IMAGE
+	Types []int `json:"types"`
+	// OrderBy is one of name, size and modified, OrderDirection is asc or desc
+	OrderBy        string `json:"order_by"`
+	OrderDirection string `json:"order_direction"`
 	PageReq
+	// the visibility of the user, which is filtered by the searchers natively
+
+	// Paths limit the nodes to the ones under them and the parents of them if not empty
+	Paths []string `json:"-"`
+	// Rules are the nodes hidden from the user by the metas
+	Rules []SearchRule `json:"-"`
+	// HidePaths are the regexes of the paths of the nodes hidden
+	HidePaths []string `json:"-"`
+}
+
+// SearchRule hides the children of the folder Parent, and the ones in its sub folders if Sub
+// except the folders under Except, which have their own rules
+type SearchRule struct {
+	Parent string
+	Sub    bool
+	Except []string
+	// Names are the regexes of the names hidden, all the children are hidden if it's empty
+	Names []string
 }
 
 type SearchNode struct {
+	// ID is the integer primary key in db, which the full text index refers to
+	ID     uint   `json:"-" gorm:"primaryKey"`
 	Parent string `json:"parent" gorm:"index"`
 	Name   string `json:"name"`
 	IsDir  bool   `json:"is_dir"`
 	Size   int64  `json:"size"`
+	// Modified is the modified time of the obj when it's indexed
+	Modified time.Time `json:"modified"`
+	// ObjType is the type of the obj by the type settings when it's indexed
+	ObjType int `json:"obj_type"`
+	// Ext is the lower case extension of the file without dot
+	Ext string `json:"ext"`
+	// Content is the text extracted from the file, it's only indexed for the storages enabled,
+	// the size is large enough to be a long text in mysql and postgres
+	Content string `json:"content,omitempty" gorm:"size:1048576"`
+	// Snippet is the part of the content matched with the keywords highlighted, only set in the results
+	Snippet string `json:"snippet,omitempty" gorm:"-"`
 }
 
 func (p *SearchReq) Validate() error {
@@ -34,6 +91,34 @@ func (p *SearchReq) Validate() error {
 	if p.PerPage < 1 {
 		return fmt.Errorf("per_page can't < 1")
 	}
+	switch p.Mode {
+	case "", SearchPhrase, SearchWildcard:
+	case SearchRegex:
+		if _, err := regexp.Compile(p.Keywords); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown search mode: %s", p.Mode)
+	}
+	if p.MinSize < 0 || p.MaxSize < 0 || (p.MaxSize > 0 && p.MinSize > p.MaxSize) {
+		return fmt.Errorf("invalid size range")
+	}
+	if p.ModifiedAfter != nil && p.ModifiedBefore != nil && p.ModifiedAfter.After(*p.ModifiedBefore) {
+		return fmt.Errorf("invalid modified time range")
+	}
+	for i := range p.Exts {
+		p.Exts[i] = strings.ToLower(strings.TrimPrefix(p.Exts[i], "."))
+	}
+	switch p.OrderBy {
+	case "", "name", "size", "modified":
+	default:
+		return fmt.Errorf("can't order by %s", p.OrderBy)
+	}
+	switch p.OrderDirection {
+	case "", "asc", "desc":
+	default:
+		return fmt.Errorf("unknown order direction: %s", p.OrderDirection)
+	}
 	return nil
 }
 
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+)
+
+type Share struct {
+	ID string `json:"id" gorm:"primaryKey;size:32"`
+	// Path is the full path of the shared file or folder, i.e. with the base path of the creator
+	Path      string     `json:"path" binding:"required"`
+	CreatorID uint       `json:"creator_id" gorm:"index"`
+	PwdHash   string     `json:"-"`
+	Salt      string     `json:"-"`
+	Expires   *time.Time `json:"expires"`
+	// MaxAccess is the max times of downloading, 0 means unlimited
+	MaxAccess   int       `json:"max_access"`
+	AccessCount int       `json:"access_count"`
+	AllowUpload bool      `json:"allow_upload"`
+	Disabled    bool      `json:"disabled"`
+	Created     time.Time `json:"created"`
+}
+
+func (s *Share) Validate() error {
+	if err := s.ValidateState(); err != nil {
+		return err
+	}
+	if s.MaxAccess > 0 && s.AccessCount >= s.MaxAccess {
+		return errors.New("share has reached the max access count")
+	}
+	return nil
+}
+
+// ValidateState check whether the share is enabled and not expired, regardless of the access count
+func (s *Share) ValidateState() error {
+	if s.Disabled {
+		return errors.New("share is disabled")
+	}
+	if s.Expires != nil && time.Now().After(*s.Expires) {
+		return errors.New("share is expired")
+	}
+	return nil
+}
+
+// SetPassword set the hash of the password, empty means no password
+func (s *Share) SetPassword(pwd string) {
+	if pwd == "" {
+		s.PwdHash, s.Salt = "", ""
+		return
+	}
+	s.Salt = random.String(16)
+	s.PwdHash = TwoHashPwd(pwd, s.Salt)
+}
+
+func (s *Share) HasPassword() bool {
+	return s.PwdHash != ""
+}
+
+// ValidatePassword check the raw password, it's always valid if the share has no password
+func (s *Share) ValidatePassword(pwd string) bool {
+	if !s.HasPassword() {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(TwoHashPwd(pwd, s.Salt)), []byte(s.PwdHash)) == 1
+}
@@ -16,9 +16,12 @@ type Storage struct {
 	Modified        time.Time `json:"modified"`
 	Disabled        bool      `json:"disabled"` // if disabled
 	DisableIndex    bool      `json:"disable_index"`
+	IndexContent    bool      `json:"index_content"` // index the content of the text, pdf and office files
 	EnableSign      bool      `json:"enable_sign"`
 	Sort
 	Proxy
+	Trash
+	Versioning
 }
 
 type Sort struct {
@@ -34,6 +37,19 @@ type Proxy struct {
 	DownProxyUrl string `json:"down_proxy_url"`
 }
 
+type Trash struct {
+	// TrashPolicy is one of global, enabled and disabled
+	TrashPolicy string `json:"trash_policy"`
+	// TrashPath overrides the global trash path if the policy is enabled
+	TrashPath string `json:"trash_path"`
+}
+
+type Versioning struct {
+	// VersionsKeep is the number of old versions kept when a file is overwritten, 0 means disabled
+	VersionsKeep int    `json:"versions_keep"`
+	VersionsPath string `json:"versions_path"`
+}
+
 func (s *Storage) GetStorage() *Storage {
 	return s
 }
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	StorageDown         = "down"
+	StorageRecovered    = "recovered"
+	StorageReinitFailed = "reinit_failed"
+)
+
+// StorageHealthLog is a change of the health of a storage found by the health check
+type StorageHealthLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	StorageID uint      `json:"storage_id" gorm:"index"`
+	MountPath string    `json:"mount_path"`
+	Time      time.Time `json:"time"`
+	// Event is one of down, recovered and reinit_failed
+	Event   string `json:"event"`
+	Message string `json:"message" gorm:"type:text"`
+}
+
+// StorageHealth is the current health of a loaded storage
+type StorageHealth struct {
+	StorageID uint       `json:"storage_id"`
+	MountPath string     `json:"mount_path"`
+	Driver    string     `json:"driver"`
+	Status    string     `json:"status"`
+	Healthy   bool       `json:"healthy"`
+	Failures  int        `json:"failures"`
+	LastCheck *time.Time `json:"last_check"`
+	NextCheck *time.Time `json:"next_check"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+type TrashItem struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	StorageID uint   `json:"storage_id" gorm:"index"`
+	MountPath string `json:"mount_path"`
+	// Path is the original actual path in the storage
+	Path string `json:"path"`
+	// TrashPath is the actual path of the object in the trash folder
+	TrashPath string    `json:"trash_path"`
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	IsDir     bool      `json:"is_dir"`
+	DeletedBy string    `json:"deleted_by"`
+	DeletedAt time.Time `json:"deleted_at" gorm:"index"`
+}
@@ -50,6 +50,14 @@ type User struct {
 	OtpSecret  string `json:"-"`
 	SsoID      string `json:"sso_id"` // unique by sso platform
 	Authn      string `gorm:"type:text" json:"-"`
+	Quota
+	// GroupIDs are the ids of the groups the user belongs to
+	GroupIDs []uint `json:"group_ids" gorm:"-"`
+	// inherited from the enabled groups, see SetGroups
+	groupPermission int32
+	groupBasePaths  []string
+	groupMetas      []GroupMeta
+	acls            []ACL
 }
 
 func (u *User) IsGuest() bool {
@@ -81,64 +89,249 @@ func (u *User) SetPassword(pwd string) *User {
 	return u
 }
 
+// SetGroups set the groups of the user, the user inherits the permission bits, the base paths
+// and the metas of the enabled ones
+func (u *User) SetGroups(groups []Group, metas []GroupMeta) {
+	u.GroupIDs = make([]uint, 0, len(groups))
+	u.groupPermission, u.groupBasePaths, u.groupMetas = 0, nil, nil
+	enabled := make(map[uint]bool)
+	for _, g := range groups {
+		u.GroupIDs = append(u.GroupIDs, g.ID)
+		if g.Disabled {
+			continue
+		}
+		enabled[g.ID] = true
+		u.groupPermission |= g.Permission
+		// the base paths of the groups are kept as separate roots, the members can only
+		// access the paths in them, see CanAccessPath
+		basePath := utils.FixAndCleanPath(g.BasePath)
+		if basePath == "/" {
+			u.groupBasePaths = []string{"/"}
+		} else if !utils.SliceContains(u.groupBasePaths, "/") && !utils.SliceContains(u.groupBasePaths, basePath) {
+			u.groupBasePaths = append(u.groupBasePaths, basePath)
+		}
+	}
+	for _, m := range metas {
+		if enabled[m.GroupID] {
+			u.groupMetas = append(u.groupMetas, m)
+		}
+	}
+}
+
+// roots returns the base paths of the groups if the user can only access the paths in them,
+// the only one is used as the base path directly
+func (u *User) roots() []string {
+	if utils.FixAndCleanPath(u.BasePath) != "/" || len(u.groupBasePaths) <= 1 {
+		return nil
+	}
+	return u.groupBasePaths
+}
+
+// InBasePath check whether the path is in the base path of the user, or one of the base paths of the groups
+func (u *User) InBasePath(path string) bool {
+	roots := u.roots()
+	if len(roots) == 0 {
+		return utils.IsSubPath(u.GetBasePath(), path)
+	}
+	for _, root := range roots {
+		if utils.IsSubPath(root, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetACLs set the ACLs of the user and the enabled groups
+func (u *User) SetACLs(acls []ACL) {
+	u.acls = acls
+}
+
+// HasLimitedPaths returns whether the user can only access some paths by the base paths of the groups or the ACLs
+func (u *User) HasLimitedPaths() bool {
+	return len(u.roots()) > 0 || len(u.acls) > 0
+}
+
+// LimitedPaths returns the paths which the user can access by the base paths of the groups and the ACLs,
+// nil if it's not limited
+func (u *User) LimitedPaths() []string {
+	roots, acls := u.roots(), u.aclPaths()
+	if len(roots) == 0 {
+		if len(acls) == 0 {
+			return nil
+		}
+		return acls
+	}
+	if len(acls) == 0 {
+		return roots
+	}
+	// the paths in both of the roots and the ACLs
+	res := make([]string, 0)
+	for _, root := range roots {
+		for _, a := range acls {
+			if utils.IsSubPath(root, a) {
+				res = append(res, a)
+			} else if utils.IsSubPath(a, root) {
+				res = append(res, root)
+			}
+		}
+	}
+	return res
+}
+
+func (u *User) aclPaths() []string {
+	paths := make([]string, 0, len(u.acls))
+	for _, a := range u.acls {
+		paths = append(paths, utils.FixAndCleanPath(a.Path))
+	}
+	return paths
+}
+
+// canWalkTo check whether the path is in one of the paths or a parent of them, so that the user can walk to them
+func canWalkTo(paths []string, path string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		if utils.IsSubPath(p, path) || utils.IsSubPath(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessPath check the path against the base paths of the groups and the ACLs, the paths in them
+// and the parents of them, so that the user can walk to the granted paths, can be accessed
+func (u *User) CanAccessPath(path string) bool {
+	return canWalkTo(u.roots(), path) && canWalkTo(u.aclPaths(), path)
+}
+
+// At returns the user with the permission at the path, the permission of the nearest ACLs
+// replaces the one of the user and the groups, and nothing is permitted outside the ACLs
+func (u *User) At(path string) *User {
+	if len(u.acls) == 0 {
+		return u
+	}
+	res := *u
+	res.Permission, _ = nearestACLPermission(u.acls, path)
+	res.groupPermission = 0
+	return &res
+}
+
+// GetPermission returns the union of the permission of the user and the groups
+func (u *User) GetPermission() int32 {
+	return u.Permission | u.groupPermission
+}
+
+// GetBasePath returns the base path of the user, the common parent of the base paths of the groups is used
+// if the user's own base path is the root, the paths out of them are denied by CanAccessPath
+func (u *User) GetBasePath() string {
+	basePath := utils.FixAndCleanPath(u.BasePath)
+	if basePath == "/" && len(u.groupBasePaths) > 0 {
+		res := u.groupBasePaths[0]
+		for _, p := range u.groupBasePaths[1:] {
+			res = utils.CommonParent(res, p)
+		}
+		return res
+	}
+	return basePath
+}
+
+// GroupMetaPaths returns the paths of the metas of the enabled groups
+func (u *User) GroupMetaPaths() []string {
+	paths := make([]string, 0, len(u.groupMetas))
+	for _, m := range u.groupMetas {
+		paths = append(paths, utils.FixAndCleanPath(m.Path))
+	}
+	return paths
+}
+
+// NearestGroupMeta returns the group meta nearest to the path, or nil if there is none
+func (u *User) NearestGroupMeta(path string) *GroupMeta {
+	var nearest []*GroupMeta
+	for i := range u.groupMetas {
+		m := &u.groupMetas[i]
+		if !utils.IsSubPath(m.Path, path) {
+			continue
+		}
+		if len(nearest) > 0 && len(utils.FixAndCleanPath(m.Path)) < len(utils.FixAndCleanPath(nearest[0].Path)) {
+			continue
+		}
+		if len(nearest) > 0 && !utils.PathEqual(m.Path, nearest[0].Path) {
+			nearest = nearest[:0]
+		}
+		nearest = append(nearest, m)
+	}
+	if len(nearest) == 0 {
+		return nil
+	}
+	return mergeGroupMetas(nearest)
+}
+
 func (u *User) CanSeeHides() bool {
-	return u.Permission&1 == 1
+	return u.GetPermission()&1 == 1
 }
 
 func (u *User) CanAccessWithoutPassword() bool {
-	return (u.Permission>>1)&1 == 1
+	return (u.GetPermission()>>1)&1 == 1
 }
 
 func (u *User) CanAddOfflineDownloadTasks() bool {
-	return (u.Permission>>2)&1 == 1
+	return (u.GetPermission()>>2)&1 == 1
 }
 
 func (u *User) CanWrite() bool {
-	return (u.Permission>>3)&1 == 1
+	return (u.GetPermission()>>3)&1 == 1
 }
 
 func (u *User) CanRename() bool {
-	return (u.Permission>>4)&1 == 1
+	return (u.GetPermission()>>4)&1 == 1
 }
 
 func (u *User) CanMove() bool {
-	return (u.Permission>>5)&1 == 1
+	return (u.GetPermission()>>5)&1 == 1
 }
 
 func (u *User) CanCopy() bool {
-	return (u.Permission>>6)&1 == 1
+	return (u.GetPermission()>>6)&1 == 1
 }
 
 func (u *User) CanRemove() bool {
-	return (u.Permission>>7)&1 == 1
+	return (u.GetPermission()>>7)&1 == 1
 }
 
 func (u *User) CanWebdavRead() bool {
-	return (u.Permission>>8)&1 == 1
+	return (u.GetPermission()>>8)&1 == 1
 }
 
 func (u *User) CanWebdavManage() bool {
-	return (u.Permission>>9)&1 == 1
+	return (u.GetPermission()>>9)&1 == 1
 }
 
 func (u *User) CanFTPAccess() bool {
-	return (u.Permission>>10)&1 == 1
+	return (u.GetPermission()>>10)&1 == 1
 }
 
 func (u *User) CanFTPManage() bool {
-	return (u.Permission>>11)&1 == 1
+	return (u.GetPermission()>>11)&1 == 1
 }
 
 func (u *User) CanReadArchives() bool {
-	return (u.Permission>>12)&1 == 1
+	return (u.GetPermission()>>12)&1 == 1
 }
 
 func (u *User) CanDecompress() bool {
-	return (u.Permission>>13)&1 == 1
+	return (u.GetPermission()>>13)&1 == 1
 }
 
 func (u *User) JoinPath(reqPath string) (string, error) {
-	return utils.JoinBasePath(u.BasePath, reqPath)
+	path, err := utils.JoinBasePath(u.GetBasePath(), reqPath)
+	if err != nil {
+		return "", err
+	}
+	if !u.CanAccessPath(path) {
+		return "", errors.WithStack(errs.PermissionDenied)
+	}
+	return path, nil
 }
 
 func StaticHash(password string) string {
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// FileVersion is an old version of a file kept when it's overwritten
+type FileVersion struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	// Created is when the version is created, i.e. the file is overwritten
+	Created time.Time `json:"created"`
+	// Path is the actual path of the version in the storage
+	Path string `json:"path"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Webhook is the url which the events are posted to, the body is signed by Secret if it's set
+type Webhook struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	Name   string `json:"name"`
+	URL    string `json:"url" binding:"required"`
+	Secret string `json:"secret"`
+	// Events are the types of the events separated by comma, empty means all
+	Events   string `json:"events"`
+	Disabled bool   `json:"disabled"`
+}
+
+// Accept returns whether the event of the type should be posted to the webhook
+func (w *Webhook) Accept(typ string) bool {
+	if w.Disabled {
+		return false
+	}
+	if strings.TrimSpace(w.Events) == "" {
+		return true
+	}
+	for _, e := range strings.Split(w.Events, ",") {
+		if strings.TrimSpace(e) == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is the result of posting an event to a webhook
+type WebhookDelivery struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WebhookID  uint      `json:"webhook_id" gorm:"index"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Time       time.Time `json:"time"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status_code"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error" gorm:"type:text"`
+	// Duration is the milliseconds spent on all the attempts
+	Duration int64 `json:"duration"`
+}
@@ -27,11 +27,12 @@ type DownloadTask struct {
 	callStatusRetried int
 }
 
-func (t *DownloadTask) Run() error {
+func (t *DownloadTask) Run() (err error) {
 	t.ReinitCtx()
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
+	defer t.StartRun("offline_download", t.GetName())(&err)
 	if t.tool == nil {
 		tool, err := Tools.Get(t.Toolname)
 		if err != nil {
@@ -168,11 +169,11 @@ func (t *DownloadTask) Transfer() error {
 	if toolName == "115 Cloud" || toolName == "PikPak" || toolName == "Thunder" || toolName == "ThunderBrowser" || toolName == "ThunderX" {
 		// 如果不是直接下载到目标路径，则进行转存
 		if t.TempDir != t.DstDirPath {
-			return transferObj(t.Ctx(), t.TempDir, t.DstDirPath, t.DeletePolicy)
+			return transferObj(t.Ctx(), t.GetID(), t.TempDir, t.DstDirPath, t.DeletePolicy)
 		}
 		return nil
 	}
-	return transferStd(t.Ctx(), t.TempDir, t.DstDirPath, t.DeletePolicy)
+	return transferStd(t.Ctx(), t.GetID(), t.TempDir, t.DstDirPath, t.DeletePolicy)
 }
 
 func (t *DownloadTask) GetName() string {
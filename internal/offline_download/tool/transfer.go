@@ -31,11 +31,12 @@ type TransferTask struct {
 	DeletePolicy DeletePolicy  `json:"delete_policy"`
 }
 
-func (t *TransferTask) Run() error {
+func (t *TransferTask) Run() (err error) {
 	t.ReinitCtx()
 	t.ClearEndTime()
 	t.SetStartTime(time.Now())
 	defer func() { t.SetEndTime(time.Now()) }()
+	defer t.StartRun("transfer", t.GetName())(&err)
 	if t.SrcStorage == nil {
 		return transferStdPath(t)
 	} else {
@@ -75,7 +76,7 @@ var (
 	TransferTaskManager *tache.Manager[*TransferTask]
 )
 
-func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy DeletePolicy) error {
+func transferStd(ctx context.Context, parentID, tempDir, dstDirPath string, deletePolicy DeletePolicy) error {
 	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
 	if err != nil {
 		return errors.WithMessage(err, "failed get dst storage")
@@ -88,7 +89,8 @@ func transferStd(ctx context.Context, tempDir, dstDirPath string, deletePolicy D
 	for _, entry := range entries {
 		t := &TransferTask{
 			TaskExtension: task.TaskExtension{
-				Creator: taskCreator,
+				Creator:  taskCreator,
+				ParentID: parentID,
 			},
 			SrcObjPath:   stdpath.Join(tempDir, entry.Name()),
 			DstDirPath:   dstDirActualPath,
@@ -118,7 +120,8 @@ func transferStdPath(t *TransferTask) error {
 			dstObjPath := stdpath.Join(t.DstDirPath, info.Name())
 			t := &TransferTask{
 				TaskExtension: task.TaskExtension{
-					Creator: t.Creator,
+					Creator:  t.Creator,
+					ParentID: t.GetID(),
 				},
 				SrcObjPath:   srcRawPath,
 				DstDirPath:   dstObjPath,
@@ -144,6 +147,10 @@ func transferStdFile(t *TransferTask) error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to get file %s", t.SrcObjPath)
 	}
+	if err = op.ReserveQuota(t.Creator, info.Size()); err != nil {
+		_ = rc.Close()
+		return err
+	}
 	mimetype := utils.GetMimeType(t.SrcObjPath)
 	s := &stream.FileStream{
 		Ctx: nil,
@@ -158,7 +165,12 @@ func transferStdFile(t *TransferTask) error {
 		Closers:  utils.NewClosers(rc),
 	}
 	t.SetTotalBytes(info.Size())
-	return op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, s, t.SetProgress)
+	if err = op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, s, t.SetProgress); err != nil {
+		op.ReleaseQuota(t.Creator, info.Size())
+		return err
+	}
+	op.AddUsage(t.Creator, t.DstStorage, stdpath.Join(t.DstDirPath, s.GetName()), info.Size())
+	return nil
 }
 
 func removeStdTemp(t *TransferTask) {
@@ -171,7 +183,7 @@ func removeStdTemp(t *TransferTask) {
 	}
 }
 
-func transferObj(ctx context.Context, tempDir, dstDirPath string, deletePolicy DeletePolicy) error {
+func transferObj(ctx context.Context, parentID, tempDir, dstDirPath string, deletePolicy DeletePolicy) error {
 	srcStorage, srcObjActualPath, err := op.GetStorageAndActualPath(tempDir)
 	if err != nil {
 		return errors.WithMessage(err, "failed get src storage")
@@ -188,7 +200,8 @@ func transferObj(ctx context.Context, tempDir, dstDirPath string, deletePolicy D
 	for _, obj := range objs {
 		t := &TransferTask{
 			TaskExtension: task.TaskExtension{
-				Creator: taskCreator,
+				Creator:  taskCreator,
+				ParentID: parentID,
 			},
 			SrcObjPath:   stdpath.Join(srcObjActualPath, obj.GetName()),
 			DstDirPath:   dstDirActualPath,
@@ -223,7 +236,8 @@ func transferObjPath(t *TransferTask) error {
 			dstObjPath := stdpath.Join(t.DstDirPath, srcObj.GetName())
 			TransferTaskManager.Add(&TransferTask{
 				TaskExtension: task.TaskExtension{
-					Creator: t.Creator,
+					Creator:  t.Creator,
+					ParentID: t.GetID(),
 				},
 				SrcObjPath:   srcObjPath,
 				DstDirPath:   dstObjPath,
@@ -240,11 +254,19 @@ func transferObjPath(t *TransferTask) error {
 	return transferObjFile(t)
 }
 
-func transferObjFile(t *TransferTask) error {
+func transferObjFile(t *TransferTask) (err error) {
 	srcFile, err := op.Get(t.Ctx(), t.SrcStorage, t.SrcObjPath)
 	if err != nil {
 		return errors.WithMessagef(err, "failed get src [%s] file", t.SrcObjPath)
 	}
+	if err = op.ReserveQuota(t.Creator, srcFile.GetSize()); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			op.ReleaseQuota(t.Creator, srcFile.GetSize())
+		}
+	}()
 	link, _, err := op.Link(t.Ctx(), t.SrcStorage, t.SrcObjPath, model.LinkArgs{
 		Header: http.Header{},
 	})
@@ -261,7 +283,11 @@ func transferObjFile(t *TransferTask) error {
 		return errors.WithMessagef(err, "failed get [%s] stream", t.SrcObjPath)
 	}
 	t.SetTotalBytes(srcFile.GetSize())
-	return op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, ss, t.SetProgress)
+	if err = op.Put(t.Ctx(), t.DstStorage, t.DstDirPath, ss, t.SetProgress); err != nil {
+		return err
+	}
+	op.AddUsage(t.Creator, t.DstStorage, stdpath.Join(t.DstDirPath, srcFile.GetName()), srcFile.GetSize())
+	return nil
 }
 
 func removeObjTemp(t *TransferTask) {
@@ -269,7 +295,7 @@ func removeObjTemp(t *TransferTask) {
 	if err != nil || srcObj.IsDir() {
 		return
 	}
-	if err := op.Remove(t.Ctx(), t.SrcStorage, t.SrcObjPath); err != nil {
+	if err := op.RemoveDirectly(t.Ctx(), t.SrcStorage, t.SrcObjPath); err != nil {
 		log.Errorf("failed to delete temp obj %s, error: %s", t.SrcObjPath, err.Error())
 	}
 }
@@ -0,0 +1,51 @@
+package op
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func GetACLById(id uint) (*model.ACL, error) {
+	return db.GetACLById(id)
+}
+
+func GetACLs(pageIndex, pageSize int) ([]model.ACL, int64, error) {
+	return db.GetACLs(pageIndex, pageSize)
+}
+
+func CreateACL(a *model.ACL) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	a.Path = utils.FixAndCleanPath(a.Path)
+	if err := db.CreateACL(a); err != nil {
+		return err
+	}
+	// the ACLs are cached with the users
+	clearUserCache()
+	return nil
+}
+
+func UpdateACL(a *model.ACL) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	if _, err := db.GetACLById(a.ID); err != nil {
+		return err
+	}
+	a.Path = utils.FixAndCleanPath(a.Path)
+	if err := db.UpdateACL(a); err != nil {
+		return err
+	}
+	clearUserCache()
+	return nil
+}
+
+func DeleteACLById(id uint) error {
+	if err := db.DeleteACLById(id); err != nil {
+		return err
+	}
+	clearUserCache()
+	return nil
+}
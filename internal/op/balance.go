@@ -0,0 +1,397 @@
+package op
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// a member is skipped for balanceSkipDuration after balanceFailThreshold backend errors in a row
+	balanceFailThreshold = 3
+	balanceSkipDuration  = 30 * time.Second
+	// the weight of the latest latency in the moving average
+	balanceLatencyAlpha = 0.2
+)
+
+var balanceGroups struct {
+	sync.RWMutex
+	byPath    map[string]*model.BalanceGroup
+	byStorage map[uint]*model.BalanceGroup
+}
+
+// LoadBalanceGroups load the balance groups in db to memory
+func LoadBalanceGroups() error {
+	groups, err := db.GetBalanceGroups()
+	if err != nil {
+		return err
+	}
+	byPath := make(map[string]*model.BalanceGroup, len(groups))
+	byStorage := make(map[uint]*model.BalanceGroup)
+	for i := range groups {
+		g := &groups[i]
+		byPath[g.MountPath] = g
+		for _, m := range g.Members {
+			byStorage[m.StorageID] = g
+		}
+	}
+	balanceGroups.Lock()
+	balanceGroups.byPath, balanceGroups.byStorage = byPath, byStorage
+	balanceGroups.Unlock()
+	return nil
+}
+
+func getBalanceGroup(mountPath string) *model.BalanceGroup {
+	balanceGroups.RLock()
+	defer balanceGroups.RUnlock()
+	return balanceGroups.byPath[mountPath]
+}
+
+// GetVirtualMountPath returns the path where the storage is shown, which is the mount path of
+// its balance group if it's a member, otherwise the mount path without the balance suffix
+func GetVirtualMountPath(storage driver.Driver) string {
+	balanceGroups.RLock()
+	g, ok := balanceGroups.byStorage[storage.GetStorage().ID]
+	balanceGroups.RUnlock()
+	if ok {
+		return g.MountPath
+	}
+	return utils.GetActualMountPath(storage.GetStorage().MountPath)
+}
+
+func GetBalanceGroups() ([]model.BalanceGroup, error) {
+	return db.GetBalanceGroups()
+}
+
+func GetBalanceGroupById(id uint) (*model.BalanceGroup, error) {
+	return db.GetBalanceGroupById(id)
+}
+
+func checkBalanceGroup(g *model.BalanceGroup) error {
+	g.MountPath = utils.FixAndCleanPath(g.MountPath)
+	if utils.IsBalance(g.MountPath) {
+		return errors.New("the mount path of balance group can't contain .balance")
+	}
+	switch g.Strategy {
+	case "":
+		g.Strategy = model.BalanceRoundRobin
+	case model.BalanceRoundRobin, model.BalanceWeighted, model.BalanceLeastActive, model.BalanceLatency, model.BalanceFailover:
+	default:
+		return errors.Errorf("unknown balance strategy: %s", g.Strategy)
+	}
+	if g.StickyTTL < 0 {
+		return errors.New("sticky ttl can't be negative")
+	}
+	balanceGroups.RLock()
+	defer balanceGroups.RUnlock()
+	seen := make(map[uint]struct{}, len(g.Members))
+	for _, m := range g.Members {
+		if _, ok := seen[m.StorageID]; ok {
+			return errors.Errorf("storage %d is added twice", m.StorageID)
+		}
+		seen[m.StorageID] = struct{}{}
+		if other, ok := balanceGroups.byStorage[m.StorageID]; ok && other.ID != g.ID {
+			return errors.Errorf("storage %d is already a member of %s", m.StorageID, other.MountPath)
+		}
+		if _, err := db.GetStorageById(m.StorageID); err != nil {
+			return errors.WithMessagef(err, "failed get storage %d", m.StorageID)
+		}
+	}
+	return nil
+}
+
+func CreateBalanceGroup(g *model.BalanceGroup) error {
+	if err := checkBalanceGroup(g); err != nil {
+		return err
+	}
+	if err := db.CreateBalanceGroup(g); err != nil {
+		return err
+	}
+	return LoadBalanceGroups()
+}
+
+func UpdateBalanceGroup(g *model.BalanceGroup) error {
+	if _, err := db.GetBalanceGroupById(g.ID); err != nil {
+		return err
+	}
+	if err := checkBalanceGroup(g); err != nil {
+		return err
+	}
+	if err := db.UpdateBalanceGroup(g); err != nil {
+		return err
+	}
+	return LoadBalanceGroups()
+}
+
+func DeleteBalanceGroupById(id uint) error {
+	if err := db.DeleteBalanceGroupById(id); err != nil {
+		return err
+	}
+	return LoadBalanceGroups()
+}
+
+type balanceStat struct {
+	active    atomic.Int64
+	mu        sync.Mutex
+	latency   float64
+	failures  int
+	skipUntil time.Time
+}
+
+var balanceStats generic_sync.MapOf[uint, *balanceStat]
+
+func getBalanceStat(storage driver.Driver) *balanceStat {
+	stat, _ := balanceStats.LoadOrStore(storage.GetStorage().ID, &balanceStat{})
+	return stat
+}
+
+// startBackendCall count the call to the backend of the storage as active,
+// the returned func record the latency or the error of the call
+func startBackendCall(storage driver.Driver) func(err error) {
+	stat := getBalanceStat(storage)
+	stat.active.Add(1)
+	start := time.Now()
+	return func(err error) {
+		stat.active.Add(-1)
+		stat.mu.Lock()
+		defer stat.mu.Unlock()
+		if err != nil {
+			// only the errors of the backend itself make it unavailable
+			if errs.IsNotFoundError(err) || errs.IsNotSupportError(err) || errors.Is(err, context.Canceled) {
+				return
+			}
+			stat.failures++
+			if stat.failures >= balanceFailThreshold {
+				stat.skipUntil = time.Now().Add(balanceSkipDuration)
+			}
+			return
+		}
+		stat.failures = 0
+		ms := float64(time.Since(start)) / float64(time.Millisecond)
+		if stat.latency == 0 {
+			stat.latency = ms
+		} else {
+			stat.latency = balanceLatencyAlpha*ms + (1-balanceLatencyAlpha)*stat.latency
+		}
+	}
+}
+
+// Transfer is a download proxied by alist. it's put in the context at conf.TransferKey before
+// getting the link, then counted as active on the storage of the link until Done is called
+type Transfer struct {
+	stat *balanceStat
+}
+
+func NewTransfer() *Transfer {
+	return &Transfer{}
+}
+
+func (t *Transfer) start(storage driver.Driver) {
+	if t.stat != nil {
+		return
+	}
+	t.stat = getBalanceStat(storage)
+	t.stat.active.Add(1)
+}
+
+// Done stop counting the transfer as active
+func (t *Transfer) Done() {
+	if t.stat != nil {
+		t.stat.active.Add(-1)
+		t.stat = nil
+	}
+}
+
+func (s *balanceStat) skipped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Now().Before(s.skipUntil)
+}
+
+func (s *balanceStat) getLatency() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latency
+}
+
+// GetBalanceStats returns the stats of the members of the balance group at mountPath
+func GetBalanceStats(mountPath string) []model.BalanceStats {
+	mountPath = utils.FixAndCleanPath(mountPath)
+	members := balanceMembers(mountPath)
+	res := make([]model.BalanceStats, 0, len(members))
+	for _, storage := range members {
+		stat := getBalanceStat(storage)
+		stat.mu.Lock()
+		res = append(res, model.BalanceStats{
+			StorageID: storage.GetStorage().ID,
+			MountPath: storage.GetStorage().MountPath,
+			Active:    stat.active.Load(),
+			Latency:   stat.latency,
+			Failures:  stat.failures,
+			Skipped:   time.Now().Before(stat.skipUntil),
+		})
+		stat.mu.Unlock()
+	}
+	return res
+}
+
+// balanceMembers returns the loaded members of the group at mountPath, the members configured are
+// in front in order, then the others sorted by mount path
+func balanceMembers(mountPath string) []driver.Driver {
+	var storages []driver.Driver
+	storagesMap.Range(func(_ string, storage driver.Driver) bool {
+		if GetVirtualMountPath(storage) == mountPath {
+			storages = append(storages, storage)
+		}
+		return true
+	})
+	return sortBalanceMembers(storages, getBalanceGroup(mountPath))
+}
+
+func sortBalanceMembers(storages []driver.Driver, group *model.BalanceGroup) []driver.Driver {
+	index := func(storage driver.Driver) int {
+		if group != nil {
+			for i, m := range group.Members {
+				if m.StorageID == storage.GetStorage().ID {
+					return i
+				}
+			}
+		}
+		return len(storages)
+	}
+	slices.SortStableFunc(storages, func(a, b driver.Driver) int {
+		if i, j := index(a), index(b); i != j {
+			return i - j
+		}
+		return strings.Compare(a.GetStorage().MountPath, b.GetStorage().MountPath)
+	})
+	return storages
+}
+
+// balanceCounters is the counter of each balance group for the round robin strategy
+var balanceCounters generic_sync.MapOf[string, *atomic.Uint64]
+
+var stickyCache = cache.NewMemCache[string]()
+
+// GetBalancedStorage get storage by path
+func GetBalancedStorage(path string) driver.Driver {
+	return getBalancedStorage(path, "")
+}
+
+// getBalancedStorage pick a member of the balance group by its strategy, the members which
+// are not working or erroring are skipped unless all of them are
+func getBalancedStorage(path, client string) driver.Driver {
+	path = utils.FixAndCleanPath(path)
+	storages := getStoragesByPath(path)
+	switch len(storages) {
+	case 0:
+		return nil
+	case 1:
+		return storages[0]
+	}
+	virtualPath := GetVirtualMountPath(storages[0])
+	group := getBalanceGroup(virtualPath)
+	storages = sortBalanceMembers(storages, group)
+	candidates := make([]driver.Driver, 0, len(storages))
+	for _, storage := range storages {
+		if storage.GetStorage().Status == WORK && !getBalanceStat(storage).skipped() {
+			candidates = append(candidates, storage)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = storages
+	}
+	sticky := group != nil && group.StickyTTL > 0 && client != ""
+	stickyKey := virtualPath + "\n" + client
+	if sticky {
+		if mountPath, ok := stickyCache.Get(stickyKey); ok {
+			for _, storage := range candidates {
+				if storage.GetStorage().MountPath == mountPath {
+					return storage
+				}
+			}
+		}
+	}
+	strategy := model.BalanceRoundRobin
+	if group != nil {
+		strategy = group.Strategy
+	}
+	var selected driver.Driver
+	switch strategy {
+	case model.BalanceWeighted:
+		selected = pickWeighted(candidates, group)
+	case model.BalanceLeastActive:
+		// the active ops and the downloads proxied by alist are counted
+		selected = slices.MinFunc(candidates, func(a, b driver.Driver) int {
+			return int(getBalanceStat(a).active.Load() - getBalanceStat(b).active.Load())
+		})
+	case model.BalanceLatency:
+		// the members without latency are picked first to measure them
+		selected = slices.MinFunc(candidates, func(a, b driver.Driver) int {
+			la, lb := getBalanceStat(a).getLatency(), getBalanceStat(b).getLatency()
+			switch {
+			case la < lb:
+				return -1
+			case la > lb:
+				return 1
+			}
+			return 0
+		})
+	case model.BalanceFailover:
+		selected = candidates[0]
+	default:
+		counter, _ := balanceCounters.LoadOrStore(virtualPath, &atomic.Uint64{})
+		selected = candidates[counter.Add(1)%uint64(len(candidates))]
+	}
+	if sticky {
+		stickyCache.Set(stickyKey, selected.GetStorage().MountPath,
+			cache.WithEx[string](time.Duration(group.StickyTTL)*time.Second))
+	}
+	return selected
+}
+
+func pickWeighted(candidates []driver.Driver, group *model.BalanceGroup) driver.Driver {
+	weights := make([]int, len(candidates))
+	total := 0
+	for i, storage := range candidates {
+		weights[i] = 1
+		for _, m := range group.Members {
+			if m.StorageID == storage.GetStorage().ID && m.Weight > 0 {
+				weights[i] = m.Weight
+			}
+		}
+		total += weights[i]
+	}
+	n := rand.Intn(total)
+	for i, w := range weights {
+		if n < w {
+			return candidates[i]
+		}
+		n -= w
+	}
+	return candidates[len(candidates)-1]
+}
+
+// clientIP returns the ip of the client in ctx without port, which is used for the sticky balance
+func clientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(conf.ClientIPKey).(string)
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		return host
+	}
+	return ip
+}
@@ -0,0 +1,116 @@
+package op_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	mapset "github.com/deckarep/golang-set/v2"
+)
+
+func TestBalanceGroup(t *testing.T) {
+	var ids []uint
+	for _, mountPath := range []string{"/m1", "/m2"} {
+		id, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: mountPath, Addition: `{"root_folder_path":"."}`})
+		if err != nil {
+			t.Fatalf("failed to create storage: %+v", err)
+		}
+		ids = append(ids, id)
+	}
+	group := model.BalanceGroup{
+		MountPath: "/bal",
+		Strategy:  model.BalanceFailover,
+		Members:   []model.BalanceMember{{StorageID: ids[0]}, {StorageID: ids[1]}},
+	}
+	if err := op.CreateBalanceGroup(&group); err != nil {
+		t.Fatalf("failed to create balance group: %+v", err)
+	}
+	if err := op.CreateBalanceGroup(&model.BalanceGroup{MountPath: "/bal2", Members: group.Members[:1]}); err == nil {
+		t.Errorf("expected error when a storage is added to two groups")
+	}
+
+	names := mapset.NewSet[string]()
+	for _, obj := range op.GetStorageVirtualFilesByPath("/") {
+		names.Add(obj.GetName())
+	}
+	if !names.Contains("bal") || names.Contains("m1") || names.Contains("m2") {
+		t.Errorf("expected the members to be shown at /bal, got: %+v", names)
+	}
+
+	expect := func(ctx context.Context, mountPath string) {
+		t.Helper()
+		storage, actualPath, err := op.GetStorageAndActualPathFor(ctx, "/bal/x")
+		if err != nil {
+			t.Fatalf("failed get storage: %+v", err)
+		}
+		if storage.GetStorage().MountPath != mountPath || actualPath != "/x" {
+			t.Errorf("expected %s /x, got: %s %s", mountPath, storage.GetStorage().MountPath, actualPath)
+		}
+	}
+	expect(context.Background(), "/m1")
+	primary, _ := op.GetStorageByMountPath("/m1")
+	primary.GetStorage().SetStatus("down")
+	expect(context.Background(), "/m2")
+	primary.GetStorage().SetStatus(op.WORK)
+
+	group.Strategy = model.BalanceRoundRobin
+	group.StickyTTL = 60
+	if err := op.UpdateBalanceGroup(&group); err != nil {
+		t.Fatalf("failed to update balance group: %+v", err)
+	}
+	ctx := context.WithValue(context.Background(), conf.ClientIPKey, "10.0.0.1:1234")
+	storage, _, _ := op.GetStorageAndActualPathFor(ctx, "/bal")
+	for i := 0; i < 3; i++ {
+		expect(ctx, storage.GetStorage().MountPath)
+	}
+
+	// the concurrent requests are spread evenly
+	group.StickyTTL = 0
+	if err := op.UpdateBalanceGroup(&group); err != nil {
+		t.Fatalf("failed to update balance group: %+v", err)
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	picked := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			storage := op.GetBalancedStorage("/bal")
+			mu.Lock()
+			picked[storage.GetStorage().MountPath]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if picked["/m1"] != 50 || picked["/m2"] != 50 {
+		t.Errorf("expected the requests to be spread evenly, got: %+v", picked)
+	}
+
+	// the proxied downloads are counted as active until done
+	group.Strategy = model.BalanceLeastActive
+	if err := op.UpdateBalanceGroup(&group); err != nil {
+		t.Fatalf("failed to update balance group: %+v", err)
+	}
+	transfer := op.NewTransfer()
+	link, _, err := op.Link(context.WithValue(context.Background(), conf.TransferKey, transfer), primary, "/balance_test.go", model.LinkArgs{})
+	if err != nil {
+		t.Fatalf("failed get link: %+v", err)
+	}
+	if link.MFile != nil {
+		link.MFile.Close()
+	}
+	expect(context.Background(), "/m2")
+	transfer.Done()
+	expect(context.Background(), "/m1")
+
+	if err := op.DeleteBalanceGroupById(group.ID); err != nil {
+		t.Fatalf("failed to delete balance group: %+v", err)
+	}
+	if _, _, err := op.GetStorageAndActualPath("/bal/x"); err == nil {
+		t.Errorf("expected no storage at /bal after the group is deleted")
+	}
+}
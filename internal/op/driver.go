@@ -138,7 +138,36 @@ func getMainItems(config driver.Config) []driver.Item {
 		Type:     conf.TypeBool,
 		Default:  "false",
 		Required: true,
+	}, driver.Item{
+		Name:    "index_content",
+		Type:    conf.TypeBool,
+		Default: "false",
+		Help:    "Whether to index the content of the text, pdf and office files, which are downloaded while indexing",
 	})
+	if !config.NoUpload {
+		items = append(items, []driver.Item{{
+			Name:     "trash_policy",
+			Type:     conf.TypeSelect,
+			Options:  "global,enabled,disabled",
+			Default:  "global",
+			Required: true,
+			Help:     "Whether to move the removed objects into the trash folder, global means follow the setting",
+		}, {
+			Name: "trash_path",
+			Type: conf.TypeString,
+			Help: "The trash folder in this storage, the global one is used if empty",
+		}, {
+			Name:    "versions_keep",
+			Type:    conf.TypeNumber,
+			Default: "0",
+			Help:    "The number of old versions kept when a file is overwritten, 0 means disabled",
+		}, {
+			Name:    "versions_path",
+			Type:    conf.TypeString,
+			Default: "/.versions",
+			Help:    "The hidden folder in this storage to keep the old versions",
+		}}...)
+	}
 	items = append(items, driver.Item{
 		Name:     "enable_sign",
 		Type:     conf.TypeBool,
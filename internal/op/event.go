@@ -0,0 +1,41 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/event"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+type noEventKey struct{}
+
+// withoutEvents returns the ctx in which the object events are not published,
+// it's used by the internal operations such as moving into the trash or the versions folder
+func withoutEvents(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noEventKey{}, true)
+}
+
+// publishObjEvent publish the event of the object at path of the storage,
+// srcPath is the path before moved or renamed
+func publishObjEvent(ctx context.Context, typ string, storage driver.Driver, path, srcPath string, obj model.Obj) {
+	if silent, _ := ctx.Value(noEventKey{}).(bool); silent {
+		return
+	}
+	mountPath := GetVirtualMountPath(storage)
+	data := event.ObjData{Path: stdpath.Join(mountPath, path)}
+	if srcPath != "" {
+		data.SrcPath = stdpath.Join(mountPath, srcPath)
+	}
+	if obj != nil {
+		data.IsDir = obj.IsDir()
+		if !data.IsDir {
+			data.Size = obj.GetSize()
+		}
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		data.User = user.Username
+	}
+	event.Publish(typ, data)
+}
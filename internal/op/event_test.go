@@ -0,0 +1,55 @@
+package op_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/event"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func TestObjEvents(t *testing.T) {
+	root, _ := utils.Json.MarshalToString(map[string]string{"root_folder_path": t.TempDir()})
+	_, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: "/events", Addition: root})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/events")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	events := make(chan event.Event, 16)
+	unsubscribe := event.Subscribe(func(e event.Event) {
+		events <- e
+	}, event.ObjCreated, event.ObjUpdated, event.ObjDeleted)
+	defer unsubscribe()
+
+	expect := func(typ, path, srcPath string) {
+		t.Helper()
+		select {
+		case e := <-events:
+			data := e.Data.(event.ObjData)
+			if e.Type != typ || data.Path != path || data.SrcPath != srcPath {
+				t.Errorf("expected %s %s %s, got: %s %+v", typ, path, srcPath, e.Type, data)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected %s %s, got nothing", typ, path)
+		}
+	}
+	ctx := context.Background()
+	if err = op.MakeDir(ctx, storage, "/a"); err != nil {
+		t.Fatalf("failed make dir: %+v", err)
+	}
+	expect(event.ObjCreated, "/events/a", "")
+	if err = op.Rename(ctx, storage, "/a", "b"); err != nil {
+		t.Fatalf("failed rename: %+v", err)
+	}
+	expect(event.ObjUpdated, "/events/b", "/events/a")
+	if err = op.Remove(ctx, storage, "/b"); err != nil {
+		t.Fatalf("failed remove: %+v", err)
+	}
+	expect(event.ObjDeleted, "/events/b", "")
+}
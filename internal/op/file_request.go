@@ -0,0 +1,44 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+)
+
+func GetFileRequestByToken(token string) (*model.FileRequest, error) {
+	return db.GetFileRequestByToken(token)
+}
+
+func GetFileRequests(creatorId uint, pageIndex, pageSize int) ([]model.FileRequest, int64, error) {
+	return db.GetFileRequests(creatorId, pageIndex, pageSize)
+}
+
+func CreateFileRequest(r *model.FileRequest) error {
+	r.Token = random.String(16)
+	r.Path = utils.FixAndCleanPath(r.Path)
+	r.UploadCount = 0
+	r.Created = time.Now()
+	return db.CreateFileRequest(r)
+}
+
+// UpdateFileRequest update the limits of the file request, the path, creator and upload count are kept
+func UpdateFileRequest(r *model.FileRequest) error {
+	old, err := db.GetFileRequestByToken(r.Token)
+	if err != nil {
+		return err
+	}
+	r.Path, r.CreatorID, r.UploadCount, r.Created = old.Path, old.CreatorID, old.UploadCount, old.Created
+	return db.UpdateFileRequest(r)
+}
+
+func DeleteFileRequestByToken(token string) error {
+	return db.DeleteFileRequestByToken(token)
+}
+
+func IncreaseFileRequestUploadCount(token string) error {
+	return db.IncreaseFileRequestUploadCount(token)
+}
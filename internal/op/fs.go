@@ -4,25 +4,49 @@ import (
 	"context"
 	stdpath "path"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/event"
+	"github.com/alist-org/alist/v3/internal/listcache"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/internal/tracing"
 	"github.com/alist-org/alist/v3/pkg/generic_sync"
 	"github.com/alist-org/alist/v3/pkg/singleflight"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // In order to facilitate adding some other things before and after file op
 
-var listCache = cache.NewMemCache(cache.WithShards[[]model.Obj](64))
+var listCache = listcache.NewMemory()
 var listG singleflight.Group[[]model.Obj]
 
+// SetListCache replace the backend of list cache, it should be called before loading storages
+func SetListCache(c listcache.ListCache) {
+	old := listCache
+	listCache = c
+	_ = old.Close()
+}
+
+func CloseListCache() {
+	if err := listCache.Close(); err != nil {
+		log.Errorf("failed to close list cache: %+v", err)
+	}
+}
+
+func cacheExpiration(storage driver.Driver) time.Duration {
+	return time.Minute * time.Duration(storage.GetStorage().CacheExpiration)
+}
+
 func updateCacheObj(storage driver.Driver, path string, oldObj model.Obj, newObj model.Obj) {
 	key := Key(storage, path)
 	objs, ok := listCache.Get(key)
@@ -39,7 +63,7 @@ func updateCacheObj(storage driver.Driver, path string, oldObj model.Obj, newObj
 				break
 			}
 		}
-		listCache.Set(key, objs, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+		listCache.Set(key, objs, cacheExpiration(storage))
 	}
 }
 
@@ -53,7 +77,7 @@ func delCacheObj(storage driver.Driver, path string, obj model.Obj) {
 				break
 			}
 		}
-		listCache.Set(key, objs, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+		listCache.Set(key, objs, cacheExpiration(storage))
 	}
 }
 
@@ -66,6 +90,7 @@ func addCacheObj(storage driver.Driver, path string, newObj model.Obj) {
 		for i, obj := range objs {
 			if obj.GetName() == newObj.GetName() {
 				objs[i] = newObj
+				listCache.Set(key, objs, cacheExpiration(storage))
 				return
 			}
 		}
@@ -82,25 +107,42 @@ func addCacheObj(storage driver.Driver, path string, newObj model.Obj) {
 			log.Debug("addCacheObj: wait start sort")
 			debounce(func() {
 				log.Debug("addCacheObj: start sort")
-				model.SortFiles(objs, storage.GetStorage().OrderBy, storage.GetStorage().OrderDirection)
+				// the objs may have been changed or persisted by the backend since then
+				if objs, ok := listCache.Get(key); ok {
+					model.SortFiles(objs, storage.GetStorage().OrderBy, storage.GetStorage().OrderDirection)
+					listCache.Set(key, objs, cacheExpiration(storage))
+				}
 				addSortDebounceMap.Delete(key)
 			})
 		}
 
-		listCache.Set(key, objs, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+		listCache.Set(key, objs, cacheExpiration(storage))
 	}
 }
 
 func ClearCache(storage driver.Driver, path string) {
-	objs, ok := listCache.Get(Key(storage, path))
-	if ok {
-		for _, obj := range objs {
-			if obj.IsDir() {
-				ClearCache(storage, stdpath.Join(path, obj.GetName()))
-			}
-		}
-	}
-	listCache.Del(Key(storage, path))
+	listCache.DelPrefix(Key(storage, path))
+}
+
+// InvalidateCache drop the list cache of path and all its sub-folders in every storage whose
+// mount path overlaps path, it returns the mount paths of the storages affected
+func InvalidateCache(path string) []string {
+	path = utils.FixAndCleanPath(path)
+	mountPaths := make([]string, 0)
+	storagesMap.Range(func(mountPath string, storage driver.Driver) bool {
+		actualPath := GetVirtualMountPath(storage)
+		switch {
+		case utils.IsSubPath(actualPath, path):
+			listCache.DelPrefix(Key(storage, strings.TrimPrefix(path, actualPath)))
+		case utils.IsSubPath(path, actualPath):
+			listCache.DelPrefix(Key(storage, "/"))
+		default:
+			return true
+		}
+		mountPaths = append(mountPaths, mountPath)
+		return true
+	})
+	return mountPaths
 }
 
 func Key(storage driver.Driver, path string) string {
@@ -109,6 +151,24 @@ func Key(storage driver.Driver, path string) string {
 
 // List files in storage, not contains virtual file
 func List(ctx context.Context, storage driver.Driver, path string, args model.ListArgs) ([]model.Obj, error) {
+	ctx, done := startOp(ctx, "list", storage, path)
+	objs, err := list(ctx, storage, path, args)
+	done(err)
+	return objs, err
+}
+
+// startOp start the span of the op, the returned func record the metrics and end the span
+func startOp(ctx context.Context, op string, storage driver.Driver, path string) (context.Context, func(err error)) {
+	start := time.Now()
+	driverName, mountPath := storage.Config().Name, storage.GetStorage().MountPath
+	ctx, span := tracing.Start(ctx, "op."+op, append(tracing.Storage(mountPath, driverName), attribute.String("alist.path", path))...)
+	return ctx, func(err error) {
+		metrics.ObserveOp(op, driverName, mountPath, start, err)
+		tracing.End(span, err)
+	}
+}
+
+func list(ctx context.Context, storage driver.Driver, path string, args model.ListArgs) ([]model.Obj, error) {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
@@ -118,8 +178,10 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 	if !args.Refresh {
 		if files, ok := listCache.Get(key); ok {
 			log.Debugf("use cache when list %s", path)
+			metrics.ListCacheHits.Inc()
 			return files, nil
 		}
+		metrics.ListCacheMisses.Inc()
 	}
 	dir, err := GetUnwrap(ctx, storage, path)
 	if err != nil {
@@ -130,7 +192,9 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return nil, errors.WithStack(errs.NotFolder)
 	}
 	objs, err, _ := listG.Do(key, func() ([]model.Obj, error) {
+		done := startBackendCall(storage)
 		files, err := storage.List(ctx, dir, args)
+		done(err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to list objs")
 		}
@@ -144,8 +208,10 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		model.WrapObjsName(files)
 		// call hooks
 		go func(reqPath string, files []model.Obj) {
+			_, span := tracing.Start(ctx, "op.ObjsUpdateHook", attribute.String("alist.path", reqPath))
 			HandleObjsUpdateHook(reqPath, files)
-		}(utils.GetFullPath(storage.GetStorage().MountPath, path), files)
+			span.End()
+		}(stdpath.Join(GetVirtualMountPath(storage), path), files)
 
 		// sort objs
 		if storage.Config().LocalSort {
@@ -156,7 +222,7 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		if !storage.Config().NoCache {
 			if len(files) > 0 {
 				log.Debugf("set cache: %s => %+v", key, files)
-				listCache.Set(key, files, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+				listCache.Set(key, files, cacheExpiration(storage))
 			} else {
 				log.Debugf("del cache: %s", key)
 				listCache.Del(key)
@@ -169,6 +235,13 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 
 // Get object from list of files
 func Get(ctx context.Context, storage driver.Driver, path string) (model.Obj, error) {
+	ctx, done := startOp(ctx, "get", storage, path)
+	obj, err := get(ctx, storage, path)
+	done(err)
+	return obj, err
+}
+
+func get(ctx context.Context, storage driver.Driver, path string) (model.Obj, error) {
 	path = utils.FixAndCleanPath(path)
 	log.Debugf("op.Get %s", path)
 
@@ -248,6 +321,18 @@ var linkG singleflight.Group[*model.Link]
 
 // Link get link, if is an url. should have an expiry time
 func Link(ctx context.Context, storage driver.Driver, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
+	ctx, done := startOp(ctx, "link", storage, path)
+	link, file, err := _link(ctx, storage, path, args)
+	done(err)
+	if err == nil {
+		if t, ok := ctx.Value(conf.TransferKey).(*Transfer); ok {
+			t.start(storage)
+		}
+	}
+	return link, file, err
+}
+
+func _link(ctx context.Context, storage driver.Driver, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return nil, nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
@@ -263,7 +348,9 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return link, file, nil
 	}
 	fn := func() (*model.Link, error) {
+		done := startBackendCall(storage)
 		link, err := storage.Link(ctx, file, args)
+		done(err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed get link")
 		}
@@ -345,6 +432,9 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache
 				default:
 					return nil, errs.NotImplement
 				}
+				if err == nil {
+					publishObjEvent(ctx, event.ObjCreated, storage, path, "", &model.Object{Name: dirName, IsFolder: true})
+				}
 				return nil, errors.WithStack(err)
 			}
 			return nil, errors.WithMessage(err, "failed to check if dir exists")
@@ -399,6 +489,10 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		moveUsage(storage, srcPath, stdpath.Join(dstDirPath, srcRawObj.GetName()))
+		publishObjEvent(ctx, event.ObjUpdated, storage, stdpath.Join(dstDirPath, srcRawObj.GetName()), srcPath, srcRawObj)
+	}
 	return errors.WithStack(err)
 }
 
@@ -433,6 +527,10 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		moveUsage(storage, srcPath, stdpath.Join(srcDirPath, dstName))
+		publishObjEvent(ctx, event.ObjUpdated, storage, stdpath.Join(srcDirPath, dstName), srcPath, srcRawObj)
+	}
 	return errors.WithStack(err)
 }
 
@@ -471,10 +569,14 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		publishObjEvent(ctx, event.ObjCreated, storage, stdpath.Join(dstDirPath, srcObj.GetName()), "", srcObj)
+	}
 	return errors.WithStack(err)
 }
 
-func Remove(ctx context.Context, storage driver.Driver, path string) error {
+// RemoveDirectly remove the object permanently, regardless of the trash policy
+func RemoveDirectly(ctx context.Context, storage driver.Driver, path string) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
@@ -502,6 +604,8 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 			if rawObj.IsDir() {
 				ClearCache(storage, path)
 			}
+			releaseUsage(storage, path)
+			publishObjEvent(ctx, event.ObjDeleted, storage, path, "", rawObj)
 		}
 	default:
 		return errs.NotImplement
@@ -510,6 +614,13 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 }
 
 func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file model.FileStreamer, up driver.UpdateProgress, lazyCache ...bool) error {
+	ctx, done := startOp(ctx, "put", storage, stdpath.Join(dstDirPath, file.GetName()))
+	err := put(ctx, storage, dstDirPath, file, up, lazyCache...)
+	done(err)
+	return err
+}
+
+func put(ctx context.Context, storage driver.Driver, dstDirPath string, file model.FileStreamer, up driver.UpdateProgress, lazyCache ...bool) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
@@ -530,15 +641,28 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	tempName := file.GetName() + ".alist_to_delete"
 	tempPath := stdpath.Join(dstDirPath, tempName)
 	fi, err := GetUnwrap(ctx, storage, dstPath)
-	if err == nil {
+	// the old obj is replaced, so the internal operations on it are not published
+	silentCtx := withoutEvents(ctx)
+	var versionPath string
+	if err == nil && !fi.IsDir() && fi.GetSize() > 0 {
+		// keep the old obj as a version instead of overwriting it
+		versionPath, err = saveVersion(silentCtx, storage, dstPath)
+		if err != nil {
+			return errors.WithMessage(err, "while uploading, failed to save the version of existing file")
+		}
+		if versionPath != "" {
+			fi = nil
+		}
+	}
+	if err == nil && fi != nil {
 		if fi.GetSize() == 0 {
-			err = Remove(ctx, storage, dstPath)
+			err = RemoveDirectly(silentCtx, storage, dstPath)
 			if err != nil {
 				return errors.WithMessagef(err, "while uploading, failed remove existing file which size = 0")
 			}
 		} else if storage.Config().NoOverwriteUpload {
 			// try to rename old obj
-			err = Rename(ctx, storage, dstPath, tempName)
+			err = Rename(silentCtx, storage, dstPath, tempName)
 			if err != nil {
 				return err
 			}
@@ -580,16 +704,31 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 		return errs.NotImplement
 	}
 	log.Debugf("put file [%s] done", file.GetName())
+	if versionPath != "" {
+		if err != nil {
+			revertVersion(silentCtx, storage, dstPath, versionPath)
+		} else {
+			linkCache.Del(Key(storage, dstPath))
+			pruneVersions(silentCtx, storage, dstPath)
+		}
+	}
+	if err == nil {
+		typ := event.ObjCreated
+		if fi != nil || versionPath != "" {
+			typ = event.ObjUpdated
+		}
+		publishObjEvent(ctx, typ, storage, dstPath, "", file)
+	}
 	if storage.Config().NoOverwriteUpload && fi != nil && fi.GetSize() > 0 {
 		if err != nil {
 			// upload failed, recover old obj
-			err := Rename(ctx, storage, tempPath, file.GetName())
+			err := Rename(silentCtx, storage, tempPath, file.GetName())
 			if err != nil {
 				log.Errorf("failed recover old obj: %+v", err)
 			}
 		} else {
 			// upload success, remove old obj
-			err := Remove(ctx, storage, tempPath)
+			err := RemoveDirectly(silentCtx, storage, tempPath)
 			if err != nil {
 				return err
 			} else {
@@ -637,6 +776,9 @@ func PutURL(ctx context.Context, storage driver.Driver, dstDirPath, dstName, url
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		publishObjEvent(ctx, event.ObjCreated, storage, stdpath.Join(dstDirPath, dstName), "", &model.Object{Name: dstName})
+	}
 	log.Debugf("put url [%s](%s) done", dstName, url)
 	return errors.WithStack(err)
 }
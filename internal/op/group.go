@@ -0,0 +1,170 @@
+package op
+
+import (
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// loadGroups set the groups, the group metas and the ACLs of the user
+func loadGroups(u *model.User) error {
+	groups, err := db.GetGroupsByUserId(u.ID)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(groups))
+	for _, g := range groups {
+		if !g.Disabled {
+			ids = append(ids, g.ID)
+		}
+	}
+	metas, err := db.GetGroupMetasByGroupIds(ids)
+	if err != nil {
+		return err
+	}
+	u.SetGroups(groups, metas)
+	acls, err := db.GetACLsOfUser(u.ID, ids)
+	if err != nil {
+		return err
+	}
+	u.SetACLs(acls)
+	return nil
+}
+
+// clearUserCache is called when the groups or the ACLs are changed, since they are cached with the users
+func clearUserCache() {
+	userCache.Clear()
+	adminUser = nil
+	guestUser = nil
+}
+
+func checkGroupIds(ids []uint) error {
+	for _, id := range ids {
+		if _, err := db.GetGroupById(id); err != nil {
+			return errors.WithMessagef(err, "group %d", id)
+		}
+	}
+	return nil
+}
+
+func GetGroupById(id uint) (*model.Group, error) {
+	return db.GetGroupById(id)
+}
+
+func GetGroups(pageIndex, pageSize int) ([]model.Group, int64, error) {
+	return db.GetGroups(pageIndex, pageSize)
+}
+
+// GetGroupIdsByNames returns the ids of the groups with the names separated by comma,
+// the unknown names are ignored
+func GetGroupIdsByNames(names string) ([]uint, error) {
+	var list []string
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			list = append(list, name)
+		}
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	groups, err := db.GetGroupsByNames(list)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(groups))
+	for _, g := range groups {
+		ids = append(ids, g.ID)
+	}
+	return ids, nil
+}
+
+func CreateGroup(g *model.Group) error {
+	g.BasePath = utils.FixAndCleanPath(g.BasePath)
+	return db.CreateGroup(g)
+}
+
+func UpdateGroup(g *model.Group) error {
+	if _, err := db.GetGroupById(g.ID); err != nil {
+		return err
+	}
+	g.BasePath = utils.FixAndCleanPath(g.BasePath)
+	if err := db.UpdateGroup(g); err != nil {
+		return err
+	}
+	clearUserCache()
+	return nil
+}
+
+func DeleteGroupById(id uint) error {
+	if err := db.DeleteGroupById(id); err != nil {
+		return err
+	}
+	clearUserCache()
+	return nil
+}
+
+func GetGroupMetas(groupId uint) ([]model.GroupMeta, error) {
+	return db.GetGroupMetasByGroupIds([]uint{groupId})
+}
+
+func GetGroupMetaById(id uint) (*model.GroupMeta, error) {
+	return db.GetGroupMetaById(id)
+}
+
+func CreateGroupMeta(m *model.GroupMeta) error {
+	if _, err := db.GetGroupById(m.GroupID); err != nil {
+		return err
+	}
+	m.Path = utils.FixAndCleanPath(m.Path)
+	if err := db.CreateGroupMeta(m); err != nil {
+		return err
+	}
+	clearUserCache()
+	return nil
+}
+
+func UpdateGroupMeta(m *model.GroupMeta) error {
+	old, err := db.GetGroupMetaById(m.ID)
+	if err != nil {
+		return err
+	}
+	m.GroupID = old.GroupID
+	m.Path = utils.FixAndCleanPath(m.Path)
+	if err = db.UpdateGroupMeta(m); err != nil {
+		return err
+	}
+	clearUserCache()
+	return nil
+}
+
+func DeleteGroupMetaById(id uint) error {
+	if err := db.DeleteGroupMetaById(id); err != nil {
+		return err
+	}
+	clearUserCache()
+	return nil
+}
+
+// GetNearestMetaForUser returns the nearest meta of the path with the overrides of the user's groups,
+// the group meta is used if it's as near as or nearer than the global one
+func GetNearestMetaForUser(user *model.User, path string) (*model.Meta, error) {
+	meta, err := GetNearestMeta(path)
+	if user == nil {
+		return meta, err
+	}
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return nil, err
+	}
+	groupMeta := user.NearestGroupMeta(path)
+	if groupMeta == nil {
+		return meta, err
+	}
+	if meta != nil && !utils.PathEqual(meta.Path, groupMeta.Path) && utils.IsSubPath(groupMeta.Path, meta.Path) {
+		return meta, nil
+	}
+	return groupMeta.Override(meta), nil
+}
@@ -0,0 +1,119 @@
+package op_test
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
+)
+
+func TestUserGroups(t *testing.T) {
+	read := &model.Group{Name: "read", BasePath: "/team/a", Permission: 1 << 8, Quota: model.Quota{QuotaFiles: 2}}
+	write := &model.Group{Name: "write", BasePath: "/team/b", Permission: 1 << 3}
+	for _, g := range []*model.Group{read, write} {
+		if err := op.CreateGroup(g); err != nil {
+			t.Fatalf("failed to create group: %+v", err)
+		}
+	}
+	if err := op.CreateGroupMeta(&model.GroupMeta{GroupID: write.ID, Path: "/team/b/docs", Write: true, WSub: true}); err != nil {
+		t.Fatalf("failed to create group meta: %+v", err)
+	}
+	user := &model.User{Username: "member", BasePath: "/", GroupIDs: []uint{read.ID, write.ID}}
+	if err := op.CreateUser(user); err != nil {
+		t.Fatalf("failed to create user: %+v", err)
+	}
+	user, err := op.GetUserByName("member")
+	if err != nil {
+		t.Fatalf("failed to get user: %+v", err)
+	}
+	if !user.CanWebdavRead() || !user.CanWrite() || user.CanRemove() {
+		t.Errorf("permission should be the union of the groups, got %b", user.GetPermission())
+	}
+	// the base paths of the groups are separate roots, the paths between them are denied
+	if !user.CanAccessPath("/team") || !user.CanAccessPath("/team/a/x") || !user.InBasePath("/team/b") || user.InBasePath("/team") {
+		t.Errorf("the paths in or to the base paths of the groups should be accessed")
+	}
+	for _, path := range []string{"/team/c", "/team/ab", "/other"} {
+		if user.CanAccessPath(path) || user.InBasePath(path) {
+			t.Errorf("%s out of the base paths of the groups should be denied", path)
+		}
+	}
+	if _, err = user.JoinPath("/c"); !errors.Is(err, errs.PermissionDenied) {
+		t.Errorf("/team/c should be denied, got %+v", err)
+	}
+	if paths := user.LimitedPaths(); len(paths) != 2 {
+		t.Errorf("the user should be limited to the base paths of the groups, got %+v", paths)
+	}
+	meta, err := op.GetNearestMetaForUser(user, "/team/b/docs/x")
+	if err != nil || meta == nil || !meta.Write {
+		t.Errorf("group meta should be applied, got %+v, %+v", meta, err)
+	}
+
+	for _, path := range []string{"/group/a", "/group/b"} {
+		if err = op.ReserveQuota(user, 10); err != nil {
+			t.Errorf("quota should not be exceeded: %+v", err)
+		}
+		db.SetUploadedFile(&model.UploadedFile{Path: path, UserID: user.ID, Size: 10})
+	}
+	if err = op.CheckQuota(user, 10); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("quota of the group should be exceeded, got %+v", err)
+	}
+	if err = op.ReserveQuota(user, 10); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("quota of the group should be exceeded, got %+v", err)
+	}
+
+	read.Disabled = true
+	if err = op.UpdateGroup(read); err != nil {
+		t.Fatalf("failed to update group: %+v", err)
+	}
+	user, _ = op.GetUserByName("member")
+	if user.CanWebdavRead() || user.GetBasePath() != "/team/b" {
+		t.Errorf("disabled group should be ignored")
+	}
+	if err = op.CheckQuota(user, 10); err != nil {
+		t.Errorf("quota of disabled group should be ignored: %+v", err)
+	}
+}
+
+func TestUserACLs(t *testing.T) {
+	group := &model.Group{Name: "acl"}
+	if err := op.CreateGroup(group); err != nil {
+		t.Fatalf("failed to create group: %+v", err)
+	}
+	user := &model.User{Username: "acl_member", BasePath: "/", Permission: 1 << 3, GroupIDs: []uint{group.ID}}
+	if err := op.CreateUser(user); err != nil {
+		t.Fatalf("failed to create user: %+v", err)
+	}
+	acls := []*model.ACL{
+		{UserID: user.ID, Path: "/data/public", Permission: 0},
+		{GroupID: group.ID, Path: "/data/public/upload", Permission: 1 << 3},
+	}
+	for _, a := range acls {
+		if err := op.CreateACL(a); err != nil {
+			t.Fatalf("failed to create acl: %+v", err)
+		}
+	}
+	user, err := op.GetUserByName("acl_member")
+	if err != nil {
+		t.Fatalf("failed to get user: %+v", err)
+	}
+	if !user.CanAccessPath("/data") || !user.CanAccessPath("/data/public/a") || user.CanAccessPath("/data/private") {
+		t.Errorf("only the paths of the ACLs and the parents of them should be accessible")
+	}
+	if _, err = user.JoinPath("/data/private"); !errors.Is(err, errs.PermissionDenied) {
+		t.Errorf("join path out of the ACLs should be denied, got %+v", err)
+	}
+	if user.At("/data/public/a").CanWrite() || !user.At("/data/public/upload/a").CanWrite() {
+		t.Errorf("permission should be replaced by the nearest ACL")
+	}
+	if err = op.DeleteACLById(acls[1].ID); err != nil {
+		t.Fatalf("failed to delete acl: %+v", err)
+	}
+	user, _ = op.GetUserByName("acl_member")
+	if user.At("/data/public/upload/a").CanWrite() {
+		t.Errorf("deleted ACL should not be applied")
+	}
+}
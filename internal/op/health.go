@@ -0,0 +1,254 @@
+package op
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	healthCheckTimeout = 2 * time.Minute
+	// the failed storages are re-initialized after healthRetryMin, and the delay doubles after each failure
+	healthRetryMin    = time.Minute
+	healthRetryMax    = 30 * time.Minute
+	healthHistoryKeep = 100
+)
+
+type healthState struct {
+	sync.Mutex
+	checking  bool
+	failures  int
+	lastCheck *time.Time
+	nextCheck time.Time
+}
+
+var healthStates generic_sync.MapOf[driver.Driver, *healthState]
+
+func getHealthState(storage driver.Driver, interval time.Duration) *healthState {
+	state, ok := healthStates.Load(storage)
+	if ok {
+		return state
+	}
+	// the storage has just been initialized, so it needn't be checked at once
+	next := time.Now().Add(healthRetryMin)
+	if storage.GetStorage().Status == WORK {
+		next = time.Now().Add(interval)
+	}
+	state, _ = healthStates.LoadOrStore(storage, &healthState{nextCheck: next})
+	return state
+}
+
+func dropHealthState(storage driver.Driver) {
+	healthStates.Delete(storage)
+}
+
+// HealthCheckInterval returns the interval of the health check by the setting, 0 means disabled
+func HealthCheckInterval() time.Duration {
+	item, _ := GetSettingItemByKey(conf.StorageHealthInterval)
+	if item == nil {
+		return 0
+	}
+	minutes, err := strconv.Atoi(item.Value)
+	if err != nil || minutes <= 0 {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+func healthRetryDelay(failures int) time.Duration {
+	delay := healthRetryMin
+	for i := 1; i < failures && delay < healthRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, healthRetryMax)
+}
+
+// CheckStoragesHealth check the storages which are due in background, it should be called every minute
+func CheckStoragesHealth() {
+	interval := HealthCheckInterval()
+	if interval == 0 {
+		return
+	}
+	now := time.Now()
+	for _, storage := range GetAllStorages() {
+		state := getHealthState(storage, interval)
+		state.Lock()
+		due := !state.checking && !now.Before(state.nextCheck)
+		state.Unlock()
+		if due {
+			go func() {
+				_ = checkStorageHealth(context.Background(), storage, interval)
+			}()
+		}
+	}
+}
+
+// CheckStorageHealth check the storage immediately and returns its health
+func CheckStorageHealth(ctx context.Context, mountPath string) (*model.StorageHealth, error) {
+	storage, err := GetStorageByMountPath(mountPath)
+	if err != nil {
+		return nil, err
+	}
+	interval := HealthCheckInterval()
+	if interval == 0 {
+		interval = healthRetryMax
+	}
+	if err = checkStorageHealth(ctx, storage, interval); errors.Is(err, errHealthChecking) {
+		return nil, err
+	}
+	return getStorageHealth(storage), nil
+}
+
+var errHealthChecking = errors.New("the storage is being checked")
+
+// checkStorageHealth probe the healthy storage, or re-initialize the failed one,
+// the health logs are saved and the hooks are called if the health is changed
+func checkStorageHealth(ctx context.Context, storage driver.Driver, interval time.Duration) error {
+	state := getHealthState(storage, interval)
+	state.Lock()
+	if state.checking {
+		state.Unlock()
+		return errors.WithStack(errHealthChecking)
+	}
+	state.checking = true
+	state.Unlock()
+	defer func() {
+		state.Lock()
+		state.checking = false
+		state.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	healthy := storage.GetStorage().Status == WORK
+	var err error
+	if healthy {
+		err = probeStorage(ctx, storage)
+	} else {
+		err = reinitStorage(ctx, storage)
+	}
+
+	now := time.Now()
+	state.Lock()
+	state.lastCheck = &now
+	if err == nil {
+		state.failures = 0
+		state.nextCheck = now.Add(interval)
+	} else {
+		state.failures++
+		state.nextCheck = now.Add(healthRetryDelay(state.failures))
+	}
+	state.Unlock()
+
+	mountPath := storage.GetStorage().MountPath
+	switch {
+	case healthy && err != nil:
+		log.Warnf("storage %s is down: %+v", mountPath, err)
+		storage.GetStorage().SetStatus(err.Error())
+		MustSaveDriverStorage(storage)
+		saveHealthLog(storage, model.StorageDown, err.Error())
+		go callStorageHealthHooks(storage, false, err)
+	case !healthy && err == nil:
+		log.Infof("storage %s is recovered", mountPath)
+		saveHealthLog(storage, model.StorageRecovered, "")
+		go callStorageHealthHooks(storage, true, nil)
+	case !healthy && err != nil:
+		log.Debugf("failed to re-initialize storage %s: %+v", mountPath, err)
+		saveHealthLog(storage, model.StorageReinitFailed, err.Error())
+	}
+	return err
+}
+
+// probeStorage check the storage by driver.HealthChecker, or list the root folder if not implemented
+func probeStorage(ctx context.Context, storage driver.Driver) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v", r)
+		}
+	}()
+	if checker, ok := storage.(driver.HealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+	root, err := GetUnwrap(ctx, storage, "/")
+	if err != nil {
+		return err
+	}
+	_, err = storage.List(ctx, root, model.ListArgs{})
+	return errors.WithStack(err)
+}
+
+// reinitStorage drop the failed storage and initialize it again, then probe it
+func reinitStorage(ctx context.Context, storage driver.Driver) error {
+	// the storage may have been updated or deleted in the meantime
+	if current, err := GetStorageByMountPath(storage.GetStorage().MountPath); err != nil || current != storage {
+		return errors.New("the storage has been changed")
+	}
+	stopWatch(storage)
+	if err := storage.Drop(ctx); err != nil {
+		log.Warnf("failed drop storage %s: %+v", storage.GetStorage().MountPath, err)
+	}
+	if err := initStorage(ctx, *storage.GetStorage(), storage); err != nil {
+		return err
+	}
+	return probeStorage(ctx, storage)
+}
+
+func saveHealthLog(storage driver.Driver, event, message string) {
+	s := storage.GetStorage()
+	err := db.CreateStorageHealthLog(&model.StorageHealthLog{
+		StorageID: s.ID,
+		MountPath: s.MountPath,
+		Time:      time.Now(),
+		Event:     event,
+		Message:   message,
+	})
+	if err == nil {
+		err = db.PruneStorageHealthLogs(s.ID, healthHistoryKeep)
+	}
+	if err != nil {
+		log.Errorf("failed save storage health log: %+v", err)
+	}
+}
+
+func getStorageHealth(storage driver.Driver) *model.StorageHealth {
+	s := storage.GetStorage()
+	health := &model.StorageHealth{
+		StorageID: s.ID,
+		MountPath: s.MountPath,
+		Driver:    s.Driver,
+		Status:    s.Status,
+		Healthy:   s.Status == WORK,
+	}
+	if state, ok := healthStates.Load(storage); ok {
+		state.Lock()
+		health.Failures = state.failures
+		health.LastCheck = state.lastCheck
+		next := state.nextCheck
+		health.NextCheck = &next
+		state.Unlock()
+	}
+	return health
+}
+
+// GetStoragesHealth returns the health of all loaded storages
+func GetStoragesHealth() []model.StorageHealth {
+	storages := GetAllStorages()
+	res := make([]model.StorageHealth, 0, len(storages))
+	for _, storage := range storages {
+		res = append(res, *getStorageHealth(storage))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].MountPath < res[j].MountPath
+	})
+	return res
+}
@@ -0,0 +1,57 @@
+package op_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func TestStorageHealth(t *testing.T) {
+	dir := t.TempDir()
+	addition, _ := utils.Json.MarshalToString(map[string]string{"root_folder_path": dir})
+	id, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: "/health",
+		Addition:  addition,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	check := func(healthy bool) {
+		t.Helper()
+		health, err := op.CheckStorageHealth(context.Background(), "/health")
+		if err != nil {
+			t.Fatalf("failed to check storage: %+v", err)
+		}
+		if health.Healthy != healthy {
+			t.Fatalf("expected healthy %v, got %+v", healthy, health)
+		}
+	}
+	check(true)
+	if err := os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	check(false)
+	if err := os.Mkdir(dir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	check(true)
+
+	logs, _, err := db.GetStorageHealthLogs(id, 1, 10)
+	if err != nil {
+		t.Fatalf("failed to get health logs: %+v", err)
+	}
+	var events []string
+	for _, l := range logs {
+		events = append(events, l.Event)
+	}
+	expected := []string{model.StorageRecovered, model.StorageDown}
+	if !utils.SliceEqual(events, expected) {
+		t.Errorf("expected: %+v, got: %+v", expected, events)
+	}
+}
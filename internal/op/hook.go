@@ -29,6 +29,22 @@ func HandleObjsUpdateHook(parent string, objs []model.Obj) {
 	}
 }
 
+// DirChangedHook is called when the children of dir are changed outside of alist,
+// which is reported by the drivers implementing driver.Watcher
+type DirChangedHook = func(dir string)
+
+var dirChangedHooks = make([]DirChangedHook, 0)
+
+func RegisterDirChangedHook(hook DirChangedHook) {
+	dirChangedHooks = append(dirChangedHooks, hook)
+}
+
+func HandleDirChangedHook(dir string) {
+	for _, hook := range dirChangedHooks {
+		hook(dir)
+	}
+}
+
 // Setting
 type SettingItemHook func(item *model.SettingItem) error
 
@@ -109,3 +125,19 @@ func callStorageHooks(typ string, storage driver.Driver) {
 func RegisterStorageHook(hook StorageHook) {
 	storageHooks = append(storageHooks, hook)
 }
+
+// StorageHealthHook is called when the health check finds a storage down or recovered,
+// err is the reason why the storage is down
+type StorageHealthHook func(storage driver.Driver, healthy bool, err error)
+
+var storageHealthHooks = make([]StorageHealthHook, 0)
+
+func callStorageHealthHooks(storage driver.Driver, healthy bool, err error) {
+	for _, hook := range storageHealthHooks {
+		hook(storage, healthy, err)
+	}
+}
+
+func RegisterStorageHealthHook(hook StorageHealthHook) {
+	storageHealthHooks = append(storageHealthHooks, hook)
+}
@@ -1,6 +1,8 @@
 package op
 
 import (
+	"context"
+
 	"github.com/alist-org/alist/v3/internal/errs"
 	stdpath "path"
 	"strings"
@@ -13,8 +15,14 @@ import (
 // GetStorageAndActualPath Get the corresponding storage and actual path
 // for path: remove the mount path prefix and join the actual root folder if exists
 func GetStorageAndActualPath(rawPath string) (storage driver.Driver, actualPath string, err error) {
+	return GetStorageAndActualPathFor(context.Background(), rawPath)
+}
+
+// GetStorageAndActualPathFor is GetStorageAndActualPath for the client in ctx,
+// so that the client can stick to the same member of the balance group
+func GetStorageAndActualPathFor(ctx context.Context, rawPath string) (storage driver.Driver, actualPath string, err error) {
 	rawPath = utils.FixAndCleanPath(rawPath)
-	storage = GetBalancedStorage(rawPath)
+	storage = getBalancedStorage(rawPath, clientIP(ctx))
 	if storage == nil {
 		if rawPath == "/" {
 			err = errs.NewErr(errs.StorageNotFound, "please add a storage first")
@@ -24,7 +32,7 @@ func GetStorageAndActualPath(rawPath string) (storage driver.Driver, actualPath
 		return
 	}
 	log.Debugln("use storage: ", storage.GetStorage().MountPath)
-	mountPath := utils.GetActualMountPath(storage.GetStorage().MountPath)
+	mountPath := GetVirtualMountPath(storage)
 	actualPath = utils.FixAndCleanPath(strings.TrimPrefix(rawPath, mountPath))
 	return
 }
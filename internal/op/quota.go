@@ -0,0 +1,142 @@
+package op
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetUserUsage(userId uint) (*model.UserUsage, error) {
+	return db.GetUserUsage(userId)
+}
+
+// CheckQuota check whether the user can upload a file of the size by the quotas of the user and the groups,
+// nil user means the upload is not done by any user, e.g. by the drivers
+func CheckQuota(user *model.User, size int64) error {
+	if user == nil {
+		return nil
+	}
+	size = max(size, 0)
+	if user.QuotaBytes > 0 || user.QuotaFiles > 0 {
+		usage, err := db.GetUserUsage(user.ID)
+		if err != nil {
+			return err
+		}
+		if user.Quota.Exceeded(usage, size, 1) {
+			return errors.WithStack(errs.QuotaExceeded)
+		}
+	}
+	groups, err := getQuotaGroups(user)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		usage, err := db.GetGroupUsage(g.ID)
+		if err != nil {
+			return err
+		}
+		if g.Quota.Exceeded(usage, size, 1) {
+			return errors.Wrapf(errs.QuotaExceeded, "quota of group [%s]", g.Name)
+		}
+	}
+	return nil
+}
+
+// getQuotaGroups returns the enabled groups of the user which have quotas,
+// the quota of a group is shared by all the members
+func getQuotaGroups(user *model.User) ([]model.Group, error) {
+	if len(user.GroupIDs) == 0 {
+		return nil, nil
+	}
+	groups, err := db.GetGroupsByUserId(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	var res []model.Group
+	for _, g := range groups {
+		if g.Disabled || g.QuotaBytes <= 0 && g.QuotaFiles <= 0 {
+			continue
+		}
+		res = append(res, g)
+	}
+	return res, nil
+}
+
+// ReserveQuota check the quotas like CheckQuota and add the file to the usage of the user at the same time,
+// so that the concurrent uploads can't exceed the quotas together. either AddUsage or ReleaseQuota
+// must be called with the same size after uploading
+func ReserveQuota(user *model.User, size int64) error {
+	if user == nil {
+		return nil
+	}
+	groups, err := getQuotaGroups(user)
+	if err != nil {
+		return err
+	}
+	groupIds := make([]uint, len(groups))
+	for i, g := range groups {
+		groupIds[i] = g.ID
+	}
+	return db.ReserveUsage(user.ID, groupIds, max(size, 0), func(userUsage *model.UserUsage, groupUsages map[uint]*model.UserUsage) error {
+		// the file has been added to the usages
+		if user.Quota.Exceeded(userUsage, 0, 0) {
+			return errors.WithStack(errs.QuotaExceeded)
+		}
+		for _, g := range groups {
+			if g.Quota.Exceeded(groupUsages[g.ID], 0, 0) {
+				return errors.Wrapf(errs.QuotaExceeded, "quota of group [%s]", g.Name)
+			}
+		}
+		return nil
+	})
+}
+
+// ReleaseQuota release the quota reserved by ReserveQuota when the upload is failed
+func ReleaseQuota(user *model.User, size int64) {
+	if user == nil {
+		return
+	}
+	if err := db.ReleaseUsage(user.ID, max(size, 0)); err != nil {
+		log.Errorf("failed release quota of user [%s]: %+v", user.Username, err)
+	}
+}
+
+// AddUsage record the file uploaded by the user at the actual path of the storage with the quota reserved
+// by ReserveQuota, the failure is only logged since the file has been uploaded
+func AddUsage(user *model.User, storage driver.Driver, path string, size int64) {
+	if user == nil {
+		return
+	}
+	err := db.SetUploadedFile(&model.UploadedFile{
+		StorageID: storage.GetStorage().ID,
+		Path:      utils.FixAndCleanPath(path),
+		UserID:    user.ID,
+		Size:      max(size, 0),
+	})
+	if err != nil {
+		log.Errorf("failed add usage of user [%s]: %+v", user.Username, err)
+	}
+}
+
+// moveUsage keep the usage of the moved files, the uploaders are still charged for them
+func moveUsage(storage driver.Driver, srcPath, dstPath string) {
+	if err := db.MoveUploadedFiles(storage.GetStorage().ID, srcPath, dstPath); err != nil {
+		log.Errorf("failed move usage of %s to %s: %+v", srcPath, dstPath, err)
+	}
+}
+
+// releaseUsage release the usage of the removed file or the files in the removed folder from the uploaders
+func releaseUsage(storage driver.Driver, path string) {
+	if err := db.DeleteUploadedFiles(storage.GetStorage().ID, path); err != nil {
+		log.Errorf("failed release usage of %s: %+v", path, err)
+	}
+}
+
+// ResetUserUsage forget the files uploaded by the user, so that the usage is zero
+func ResetUserUsage(userId uint) error {
+	return db.DeleteUserUsage(userId)
+}
@@ -0,0 +1,159 @@
+package op_test
+
+import (
+	"context"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+func TestUsage(t *testing.T) {
+	root, _ := utils.Json.MarshalToString(map[string]string{"root_folder_path": t.TempDir()})
+	trashDir := t.TempDir()
+	trashRoot, _ := utils.Json.MarshalToString(map[string]string{"root_folder_path": trashDir})
+	ctx := context.Background()
+	if _, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/usage", Addition: root}); err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	_, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/usage_trash", Addition: trashRoot, Trash: model.Trash{TrashPolicy: "enabled", TrashPath: "/.trash"}})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	users := make(map[string]*model.User)
+	for _, name := range []string{"uploader", "other"} {
+		user := &model.User{Username: "usage_" + name, BasePath: "/", Permission: 0xff, Quota: model.Quota{QuotaBytes: 10}}
+		if err := op.CreateUser(user); err != nil {
+			t.Fatalf("failed to create user: %+v", err)
+		}
+		users[name] = user
+	}
+	userCtx := func(name string) context.Context {
+		return context.WithValue(ctx, "user", users[name])
+	}
+	put := func(name, path, content string) error {
+		t.Helper()
+		dir, file := stdpath.Split(path)
+		return fs.PutDirectly(userCtx(name), dir, &stream.FileStream{
+			Obj:    &model.Object{Name: file, Size: int64(len(content))},
+			Reader: strings.NewReader(content),
+		})
+	}
+	expect := func(name string, bytes, files int64) {
+		t.Helper()
+		usage, err := op.GetUserUsage(users[name].ID)
+		if err != nil {
+			t.Fatalf("failed get usage: %+v", err)
+		}
+		if usage.UsedBytes != bytes || usage.UsedFiles != files {
+			t.Errorf("expected usage of %s %d bytes %d files, got %+v", name, bytes, files, usage)
+		}
+	}
+
+	// the usage is released from the uploader no matter who removes the file
+	if err = put("uploader", "/usage/a.txt", "1234"); err != nil {
+		t.Fatalf("failed to put: %+v", err)
+	}
+	expect("uploader", 4, 1)
+	if err = fs.Remove(userCtx("other"), "/usage/a.txt"); err != nil {
+		t.Fatalf("failed to remove: %+v", err)
+	}
+	expect("uploader", 0, 0)
+	expect("other", 0, 0)
+
+	// the usage of the overwritten file is replaced
+	if err = put("uploader", "/usage/b.txt", "123456"); err != nil {
+		t.Fatalf("failed to put: %+v", err)
+	}
+	if err = put("uploader", "/usage/b.txt", "12"); err != nil {
+		t.Fatalf("failed to overwrite: %+v", err)
+	}
+	expect("uploader", 2, 1)
+	if err = put("other", "/usage/b.txt", "123"); err != nil {
+		t.Fatalf("failed to overwrite: %+v", err)
+	}
+	expect("uploader", 0, 0)
+	expect("other", 3, 1)
+
+	// the files are followed when moved and released with the folder
+	if err = put("uploader", "/usage/dir/c.txt", "123"); err != nil {
+		t.Fatalf("failed to put: %+v", err)
+	}
+	if err = put("uploader", "/usage/dir/d.txt", "1234"); err != nil {
+		t.Fatalf("failed to put: %+v", err)
+	}
+	if err = put("uploader", "/usage/e.txt", "1234"); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("the quota should be exceeded, got %+v", err)
+	}
+	if err = fs.Rename(userCtx("uploader"), "/usage/dir", "renamed"); err != nil {
+		t.Fatalf("failed to rename: %+v", err)
+	}
+	expect("uploader", 7, 2)
+	if err = fs.Remove(userCtx("uploader"), "/usage/renamed"); err != nil {
+		t.Fatalf("failed to remove: %+v", err)
+	}
+	expect("uploader", 0, 0)
+
+	// the files in the trash are still stored, so they are counted until purged
+	if err = put("uploader", "/usage_trash/dir/f.txt", "12345"); err != nil {
+		t.Fatalf("failed to put: %+v", err)
+	}
+	if err = fs.Remove(userCtx("other"), "/usage_trash/dir"); err != nil {
+		t.Fatalf("failed to remove: %+v", err)
+	}
+	if utils.Exists(filepath.Join(trashDir, "dir")) {
+		t.Fatalf("the folder should be moved into trash")
+	}
+	expect("uploader", 5, 1)
+	items, _, err := op.GetTrashItems(1, model.MaxInt)
+	if err != nil {
+		t.Fatalf("failed get trash items: %+v", err)
+	}
+	for _, item := range items {
+		if item.MountPath == "/usage_trash" {
+			if err = op.PurgeTrashItem(ctx, item.ID); err != nil {
+				t.Fatalf("failed to purge: %+v", err)
+			}
+		}
+	}
+	expect("uploader", 0, 0)
+
+	// the reserved quota is counted until the upload is done or failed
+	if err = op.ReserveQuota(users["uploader"], 6); err != nil {
+		t.Fatalf("failed to reserve quota: %+v", err)
+	}
+	if err = op.ReserveQuota(users["uploader"], 6); !errors.Is(err, errs.QuotaExceeded) {
+		t.Errorf("the reserved quota should be counted, got %+v", err)
+	}
+	expect("uploader", 6, 1)
+	op.ReleaseQuota(users["uploader"], 6)
+	expect("uploader", 0, 0)
+
+	// the files in the root folder are released with the storage
+	if err = put("other", "/usage/g.txt", "123"); err != nil {
+		t.Fatalf("failed to put: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/usage")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	if err = db.DeleteUploadedFiles(storage.GetStorage().ID, "/"); err != nil {
+		t.Fatalf("failed to delete uploaded files: %+v", err)
+	}
+	expect("other", 0, 0)
+
+	// the usage can be reset by admin, e.g. the files are removed outside
+	if err = op.ResetUserUsage(users["other"].ID); err != nil {
+		t.Fatalf("failed to reset usage: %+v", err)
+	}
+	expect("other", 0, 0)
+}
@@ -0,0 +1,253 @@
+package op_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func TestSearchNodeFilters(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	nodes := []model.SearchNode{
+		{Parent: "/search", Name: "docs", IsDir: true, Modified: day, ObjType: conf.FOLDER},
+		{Parent: "/search/docs", Name: "report_2023.pdf", Size: 100, Modified: day, ObjType: conf.UNKNOWN, Ext: "pdf"},
+		{Parent: "/search/docs", Name: "report 2024.txt", Size: 2000, Modified: day.AddDate(0, 6, 0), ObjType: conf.TEXT, Ext: "txt"},
+		{Parent: "/search", Name: "movie.mp4", Size: 50000, Modified: day.AddDate(1, 0, 0), ObjType: conf.VIDEO, Ext: "mp4"},
+	}
+	if err := db.BatchCreateSearchNodes(&nodes); err != nil {
+		t.Fatalf("failed create search nodes: %+v", err)
+	}
+	after := day.AddDate(0, 1, 0)
+	tests := []struct {
+		name   string
+		req    model.SearchReq
+		expect []string
+	}{
+		{"all", model.SearchReq{}, []string{"docs", "movie.mp4", "report 2024.txt", "report_2023.pdf"}},
+		{"phrase", model.SearchReq{Keywords: "report 2024", Mode: model.SearchPhrase}, []string{"report 2024.txt"}},
+		{"phrase escaped", model.SearchReq{Keywords: "t_2", Mode: model.SearchPhrase}, []string{"report_2023.pdf"}},
+		{"wildcard", model.SearchReq{Keywords: "report?202*", Mode: model.SearchWildcard}, []string{"report 2024.txt", "report_2023.pdf"}},
+		{"size", model.SearchReq{MinSize: 1000, MaxSize: 10000}, []string{"report 2024.txt"}},
+		{"modified", model.SearchReq{ModifiedAfter: &after}, []string{"movie.mp4", "report 2024.txt"}},
+		{"exts", model.SearchReq{Exts: []string{".PDF", "mp4"}}, []string{"movie.mp4", "report_2023.pdf"}},
+		{"types", model.SearchReq{Types: []int{conf.TEXT}}, []string{"report 2024.txt"}},
+		{"order", model.SearchReq{Scope: 2, OrderBy: "size", OrderDirection: "desc"}, []string{"movie.mp4", "report 2024.txt", "report_2023.pdf"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Parent, req.Page, req.PerPage = "/search", 1, 10
+			if err := req.Validate(); err != nil {
+				t.Fatalf("invalid req: %+v", err)
+			}
+			res, total, err := db.SearchNode(req, false)
+			if err != nil {
+				t.Fatalf("failed search: %+v", err)
+			}
+			names := utils.MustSliceConvert(res, func(node model.SearchNode) string {
+				return node.Name
+			})
+			if int(total) != len(tt.expect) || !utils.SliceEqual(names, tt.expect) {
+				t.Errorf("expected %v, got %v (total %d)", tt.expect, names, total)
+			}
+		})
+	}
+}
+
+func TestSearchNodeVisibility(t *testing.T) {
+	nodes := []model.SearchNode{
+		{Parent: "/visible", Name: "pub", IsDir: true},
+		{Parent: "/visible/pub", Name: "a_1.txt"},
+		{Parent: "/visible/pub/locked", Name: "b.txt"},
+		{Parent: "/visible/pub/locked/open", Name: "c.txt"},
+		{Parent: "/visible/pub_2", Name: "d.txt"},
+		{Parent: "/visible", Name: "private", IsDir: true},
+		{Parent: "/visible/private", Name: "e.txt"},
+	}
+	if err := db.BatchCreateSearchNodes(&nodes); err != nil {
+		t.Fatalf("failed create search nodes: %+v", err)
+	}
+	tests := []struct {
+		name   string
+		req    model.SearchReq
+		expect []string
+	}{
+		{"parent", model.SearchReq{Parent: "/visible/pub"}, []string{"a_1.txt", "b.txt", "c.txt"}},
+		{"paths", model.SearchReq{Paths: []string{"/visible/pub/locked"}}, []string{"b.txt", "c.txt", "pub"}},
+		{"password", model.SearchReq{Rules: []model.SearchRule{{Parent: "/visible/pub/locked", Sub: true, Except: []string{"/visible/pub/locked/open"}}}},
+			[]string{"a_1.txt", "c.txt", "d.txt", "e.txt", "private", "pub"}},
+		{"folder", model.SearchReq{Rules: []model.SearchRule{{Parent: "/visible/pub"}}},
+			[]string{"b.txt", "c.txt", "d.txt", "e.txt", "private", "pub"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			if req.Parent == "" {
+				req.Parent = "/visible"
+			}
+			req.Page, req.PerPage = 1, 10
+			res, total, err := db.SearchNode(req, false)
+			if err != nil {
+				t.Fatalf("failed search: %+v", err)
+			}
+			names := utils.MustSliceConvert(res, func(node model.SearchNode) string {
+				return node.Name
+			})
+			if int(total) != len(tt.expect) || !utils.SliceEqual(names, tt.expect) {
+				t.Errorf("expected %v, got %v (total %d)", tt.expect, names, total)
+			}
+		})
+	}
+}
+
+func TestSearchNodeFTS(t *testing.T) {
+	// the tables of the tests have no prefix
+	prefix := conf.Conf.Database.TablePrefix
+	conf.Conf.Database.TablePrefix = ""
+	defer func() {
+		conf.Conf.Database.TablePrefix = prefix
+	}()
+	// the nodes indexed before are added to the full text index
+	nodes := []model.SearchNode{
+		{Parent: "/fts", Name: "annual report.pdf", Content: "the revenue of this year"},
+		{Parent: "/fts", Name: "notes.txt", Content: "see the annual report for the revenue"},
+	}
+	if err := db.BatchCreateSearchNodes(&nodes); err != nil {
+		t.Fatalf("failed create search nodes: %+v", err)
+	}
+	if err := db.InitSearchNodesFTS(); err != nil {
+		if strings.Contains(err.Error(), "sqlite_fts5") {
+			t.Skip(err)
+		}
+		t.Fatalf("failed init full text index: %+v", err)
+	}
+	nodes = []model.SearchNode{
+		{Parent: "/fts", Name: "年度报告.docx", Content: "今年的收入"},
+		{Parent: "/fts", Name: "ab.txt"},
+	}
+	if err := db.BatchCreateSearchNodes(&nodes); err != nil {
+		t.Fatalf("failed create search nodes: %+v", err)
+	}
+	tests := []struct {
+		name   string
+		req    model.SearchReq
+		expect []string
+	}{
+		{"rank", model.SearchReq{Keywords: "annual revenue"}, []string{"annual report.pdf", "notes.txt"}},
+		{"part", model.SearchReq{Keywords: "nnua"}, []string{"annual report.pdf", "notes.txt"}},
+		{"cjk", model.SearchReq{Keywords: "年度报"}, []string{"年度报告.docx"}},
+		{"phrase", model.SearchReq{Keywords: "this year", Mode: model.SearchPhrase}, []string{"annual report.pdf"}},
+		{"short", model.SearchReq{Keywords: "ab"}, []string{"ab.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Parent, req.Page, req.PerPage = "/fts", 1, 10
+			res, total, err := db.SearchNodeFTS(req)
+			if err != nil {
+				t.Fatalf("failed search: %+v", err)
+			}
+			names := utils.MustSliceConvert(res, func(node model.SearchNode) string {
+				return node.Name
+			})
+			if int(total) != len(tt.expect) || !utils.SliceEqual(names, tt.expect) {
+				t.Errorf("expected %v, got %v (total %d)", tt.expect, names, total)
+			}
+		})
+	}
+	if err := db.DeleteSearchNodesByParent("/fts"); err != nil {
+		t.Fatalf("failed delete search nodes: %+v", err)
+	}
+	res, _, err := db.SearchNodeFTS(model.SearchReq{Parent: "/", Keywords: "annual", PageReq: model.PageReq{Page: 1, PerPage: 10}})
+	if err != nil || len(res) != 0 {
+		t.Errorf("expected the deleted nodes are removed from the index, got %v, %+v", res, err)
+	}
+}
+
+func TestSearchNodeFTSVacuum(t *testing.T) {
+	prefix := conf.Conf.Database.TablePrefix
+	conf.Conf.Database.TablePrefix = ""
+	defer func() {
+		conf.Conf.Database.TablePrefix = prefix
+	}()
+	if err := db.InitSearchNodesFTS(); err != nil {
+		if strings.Contains(err.Error(), "sqlite_fts5") {
+			t.Skip(err)
+		}
+		t.Fatalf("failed init full text index: %+v", err)
+	}
+	nodes := []model.SearchNode{
+		{Parent: "/vacuum", Name: "first.txt", Content: "removed before vacuum"},
+		{Parent: "/vacuum", Name: "second.txt", Content: "lorem ipsum"},
+		{Parent: "/vacuum", Name: "third.txt", Content: "dolor sit amet"},
+	}
+	if err := db.BatchCreateSearchNodes(&nodes); err != nil {
+		t.Fatalf("failed create search nodes: %+v", err)
+	}
+	if err := db.DeleteSearchNodesByParent("/vacuum/first.txt"); err != nil {
+		t.Fatalf("failed delete search node: %+v", err)
+	}
+	// the nodes are still matched after they are moved by VACUUM, as the id is the rowid
+	// which can't be changed by it, and the full text index refers to the nodes by id
+	if err := db.GetDb().Exec("VACUUM").Error; err != nil {
+		t.Fatalf("failed vacuum: %+v", err)
+	}
+	var changed int64
+	if err := db.GetDb().Raw("SELECT count(*) FROM search_nodes WHERE rowid <> id").Scan(&changed).Error; err != nil || changed != 0 {
+		t.Errorf("the id should be the rowid, got %d changed, %+v", changed, err)
+	}
+	var rowid uint
+	if err := db.GetDb().Raw("SELECT rowid FROM search_nodes_fts WHERE search_nodes_fts MATCH 'dolor'").Scan(&rowid).Error; err != nil || rowid != nodes[2].ID {
+		t.Errorf("the full text index should refer to the node by id %d, got %d, %+v", nodes[2].ID, rowid, err)
+	}
+	res, _, err := db.SearchNodeFTS(model.SearchReq{Parent: "/vacuum", Keywords: "dolor", PageReq: model.PageReq{Page: 1, PerPage: 10}})
+	if err != nil || len(res) != 1 || res[0].Name != "third.txt" {
+		t.Errorf("expected third.txt, got %v, %+v", res, err)
+	}
+}
+
+func TestMigrateSearchNodes(t *testing.T) {
+	prefix := conf.Conf.Database.TablePrefix
+	conf.Conf.Database.TablePrefix = ""
+	defer func() {
+		conf.Conf.Database.TablePrefix = prefix
+	}()
+	// the nodes indexed by the old versions have no id
+	stmts := []string{
+		"DROP TABLE IF EXISTS search_nodes_fts",
+		"DROP TABLE search_nodes",
+		"CREATE TABLE search_nodes (parent text, name text, is_dir numeric, size integer, content text)",
+		"CREATE INDEX idx_search_nodes_parent ON search_nodes(parent)",
+		"INSERT INTO search_nodes VALUES ('/old', 'a.txt', 0, 1, 'annual report'), ('/old', 'b', 1, 0, '')",
+	}
+	for _, stmt := range stmts {
+		if err := db.GetDb().Exec(stmt).Error; err != nil {
+			t.Fatalf("failed create the old search nodes: %+v", err)
+		}
+	}
+	db.Init(db.GetDb())
+	nodes, err := db.GetSearchNodesByParent("/old")
+	if err != nil || len(nodes) != 2 {
+		t.Fatalf("the old nodes should be kept, got %v, %+v", nodes, err)
+	}
+	for _, node := range nodes {
+		if node.ID == 0 {
+			t.Errorf("the node should have an id, got %+v", node)
+		}
+	}
+	if err = db.InitSearchNodesFTS(); err != nil {
+		if strings.Contains(err.Error(), "sqlite_fts5") {
+			t.Skip(err)
+		}
+		t.Fatalf("failed init full text index: %+v", err)
+	}
+	res, _, err := db.SearchNodeFTS(model.SearchReq{Parent: "/old", Keywords: "annual", PageReq: model.PageReq{Page: 1, PerPage: 10}})
+	if err != nil || len(res) != 1 || res[0].Name != "a.txt" {
+		t.Errorf("expected the old nodes are added to the full text index, got %v, %+v", res, err)
+	}
+}
@@ -0,0 +1,54 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+)
+
+func GetShareById(id string) (*model.Share, error) {
+	return db.GetShareById(id)
+}
+
+func GetShares(creatorId uint, pageIndex, pageSize int) ([]model.Share, int64, error) {
+	return db.GetShares(creatorId, pageIndex, pageSize)
+}
+
+func CreateShare(s *model.Share) error {
+	s.ID = random.String(10)
+	s.Path = utils.FixAndCleanPath(s.Path)
+	s.AccessCount = 0
+	s.Created = time.Now()
+	return db.CreateShare(s)
+}
+
+// UpdateShare update the settings of the share, the path, creator and access count are kept
+func UpdateShare(s *model.Share) error {
+	old, err := db.GetShareById(s.ID)
+	if err != nil {
+		return err
+	}
+	s.Path, s.CreatorID, s.AccessCount, s.Created = old.Path, old.CreatorID, old.AccessCount, old.Created
+	return db.UpdateShare(s)
+}
+
+func DeleteShareById(id string) error {
+	return db.DeleteShareById(id)
+}
+
+// AccessShare count an access of the share, it fails if the max access count is reached
+func AccessShare(s *model.Share) error {
+	ok, err := db.IncreaseShareAccessCount(s.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("share has reached the max access count")
+	}
+	s.AccessCount++
+	return nil
+}
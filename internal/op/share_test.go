@@ -0,0 +1,61 @@
+package op_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestShare(t *testing.T) {
+	share := &model.Share{Path: "/shared", CreatorID: 1, MaxAccess: 3}
+	share.SetPassword("pwd")
+	if err := op.CreateShare(share); err != nil {
+		t.Fatalf("failed to create share: %+v", err)
+	}
+	share, err := op.GetShareById(share.ID)
+	if err != nil {
+		t.Fatalf("failed get share: %+v", err)
+	}
+	if share.PwdHash == "pwd" || share.ValidatePassword("") || share.ValidatePassword("wrong") || !share.ValidatePassword("pwd") {
+		t.Errorf("only the hash of the password should be kept and validated")
+	}
+	share.SetPassword("")
+	if !share.ValidatePassword("") {
+		t.Errorf("the share without password should be accessed by anyone")
+	}
+
+	// the max access count is not exceeded by concurrent access
+	var wg sync.WaitGroup
+	var accessed atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := op.GetShareById(share.ID)
+			if err != nil {
+				t.Errorf("failed get share: %+v", err)
+				return
+			}
+			if s.Validate() == nil && op.AccessShare(s) == nil {
+				accessed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if accessed.Load() != 3 {
+		t.Errorf("the share should be accessed 3 times, got %d", accessed.Load())
+	}
+	if share, err = op.GetShareById(share.ID); err != nil || share.AccessCount != 3 || share.Validate() == nil {
+		t.Errorf("the share should reach the max access count, got %+v, %+v", share, err)
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	share = &model.Share{Path: "/shared", Expires: &expired}
+	if share.Validate() == nil {
+		t.Errorf("the expired share should be invalid")
+	}
+}
@@ -47,6 +47,9 @@ func CreateStorage(ctx context.Context, storage model.Storage) (uint, error) {
 	storage.Modified = time.Now()
 	storage.MountPath = utils.FixAndCleanPath(storage.MountPath)
 	var err error
+	if err = checkTrashPolicy(storage); err != nil {
+		return 0, err
+	}
 	// check driver first
 	driverName := storage.Driver
 	driverNew, err := GetDriver(driverName)
@@ -139,6 +142,7 @@ func initStorage(ctx context.Context, storage model.Storage, storageDriver drive
 		err = errors.Wrap(err, "failed init storage")
 	} else {
 		driverStorage.SetStatus(WORK)
+		startWatch(storageDriver)
 	}
 	MustSaveDriverStorage(storageDriver)
 	return err
@@ -176,6 +180,10 @@ func DisableStorage(ctx context.Context, id uint) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage driver")
 	}
+	// the list cache may be persisted, so it should not outlive the storage
+	ClearCache(storageDriver, "/")
+	stopWatch(storageDriver)
+	dropHealthState(storageDriver)
 	// drop the storage in the driver
 	if err := storageDriver.Drop(ctx); err != nil {
 		return errors.Wrap(err, "failed drop storage")
@@ -203,6 +211,9 @@ func UpdateStorage(ctx context.Context, storage model.Storage) error {
 	if oldStorage.Driver != storage.Driver {
 		return errors.Errorf("driver cannot be changed")
 	}
+	if err = checkTrashPolicy(storage); err != nil {
+		return err
+	}
 	storage.Modified = time.Now()
 	storage.MountPath = utils.FixAndCleanPath(storage.MountPath)
 	err = db.UpdateStorage(&storage)
@@ -220,6 +231,9 @@ func UpdateStorage(ctx context.Context, storage model.Storage) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage driver")
 	}
+	ClearCache(storageDriver, "/")
+	stopWatch(storageDriver)
+	dropHealthState(storageDriver)
 	err = storageDriver.Drop(ctx)
 	if err != nil {
 		return errors.Wrapf(err, "failed drop storage")
@@ -241,6 +255,9 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 		if err != nil {
 			return errors.WithMessage(err, "failed get storage driver")
 		}
+		ClearCache(storageDriver, "/")
+		stopWatch(storageDriver)
+		dropHealthState(storageDriver)
 		// drop the storage in the driver
 		if err := storageDriver.Drop(ctx); err != nil {
 			return errors.Wrapf(err, "failed drop storage")
@@ -253,6 +270,9 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 	if err := db.DeleteStorageById(id); err != nil {
 		return errors.WithMessage(err, "failed delete storage in database")
 	}
+	if err := db.DeleteUploadedFilesByStorageId(id); err != nil {
+		log.Errorf("failed delete uploaded files of storage %d: %+v", id, err)
+	}
 	return nil
 }
 
@@ -279,14 +299,14 @@ func saveDriverStorage(driver driver.Driver) error {
 	return nil
 }
 
-// getStoragesByPath get storage by longest match path, contains balance storage.
+// getStoragesByPath get storage by longest match path, contains balance storage and the members of balance group.
 // for example, there is /a/b,/a/c,/a/d/e,/a/d/e.balance
 // getStoragesByPath(/a/d/e/f) => /a/d/e,/a/d/e.balance
 func getStoragesByPath(path string) []driver.Driver {
 	storages := make([]driver.Driver, 0)
 	curSlashCount := 0
-	storagesMap.Range(func(mountPath string, value driver.Driver) bool {
-		mountPath = utils.GetActualMountPath(mountPath)
+	storagesMap.Range(func(_ string, value driver.Driver) bool {
+		mountPath := GetVirtualMountPath(value)
 		// is this path
 		if utils.IsSubPath(mountPath, path) {
 			slashCount := strings.Count(utils.PathAddSeparatorSuffix(mountPath), "/")
@@ -324,7 +344,7 @@ func GetStorageVirtualFilesByPath(prefix string) []model.Obj {
 	prefix = utils.FixAndCleanPath(prefix)
 	set := mapset.NewSet[string]()
 	for _, v := range storages {
-		mountPath := utils.GetActualMountPath(v.GetStorage().MountPath)
+		mountPath := GetVirtualMountPath(v)
 		// Exclude prefix itself and non prefix
 		if len(prefix) >= len(mountPath) || !utils.IsSubPath(prefix, mountPath) {
 			continue
@@ -341,24 +361,3 @@ func GetStorageVirtualFilesByPath(prefix string) []model.Obj {
 	}
 	return files
 }
-
-var balanceMap generic_sync.MapOf[string, int]
-
-// GetBalancedStorage get storage by path
-func GetBalancedStorage(path string) driver.Driver {
-	path = utils.FixAndCleanPath(path)
-	storages := getStoragesByPath(path)
-	storageNum := len(storages)
-	switch storageNum {
-	case 0:
-		return nil
-	case 1:
-		return storages[0]
-	default:
-		virtualPath := utils.GetActualMountPath(storages[0].GetStorage().MountPath)
-		i, _ := balanceMap.LoadOrStore(virtualPath, 0)
-		i = (i + 1) % storageNum
-		balanceMap.Store(virtualPath, i)
-		return storages[i]
-	}
-}
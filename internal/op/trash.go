@@ -0,0 +1,267 @@
+package op
+
+import (
+	"context"
+	"net/http"
+	stdpath "path"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/event"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Remove move the object into the trash folder if the trash is enabled for the storage,
+// otherwise remove it permanently
+func Remove(ctx context.Context, storage driver.Driver, path string) error {
+	path = utils.FixAndCleanPath(path)
+	trashDir := GetTrashDir(storage)
+	// the objects in the trash are removed permanently
+	if trashDir == "" || utils.IsSubPath(trashDir, path) {
+		return RemoveDirectly(ctx, storage, path)
+	}
+	if utils.IsSubPath(path, trashDir) {
+		return errors.Errorf("can't remove [%s] which contains the trash folder", path)
+	}
+	return moveToTrash(ctx, storage, path, trashDir)
+}
+
+// GetTrashDir returns the trash folder of the storage, "" if the trash is disabled
+func GetTrashDir(storage driver.Driver) string {
+	s := storage.GetStorage()
+	if s.TrashPolicy == "disabled" {
+		return ""
+	}
+	if s.TrashPolicy == "enabled" && s.TrashPath != "" {
+		return utils.FixAndCleanPath(s.TrashPath)
+	}
+	item, _ := GetSettingItemByKey(conf.TrashPath)
+	if item == nil || item.Value == "" {
+		return ""
+	}
+	return utils.FixAndCleanPath(item.Value)
+}
+
+// checkTrashPolicy make sure the trash folder is known if the trash is enabled for the storage
+func checkTrashPolicy(storage model.Storage) error {
+	if storage.TrashPolicy != "enabled" || storage.TrashPath != "" {
+		return nil
+	}
+	item, _ := GetSettingItemByKey(conf.TrashPath)
+	if item == nil || item.Value == "" {
+		return errors.New("trash path is required since the trash is enabled and the global trash path is empty")
+	}
+	return nil
+}
+
+func moveToTrash(ctx context.Context, storage driver.Driver, path, trashDir string) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	if utils.PathEqual(path, "/") {
+		return errors.New("delete root folder is not allowed, please goto the manage page to delete the storage instead")
+	}
+	obj, err := Get(ctx, storage, path)
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			log.Debugf("%s have been removed", path)
+			return nil
+		}
+		return errors.WithMessage(err, "failed to get object")
+	}
+	// each removed object is put into its own folder, so that the objects with the same name don't conflict
+	entryDir := stdpath.Join(trashDir, strconv.FormatInt(time.Now().UnixNano(), 10))
+	silentCtx := withoutEvents(ctx)
+	if err = MakeDir(silentCtx, storage, entryDir); err != nil {
+		return errors.WithMessage(err, "failed to make trash folder")
+	}
+	if err = moveInStorage(silentCtx, storage, path, entryDir); err != nil {
+		if e := RemoveDirectly(silentCtx, storage, entryDir); e != nil {
+			log.Warnf("failed to remove trash folder %s: %+v", entryDir, e)
+		}
+		return errors.WithMessage(err, "failed to move into trash")
+	}
+	publishObjEvent(ctx, event.ObjDeleted, storage, path, "", obj)
+	item := &model.TrashItem{
+		StorageID: storage.GetStorage().ID,
+		MountPath: storage.GetStorage().MountPath,
+		Path:      path,
+		TrashPath: stdpath.Join(entryDir, obj.GetName()),
+		Name:      obj.GetName(),
+		Size:      obj.GetSize(),
+		IsDir:     obj.IsDir(),
+		DeletedAt: time.Now(),
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		item.DeletedBy = user.Username
+	}
+	return errors.WithMessagef(db.CreateTrashItem(item), "[%s] has been moved into trash, but failed to record it", path)
+}
+
+// moveInStorage move the object into dstDirPath of the same storage,
+// it's done by copying and removing if moving is not supported by the driver
+func moveInStorage(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string) error {
+	err := Move(ctx, storage, srcPath, dstDirPath)
+	if !errors.Is(err, errs.NotImplement) && !errors.Is(err, errs.NotSupport) {
+		return err
+	}
+	err = Copy(ctx, storage, srcPath, dstDirPath)
+	if errors.Is(err, errs.NotImplement) || errors.Is(err, errs.NotSupport) {
+		err = copyByStream(ctx, storage, srcPath, dstDirPath)
+	}
+	if err != nil {
+		return err
+	}
+	// the copy is still charged to the uploaders
+	moveUsage(storage, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
+	return RemoveDirectly(ctx, storage, srcPath)
+}
+
+// copyByStream copy the object by downloading and uploading it again
+func copyByStream(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string) error {
+	srcObj, err := Get(ctx, storage, srcPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s] object", srcPath)
+	}
+	if srcObj.IsDir() {
+		dstPath := stdpath.Join(dstDirPath, srcObj.GetName())
+		if err = MakeDir(ctx, storage, dstPath); err != nil {
+			return err
+		}
+		objs, err := List(ctx, storage, srcPath, model.ListArgs{})
+		if err != nil {
+			return errors.WithMessagef(err, "failed list src [%s] objs", srcPath)
+		}
+		for _, obj := range objs {
+			if err = copyByStream(ctx, storage, stdpath.Join(srcPath, obj.GetName()), dstPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	link, _, err := Link(ctx, storage, srcPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] link", srcPath)
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{
+		Obj: srcObj,
+		Ctx: ctx,
+	}, link)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] stream", srcPath)
+	}
+	return Put(ctx, storage, dstDirPath, ss, nil)
+}
+
+func getTrashItemStorage(item *model.TrashItem) (driver.Driver, error) {
+	s, err := db.GetStorageById(item.StorageID)
+	if err != nil {
+		return nil, errors.WithStack(errs.StorageNotFound)
+	}
+	return GetStorageByMountPath(s.MountPath)
+}
+
+func GetTrashItems(pageIndex, pageSize int) ([]model.TrashItem, int64, error) {
+	return db.GetTrashItems(pageIndex, pageSize)
+}
+
+// RestoreTrashItem move the object back to where it was removed from
+func RestoreTrashItem(ctx context.Context, id uint) error {
+	item, err := db.GetTrashItemById(id)
+	if err != nil {
+		return err
+	}
+	storage, err := getTrashItemStorage(item)
+	if err != nil {
+		return err
+	}
+	if _, err = Get(ctx, storage, item.Path); err == nil {
+		return errors.Errorf("[%s] already exists", item.Path)
+	}
+	dirPath := stdpath.Dir(item.Path)
+	if err = MakeDir(ctx, storage, dirPath); err != nil {
+		return errors.WithMessagef(err, "failed to make dir [%s]", dirPath)
+	}
+	silentCtx := withoutEvents(ctx)
+	if err = moveInStorage(silentCtx, storage, item.TrashPath, dirPath); err != nil {
+		return errors.WithMessage(err, "failed to move out of trash")
+	}
+	if err = RemoveDirectly(silentCtx, storage, stdpath.Dir(item.TrashPath)); err != nil {
+		log.Warnf("failed to remove trash folder of %s: %+v", item.Path, err)
+	}
+	publishObjEvent(ctx, event.ObjCreated, storage, item.Path, "", &model.Object{Name: item.Name, Size: item.Size, IsFolder: item.IsDir})
+	return db.DeleteTrashItemById(item.ID)
+}
+
+// PurgeTrashItem remove the object in the trash permanently
+func PurgeTrashItem(ctx context.Context, id uint) error {
+	item, err := db.GetTrashItemById(id)
+	if err != nil {
+		return err
+	}
+	return purgeTrashItem(ctx, item)
+}
+
+func purgeTrashItem(ctx context.Context, item *model.TrashItem) error {
+	storage, err := getTrashItemStorage(item)
+	if err == nil {
+		// the whole entry folder is removed, it has been published as deleted when moved into the trash
+		if err = RemoveDirectly(withoutEvents(ctx), storage, stdpath.Dir(item.TrashPath)); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, errs.StorageNotFound) {
+		return err
+	}
+	return db.DeleteTrashItemById(item.ID)
+}
+
+// EmptyTrash purge the objects removed before the given time, or all of them if before is zero
+func EmptyTrash(ctx context.Context, before time.Time) (int, error) {
+	var items []model.TrashItem
+	var err error
+	if before.IsZero() {
+		items, err = db.GetAllTrashItems()
+	} else {
+		items, err = db.GetTrashItemsBefore(before)
+	}
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := range items {
+		if err = purgeTrashItem(ctx, &items[i]); err != nil {
+			log.Warnf("failed to purge trash item %s of [%s]: %+v", items[i].Path, items[i].MountPath, err)
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+// CleanExpiredTrash empty the trash by the retention setting
+func CleanExpiredTrash(ctx context.Context) {
+	item, _ := GetSettingItemByKey(conf.TrashRetentionDays)
+	if item == nil {
+		return
+	}
+	days, err := strconv.Atoi(item.Value)
+	if err != nil || days <= 0 {
+		return
+	}
+	n, err := EmptyTrash(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Errorf("failed to clean expired trash: %+v", err)
+	} else if n > 0 {
+		log.Infof("%d expired objects in trash are purged", n)
+	}
+}
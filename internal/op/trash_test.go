@@ -0,0 +1,106 @@
+package op_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func TestTrash(t *testing.T) {
+	dir := t.TempDir()
+	root, _ := utils.Json.MarshalToString(map[string]string{"root_folder_path": dir})
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/trash_none", Addition: root, Trash: model.Trash{TrashPolicy: "enabled"}})
+	if err == nil {
+		t.Errorf("the storage with the trash enabled but no trash path should not be created")
+	}
+	_, err = op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/trash", Addition: root, Trash: model.Trash{TrashPolicy: "enabled", TrashPath: "/.trash"}})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/trash")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	if dir := op.GetTrashDir(storage); dir != "/.trash" {
+		t.Errorf("expected trash dir /.trash, got %s", dir)
+	}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err = os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatalf("failed to write file: %+v", err)
+		}
+	}
+	remove := func(path string) *model.TrashItem {
+		t.Helper()
+		if err := op.Remove(ctx, storage, path); err != nil {
+			t.Fatalf("failed to remove %s: %+v", path, err)
+		}
+		if utils.Exists(filepath.Join(dir, path)) {
+			t.Fatalf("%s should be moved into trash", path)
+		}
+		items, _, err := op.GetTrashItems(1, model.MaxInt)
+		if err != nil {
+			t.Fatalf("failed get trash items: %+v", err)
+		}
+		for i := range items {
+			if items[i].Path == path {
+				if !utils.Exists(filepath.Join(dir, items[i].TrashPath)) {
+					t.Fatalf("%s should be kept in trash", path)
+				}
+				return &items[i]
+			}
+		}
+		t.Fatalf("%s should be recorded in trash", path)
+		return nil
+	}
+
+	// restore
+	a := remove("/a.txt")
+	if err = op.RestoreTrashItem(ctx, a.ID); err != nil {
+		t.Fatalf("failed to restore: %+v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "a.txt")); err != nil || string(data) != "a.txt" {
+		t.Errorf("a.txt should be restored, got %q, %+v", data, err)
+	}
+	if utils.Exists(filepath.Join(dir, filepath.Dir(a.TrashPath))) {
+		t.Errorf("the trash folder of a.txt should be removed")
+	}
+	if _, err = db.GetTrashItemById(a.ID); err == nil {
+		t.Errorf("the restored item should not be kept")
+	}
+
+	// purge
+	b := remove("/b.txt")
+	if err = op.PurgeTrashItem(ctx, b.ID); err != nil {
+		t.Fatalf("failed to purge: %+v", err)
+	}
+	if utils.Exists(filepath.Join(dir, filepath.Dir(b.TrashPath))) || utils.Exists(filepath.Join(dir, "b.txt")) {
+		t.Errorf("b.txt should be removed permanently")
+	}
+
+	// the expired items are purged by the retention
+	c := remove("/c.txt")
+	if err = op.SaveSettingItem(&model.SettingItem{Key: conf.TrashRetentionDays, Value: "1", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE}); err != nil {
+		t.Fatalf("failed to save setting: %+v", err)
+	}
+	op.CleanExpiredTrash(ctx)
+	if _, err = db.GetTrashItemById(c.ID); err != nil {
+		t.Fatalf("c.txt should be kept before it's expired")
+	}
+	c.DeletedAt = time.Now().AddDate(0, 0, -2)
+	if err = db.GetDb().Save(c).Error; err != nil {
+		t.Fatalf("failed to update trash item: %+v", err)
+	}
+	op.CleanExpiredTrash(ctx)
+	if _, err = db.GetTrashItemById(c.ID); err == nil || utils.Exists(filepath.Join(dir, c.TrashPath)) {
+		t.Errorf("c.txt should be purged after it's expired")
+	}
+}
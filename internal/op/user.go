@@ -22,6 +22,9 @@ func GetAdmin() (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err = loadGroups(user); err != nil {
+			return nil, err
+		}
 		adminUser = user
 	}
 	return adminUser, nil
@@ -33,6 +36,9 @@ func GetGuest() (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err = loadGroups(user); err != nil {
+			return nil, err
+		}
 		guestUser = user
 	}
 	return guestUser, nil
@@ -54,6 +60,9 @@ func GetUserByName(username string) (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err = loadGroups(_user); err != nil {
+			return nil, err
+		}
 		userCache.Set(username, _user, cache.WithEx[*model.User](time.Hour))
 		return _user, nil
 	})
@@ -61,16 +70,47 @@ func GetUserByName(username string) (*model.User, error) {
 }
 
 func GetUserById(id uint) (*model.User, error) {
-	return db.GetUserById(id)
+	user, err := db.GetUserById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err = loadGroups(user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func GetUsers(pageIndex, pageSize int) (users []model.User, count int64, err error) {
-	return db.GetUsers(pageIndex, pageSize)
+	users, count, err = db.GetUsers(pageIndex, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]uint, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	groupIds, err := db.GetGroupIdsByUserIds(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range users {
+		users[i].GroupIDs = groupIds[users[i].ID]
+		if users[i].GroupIDs == nil {
+			users[i].GroupIDs = []uint{}
+		}
+	}
+	return users, count, nil
 }
 
 func CreateUser(u *model.User) error {
 	u.BasePath = utils.FixAndCleanPath(u.BasePath)
-	return db.CreateUser(u)
+	if err := checkGroupIds(u.GroupIDs); err != nil {
+		return err
+	}
+	if err := db.CreateUser(u); err != nil {
+		return err
+	}
+	return db.SetUserGroups(u.ID, u.GroupIDs)
 }
 
 func DeleteUserById(id uint) error {
@@ -82,6 +122,15 @@ func DeleteUserById(id uint) error {
 		return errs.DeleteAdminOrGuest
 	}
 	userCache.Del(old.Username)
+	if err = db.DeleteUserUsage(id); err != nil {
+		return err
+	}
+	if err = db.DeleteUserGroupsByUserId(id); err != nil {
+		return err
+	}
+	if err = db.DeleteACLsByUserId(id); err != nil {
+		return err
+	}
 	return db.DeleteUserById(id)
 }
 
@@ -98,6 +147,15 @@ func UpdateUser(u *model.User) error {
 	}
 	userCache.Del(old.Username)
 	u.BasePath = utils.FixAndCleanPath(u.BasePath)
+	// the groups are kept if they are not given
+	if u.GroupIDs != nil {
+		if err = checkGroupIds(u.GroupIDs); err != nil {
+			return err
+		}
+		if err = db.SetUserGroups(u.ID, u.GroupIDs); err != nil {
+			return err
+		}
+	}
 	return db.UpdateUser(u)
 }
 
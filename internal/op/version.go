@@ -0,0 +1,173 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/event"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the old versions of /a/b.txt are kept as {versions dir}/a/b.txt/{unix nano}/b.txt
+
+// GetVersionsDir returns the folder to keep the old versions, or "" if versioning is disabled
+func GetVersionsDir(storage driver.Driver) string {
+	s := storage.GetStorage()
+	if s.VersionsKeep <= 0 {
+		return ""
+	}
+	if s.VersionsPath == "" {
+		return "/.versions"
+	}
+	return utils.FixAndCleanPath(s.VersionsPath)
+}
+
+// saveVersion move the existing file into the versions folder before it's overwritten,
+// returns the path of the version or "" if versioning is disabled
+func saveVersion(ctx context.Context, storage driver.Driver, path string) (string, error) {
+	versionsDir := GetVersionsDir(storage)
+	if versionsDir == "" || utils.IsSubPath(versionsDir, path) {
+		return "", nil
+	}
+	versionDir := stdpath.Join(versionsDir, path, strconv.FormatInt(time.Now().UnixNano(), 10))
+	if err := MakeDir(ctx, storage, versionDir); err != nil {
+		return "", errors.WithMessage(err, "failed to make version folder")
+	}
+	if err := moveInStorage(ctx, storage, path, versionDir); err != nil {
+		if e := RemoveDirectly(ctx, storage, versionDir); e != nil {
+			log.Warnf("failed to remove version folder %s: %+v", versionDir, e)
+		}
+		return "", err
+	}
+	return stdpath.Join(versionDir, stdpath.Base(path)), nil
+}
+
+// revertVersion move the version back if the new file failed to be uploaded
+func revertVersion(ctx context.Context, storage driver.Driver, path, versionPath string) {
+	if _, err := GetUnwrap(ctx, storage, path); err == nil {
+		// the broken file left by the failed upload
+		if err = RemoveDirectly(ctx, storage, path); err != nil {
+			log.Errorf("failed to remove %s to revert the version: %+v", path, err)
+			return
+		}
+	}
+	if err := moveInStorage(ctx, storage, versionPath, stdpath.Dir(path)); err != nil {
+		log.Errorf("failed to revert the version of %s: %+v", path, err)
+		return
+	}
+	if err := RemoveDirectly(ctx, storage, stdpath.Dir(versionPath)); err != nil {
+		log.Warnf("failed to remove version folder of %s: %+v", path, err)
+	}
+}
+
+// ListVersions returns the old versions of the file, the newest first
+func ListVersions(ctx context.Context, storage driver.Driver, path string) ([]model.FileVersion, error) {
+	path = utils.FixAndCleanPath(path)
+	versionsDir := GetVersionsDir(storage)
+	if versionsDir == "" {
+		return nil, errors.New("versioning is not enabled for this storage")
+	}
+	dir := stdpath.Join(versionsDir, path)
+	objs, err := List(ctx, storage, dir, model.ListArgs{})
+	if err != nil {
+		if errs.IsObjectNotFound(err) {
+			return []model.FileVersion{}, nil
+		}
+		return nil, errors.WithMessage(err, "failed list versions")
+	}
+	versions := make([]model.FileVersion, 0, len(objs))
+	for _, obj := range objs {
+		nano, err := strconv.ParseInt(obj.GetName(), 10, 64)
+		if !obj.IsDir() || err != nil {
+			continue
+		}
+		versionPath := stdpath.Join(dir, obj.GetName(), stdpath.Base(path))
+		file, err := Get(ctx, storage, versionPath)
+		if err != nil {
+			log.Warnf("failed get version %s: %+v", versionPath, err)
+			continue
+		}
+		versions = append(versions, model.FileVersion{
+			ID:       obj.GetName(),
+			Name:     file.GetName(),
+			Size:     file.GetSize(),
+			Modified: file.ModTime(),
+			Created:  time.Unix(0, nano),
+			Path:     versionPath,
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Created.After(versions[j].Created)
+	})
+	return versions, nil
+}
+
+// GetVersionPath returns the actual path of the version with the id
+func GetVersionPath(storage driver.Driver, path, id string) (string, error) {
+	versionsDir := GetVersionsDir(storage)
+	if versionsDir == "" {
+		return "", errors.New("versioning is not enabled for this storage")
+	}
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		return "", errors.Errorf("invalid version id: %s", id)
+	}
+	path = utils.FixAndCleanPath(path)
+	return stdpath.Join(versionsDir, path, id, stdpath.Base(path)), nil
+}
+
+// RestoreVersion replace the file with the version, the current file is kept as a new version
+func RestoreVersion(ctx context.Context, storage driver.Driver, path, id string) error {
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	path = utils.FixAndCleanPath(path)
+	versionPath, err := GetVersionPath(storage, path, id)
+	if err != nil {
+		return err
+	}
+	version, err := Get(ctx, storage, versionPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get version")
+	}
+	silentCtx := withoutEvents(ctx)
+	if _, err = GetUnwrap(ctx, storage, path); err == nil {
+		if _, err = saveVersion(silentCtx, storage, path); err != nil {
+			return errors.WithMessage(err, "failed to save current version")
+		}
+	}
+	if err = MakeDir(ctx, storage, stdpath.Dir(path)); err != nil {
+		return err
+	}
+	if err = moveInStorage(silentCtx, storage, versionPath, stdpath.Dir(path)); err != nil {
+		return errors.WithMessage(err, "failed to restore version")
+	}
+	if err = RemoveDirectly(silentCtx, storage, stdpath.Dir(versionPath)); err != nil {
+		log.Warnf("failed to remove version folder of %s: %+v", path, err)
+	}
+	linkCache.Del(Key(storage, path))
+	pruneVersions(silentCtx, storage, path)
+	publishObjEvent(ctx, event.ObjUpdated, storage, path, "", version)
+	return nil
+}
+
+// pruneVersions remove the oldest versions beyond the number to keep
+func pruneVersions(ctx context.Context, storage driver.Driver, path string) {
+	keep := storage.GetStorage().VersionsKeep
+	versions, err := ListVersions(ctx, storage, path)
+	if err != nil || len(versions) <= keep {
+		return
+	}
+	for _, v := range versions[keep:] {
+		if err = RemoveDirectly(ctx, storage, stdpath.Dir(v.Path)); err != nil {
+			log.Warnf("failed to remove old version %s: %+v", v.Path, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package op_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+func TestVersions(t *testing.T) {
+	dir := t.TempDir()
+	root, _ := utils.Json.MarshalToString(map[string]string{"root_folder_path": dir})
+	ctx := context.Background()
+	_, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/versions", Addition: root, Versioning: model.Versioning{VersionsKeep: 2}})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/versions")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	put := func(reader io.Reader, size int64) error {
+		return op.Put(ctx, storage, "/", &stream.FileStream{
+			Ctx:    ctx,
+			Obj:    &model.Object{Name: "a.txt", Size: size},
+			Reader: reader,
+		}, nil)
+	}
+	putContent := func(content string) {
+		t.Helper()
+		if err := put(strings.NewReader(content), int64(len(content))); err != nil {
+			t.Fatalf("failed to put %s: %+v", content, err)
+		}
+	}
+	expectContent := func(content string) {
+		t.Helper()
+		if data, err := os.ReadFile(filepath.Join(dir, "a.txt")); err != nil || string(data) != content {
+			t.Errorf("expected content %q, got %q, %+v", content, data, err)
+		}
+	}
+	listVersions := func() []model.FileVersion {
+		t.Helper()
+		versions, err := fs.ListVersions(ctx, "/versions/a.txt")
+		if err != nil {
+			t.Fatalf("failed list versions: %+v", err)
+		}
+		return versions
+	}
+
+	// save
+	putContent("v1")
+	if versions := listVersions(); len(versions) != 0 {
+		t.Errorf("the new file should have no version, got %+v", versions)
+	}
+	putContent("v2")
+	versions := listVersions()
+	if len(versions) != 1 || versions[0].Size != 2 || !strings.HasPrefix(versions[0].Path, "/versions/.versions/a.txt/") {
+		t.Fatalf("the old file should be kept as a version, got %+v", versions)
+	}
+	expectContent("v2")
+
+	// revert, the old file is moved back if the upload failed
+	if err = put(io.MultiReader(strings.NewReader("v"), iotest.ErrReader(errors.New("broken"))), 3); err == nil {
+		t.Fatalf("the broken upload should fail")
+	}
+	expectContent("v2")
+	if reverted := listVersions(); len(reverted) != 1 || reverted[0].ID != versions[0].ID {
+		t.Errorf("the version should be moved back after the failed upload, got %+v", reverted)
+	}
+
+	// prune to the number to keep
+	putContent("v3")
+	putContent("v4")
+	versions = listVersions()
+	if len(versions) != 2 {
+		t.Fatalf("only 2 versions should be kept, got %+v", versions)
+	}
+	for i, content := range []string{"v3", "v2"} {
+		data, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(versions[i].Path, "/versions")))
+		if err != nil || string(data) != content {
+			t.Errorf("expected version %d to be %q, got %q, %+v", i, content, data, err)
+		}
+	}
+
+	// restore, the current file is kept as a new version
+	if err = fs.RestoreVersion(ctx, "/versions/a.txt", versions[1].ID); err != nil {
+		t.Fatalf("failed to restore version: %+v", err)
+	}
+	expectContent("v2")
+	restored := listVersions()
+	if len(restored) != 2 || restored[1].ID != versions[0].ID {
+		t.Errorf("the current file should be the newest version and the restored one removed, got %+v", restored)
+	}
+	if err = fs.RestoreVersion(ctx, "/versions/a.txt", "../a"); err == nil {
+		t.Errorf("the invalid version id should be rejected")
+	}
+}
@@ -0,0 +1,59 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var watchCancels generic_sync.MapOf[driver.Driver, context.CancelFunc]
+
+// startWatch start watching the changes of storage if the driver is a driver.Watcher
+func startWatch(storage driver.Driver) {
+	watcher, ok := storage.(driver.Watcher)
+	if !ok {
+		return
+	}
+	stopWatch(storage)
+	ctx, cancel := context.WithCancel(context.Background())
+	watchCancels.Store(storage, cancel)
+	go func() {
+		mountPath := storage.GetStorage().MountPath
+		log.Debugf("start watching storage %s", mountPath)
+		err := watcher.Watch(ctx, func(dir string) {
+			onDirChanged(storage, dir)
+		})
+		if err != nil && !errs.IsNotSupportError(err) && !errors.Is(err, context.Canceled) {
+			log.Errorf("failed to watch storage %s: %+v", mountPath, err)
+		}
+	}()
+}
+
+func stopWatch(storage driver.Driver) {
+	if cancel, ok := watchCancels.Load(storage); ok {
+		cancel()
+		watchCancels.Delete(storage)
+	}
+}
+
+var dirChangedDebounceMap generic_sync.MapOf[string, func(func())]
+
+func onDirChanged(storage driver.Driver, dir string) {
+	dir = utils.FixAndCleanPath(dir)
+	log.Debugf("storage %s changed: %s", storage.GetStorage().MountPath, dir)
+	listCache.DelPrefix(Key(storage, dir))
+	// the changes usually come in bursts, so the hooks are called after they settle down
+	reqPath := stdpath.Join(GetVirtualMountPath(storage), dir)
+	debounce, _ := dirChangedDebounceMap.LoadOrStore(reqPath, utils.NewDebounce(3*time.Second))
+	debounce(func() {
+		dirChangedDebounceMap.Delete(reqPath)
+		HandleDirChangedHook(reqPath)
+	})
+}
@@ -0,0 +1,302 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+type runner interface {
+	validate(args string) error
+	// run returns a message about what has been done and the tasks added, which are waited for
+	// before the run is finished
+	run(ctx context.Context, args string) (string, []task.TaskExtensionInfo, error)
+}
+
+// typedRunner decode the json args of the job to A
+type typedRunner[A any] struct {
+	check func(args *A) error
+	do    func(ctx context.Context, args *A) (string, []task.TaskExtensionInfo, error)
+}
+
+func (r typedRunner[A]) decode(args string) (*A, error) {
+	a := new(A)
+	if args == "" {
+		args = "{}"
+	}
+	if err := utils.Json.UnmarshalFromString(args, a); err != nil {
+		return nil, errors.WithMessage(err, "invalid args")
+	}
+	if r.check != nil {
+		if err := r.check(a); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func (r typedRunner[A]) validate(args string) error {
+	_, err := r.decode(args)
+	return err
+}
+
+func (r typedRunner[A]) run(ctx context.Context, args string) (string, []task.TaskExtensionInfo, error) {
+	a, err := r.decode(args)
+	if err != nil {
+		return "", nil, err
+	}
+	return r.do(ctx, a)
+}
+
+type CopyArgs struct {
+	SrcDir string   `json:"src_dir"`
+	DstDir string   `json:"dst_dir"`
+	Names  []string `json:"names"`
+}
+
+type SyncArgs struct {
+	SrcDir string `json:"src_dir"`
+	DstDir string `json:"dst_dir"`
+	Delete bool   `json:"delete"`
+	DryRun bool   `json:"dry_run"`
+}
+
+type DecompressArgs struct {
+	SrcDir        string   `json:"src_dir"`
+	DstDir        string   `json:"dst_dir"`
+	Names         []string `json:"names"`
+	ArchivePass   string   `json:"archive_pass"`
+	InnerPath     string   `json:"inner_path"`
+	CacheFull     bool     `json:"cache_full"`
+	PutIntoNewDir bool     `json:"put_into_new_dir"`
+}
+
+type UpdateIndexArgs struct {
+	Paths    []string `json:"paths"`
+	MaxDepth int      `json:"max_depth"`
+}
+
+type OfflineDownloadArgs struct {
+	Urls         []string `json:"urls"`
+	DstDir       string   `json:"dst_dir"`
+	Tool         string   `json:"tool"`
+	DeletePolicy string   `json:"delete_policy"`
+}
+
+type ClearDoneTasksArgs struct {
+	// IncludeFailed also clear the failed and canceled tasks, otherwise only the succeeded ones
+	IncludeFailed bool `json:"include_failed"`
+}
+
+func requireDirs(src, dst string) error {
+	if src == "" || dst == "" {
+		return errors.New("src_dir and dst_dir are required")
+	}
+	return nil
+}
+
+var runners = map[string]runner{
+	model.ScheduleCopy: typedRunner[CopyArgs]{
+		check: func(args *CopyArgs) error {
+			if len(args.Names) == 0 {
+				return errors.New("names are required")
+			}
+			return requireDirs(args.SrcDir, args.DstDir)
+		},
+		do: func(ctx context.Context, args *CopyArgs) (string, []task.TaskExtensionInfo, error) {
+			var tasks []task.TaskExtensionInfo
+			for _, name := range args.Names {
+				t, err := fs.Copy(ctx, stdpath.Join(args.SrcDir, name), args.DstDir)
+				if err != nil {
+					return tasksMessage(tasks), tasks, err
+				}
+				if t != nil {
+					tasks = append(tasks, t)
+				}
+			}
+			return tasksMessage(tasks), tasks, nil
+		},
+	},
+	model.ScheduleSync: typedRunner[SyncArgs]{
+		check: func(args *SyncArgs) error {
+			return requireDirs(args.SrcDir, args.DstDir)
+		},
+		do: func(ctx context.Context, args *SyncArgs) (string, []task.TaskExtensionInfo, error) {
+			t, err := fs.Sync(ctx, args.SrcDir, args.DstDir, args.Delete, args.DryRun, "")
+			if err != nil {
+				return "", nil, err
+			}
+			tasks := []task.TaskExtensionInfo{t}
+			return tasksMessage(tasks), tasks, nil
+		},
+	},
+	model.ScheduleDecompress: typedRunner[DecompressArgs]{
+		check: func(args *DecompressArgs) error {
+			if len(args.Names) == 0 {
+				return errors.New("names are required")
+			}
+			return requireDirs(args.SrcDir, args.DstDir)
+		},
+		do: func(ctx context.Context, args *DecompressArgs) (string, []task.TaskExtensionInfo, error) {
+			var tasks []task.TaskExtensionInfo
+			for _, name := range args.Names {
+				t, err := fs.ArchiveDecompress(ctx, stdpath.Join(args.SrcDir, name), args.DstDir, model.ArchiveDecompressArgs{
+					ArchiveInnerArgs: model.ArchiveInnerArgs{
+						ArchiveArgs: model.ArchiveArgs{
+							LinkArgs: model.LinkArgs{Header: http.Header{}},
+							Password: args.ArchivePass,
+						},
+						InnerPath: utils.FixAndCleanPath(args.InnerPath),
+					},
+					CacheFull:     args.CacheFull,
+					PutIntoNewDir: args.PutIntoNewDir,
+				})
+				if err != nil {
+					return tasksMessage(tasks), tasks, err
+				}
+				if t != nil {
+					tasks = append(tasks, t)
+				}
+			}
+			return tasksMessage(tasks), tasks, nil
+		},
+	},
+	model.ScheduleBuildIndex: typedRunner[struct{}]{
+		do: func(ctx context.Context, _ *struct{}) (string, []task.TaskExtensionInfo, error) {
+			if search.Running() || search.StorageIndexRunning() {
+				return "", nil, errors.New("index is running")
+			}
+			if err := search.Clear(ctx); err != nil {
+				return "", nil, err
+			}
+			err := search.BuildIndex(ctx, []string{"/"}, conf.SlicesMap[conf.IgnorePaths], setting.GetInt(conf.MaxIndexDepth, 20), true)
+			if err != nil {
+				return "", nil, err
+			}
+			return "index built", nil, nil
+		},
+	},
+	model.ScheduleUpdateIndex: typedRunner[UpdateIndexArgs]{
+		check: func(args *UpdateIndexArgs) error {
+			if len(args.Paths) == 0 {
+				return errors.New("paths are required")
+			}
+			return nil
+		},
+		do: func(ctx context.Context, args *UpdateIndexArgs) (string, []task.TaskExtensionInfo, error) {
+			if search.Running() || search.StorageIndexRunning() {
+				return "", nil, errors.New("index is running")
+			}
+			if !search.Config(ctx).AutoUpdate {
+				return "", nil, errors.New("update is not supported for current index")
+			}
+			for _, path := range args.Paths {
+				if err := search.Del(ctx, path); err != nil {
+					return "", nil, err
+				}
+			}
+			err := search.BuildIndex(ctx, args.Paths, conf.SlicesMap[conf.IgnorePaths], args.MaxDepth, false)
+			if err != nil {
+				return "", nil, err
+			}
+			return fmt.Sprintf("index of %s updated", strings.Join(args.Paths, ", ")), nil, nil
+		},
+	},
+	model.ScheduleOfflineDownload: typedRunner[OfflineDownloadArgs]{
+		check: func(args *OfflineDownloadArgs) error {
+			if len(args.Urls) == 0 || args.DstDir == "" {
+				return errors.New("urls and dst_dir are required")
+			}
+			if _, err := tool.Tools.Get(args.Tool); err != nil {
+				return err
+			}
+			return nil
+		},
+		do: func(ctx context.Context, args *OfflineDownloadArgs) (string, []task.TaskExtensionInfo, error) {
+			var tasks []task.TaskExtensionInfo
+			for _, url := range args.Urls {
+				url = strings.TrimSpace(url)
+				if url == "" {
+					continue
+				}
+				t, err := tool.AddURL(ctx, &tool.AddURLArgs{
+					URL:          url,
+					DstDirPath:   args.DstDir,
+					Tool:         args.Tool,
+					DeletePolicy: tool.DeletePolicy(args.DeletePolicy),
+				})
+				if err != nil {
+					return tasksMessage(tasks), tasks, err
+				}
+				tasks = append(tasks, t)
+			}
+			return tasksMessage(tasks), tasks, nil
+		},
+	},
+	model.ScheduleClearDoneTasks: typedRunner[ClearDoneTasksArgs]{
+		do: func(ctx context.Context, args *ClearDoneTasksArgs) (string, []task.TaskExtensionInfo, error) {
+			states := []tache.State{tache.StateSucceeded}
+			if args.IncludeFailed {
+				states = append(states, tache.StateFailed, tache.StateCanceled)
+			}
+			n := clearTasks(fs.UploadTaskManager, states) +
+				clearTasks(fs.CopyTaskManager, states) +
+				clearTasks(fs.SyncTaskManager, states) +
+				clearTasks(fs.ArchiveDownloadTaskManager, states) +
+				clearTasks[*fs.ArchiveContentUploadTask](fs.ArchiveContentUploadTaskManager, states) +
+				clearTasks(tool.DownloadTaskManager, states) +
+				clearTasks(tool.TransferTaskManager, states)
+			return fmt.Sprintf("%d tasks cleared", n), nil, nil
+		},
+	},
+}
+
+func clearTasks[T tache.Task](manager task.Manager[T], states []tache.State) int {
+	n := len(manager.GetByState(states...))
+	manager.RemoveByState(states...)
+	return n
+}
+
+// getTasks returns the tasks of all the managers, it's replaced in tests
+var getTasks = func() []task.TaskExtensionInfo {
+	var res []task.TaskExtensionInfo
+	res = appendTasks(res, fs.UploadTaskManager)
+	res = appendTasks(res, fs.CopyTaskManager)
+	res = appendTasks(res, fs.SyncTaskManager)
+	res = appendTasks(res, fs.ArchiveDownloadTaskManager)
+	res = appendTasks[*fs.ArchiveContentUploadTask](res, fs.ArchiveContentUploadTaskManager)
+	res = appendTasks(res, tool.DownloadTaskManager)
+	res = appendTasks(res, tool.TransferTaskManager)
+	return res
+}
+
+func appendTasks[T task.TaskExtensionInfo](res []task.TaskExtensionInfo, manager task.Manager[T]) []task.TaskExtensionInfo {
+	for _, t := range manager.GetAll() {
+		res = append(res, t)
+	}
+	return res
+}
+
+func tasksMessage(tasks []task.TaskExtensionInfo) string {
+	if len(tasks) == 0 {
+		return "no task added"
+	}
+	ids := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, t.GetID())
+	}
+	return fmt.Sprintf("added tasks: %s", strings.Join(ids, ", "))
+}
@@ -0,0 +1,349 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+// MaxHistory is the number of runs kept for each job
+const MaxHistory = 50
+
+var (
+	parser  = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	c       *cron.Cron
+	mu      sync.Mutex
+	entries = map[uint]cron.EntryID{}
+	// running keeps the ids of the jobs whose last run has not finished
+	running generic_sync.MapOf[uint, struct{}]
+	// runCtx is canceled when stopped, so that the runs stop waiting for their tasks
+	runCtx, cancelRuns = context.WithCancel(context.Background())
+)
+
+// Init load all the jobs and start to schedule them, it should be called after the task managers are initialized
+func Init() {
+	mu.Lock()
+	defer mu.Unlock()
+	runCtx, cancelRuns = context.WithCancel(context.Background())
+	c = cron.New(cron.WithParser(parser), cron.WithChain(cron.Recover(cronLogger{})))
+	jobs, _, err := db.GetScheduledJobs(1, model.MaxInt)
+	if err != nil {
+		log.Errorf("failed get scheduled jobs: %+v", err)
+	}
+	for i := range jobs {
+		if err = schedule(&jobs[i]); err != nil {
+			log.Errorf("failed schedule job [%s]: %+v", jobs[i].Name, err)
+		}
+	}
+	c.Start()
+}
+
+// Stop stop scheduling and wait for the running jobs, which stop waiting for their tasks,
+// the tasks are persisted and restored by the task managers
+func Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+	if c == nil {
+		return
+	}
+	cancelRuns()
+	<-c.Stop().Done()
+	c = nil
+}
+
+// schedule (re)add the job to cron, mu must be held
+func schedule(job *model.ScheduledJob) error {
+	if id, ok := entries[job.ID]; ok {
+		c.Remove(id)
+		delete(entries, job.ID)
+	}
+	if job.Disabled {
+		return nil
+	}
+	sched, err := parser.Parse(job.Cron)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	jobId := job.ID
+	// a job is skipped if the last run of it has not finished
+	entries[jobId] = c.Schedule(sched, cron.NewChain(cron.SkipIfStillRunning(cronLogger{})).Then(cron.FuncJob(func() {
+		if _, err := Run(jobId); err != nil {
+			log.Errorf("failed run scheduled job %d: %+v", jobId, err)
+		}
+	})))
+	return nil
+}
+
+func unschedule(id uint) {
+	if entryId, ok := entries[id]; ok {
+		if c != nil {
+			c.Remove(entryId)
+		}
+		delete(entries, id)
+	}
+}
+
+func fillNextRunTime(job *model.ScheduledJob) {
+	mu.Lock()
+	defer mu.Unlock()
+	if c == nil {
+		return
+	}
+	if id, ok := entries[job.ID]; ok {
+		next := c.Entry(id).Next
+		if !next.IsZero() {
+			job.NextRunTime = &next
+		}
+	}
+}
+
+func validate(job *model.ScheduledJob) error {
+	if _, err := parser.Parse(job.Cron); err != nil {
+		return errors.WithMessage(err, "invalid cron expression")
+	}
+	runner, ok := runners[job.Type]
+	if !ok {
+		return errors.Errorf("unknown job type: %s", job.Type)
+	}
+	return runner.validate(job.Args)
+}
+
+func GetJobs(pageIndex, pageSize int) ([]model.ScheduledJob, int64, error) {
+	jobs, count, err := db.GetScheduledJobs(pageIndex, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range jobs {
+		fillNextRunTime(&jobs[i])
+	}
+	return jobs, count, nil
+}
+
+func GetJobById(id uint) (*model.ScheduledJob, error) {
+	job, err := db.GetScheduledJobById(id)
+	if err != nil {
+		return nil, err
+	}
+	fillNextRunTime(job)
+	return job, nil
+}
+
+func CreateJob(job *model.ScheduledJob) error {
+	if err := validate(job); err != nil {
+		return err
+	}
+	job.ID = 0
+	job.LastRunTime, job.LastStatus, job.LastMessage = nil, "", ""
+	if err := db.CreateScheduledJob(job); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if c == nil {
+		return nil
+	}
+	return schedule(job)
+}
+
+func UpdateJob(job *model.ScheduledJob) error {
+	if err := validate(job); err != nil {
+		return err
+	}
+	old, err := db.GetScheduledJobById(job.ID)
+	if err != nil {
+		return err
+	}
+	// the status is only updated by runs
+	job.LastRunTime, job.LastStatus, job.LastMessage = old.LastRunTime, old.LastStatus, old.LastMessage
+	if err = db.UpdateScheduledJob(job); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if c == nil {
+		return nil
+	}
+	return schedule(job)
+}
+
+func DeleteJobById(id uint) error {
+	if err := db.DeleteScheduledJobById(id); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	unschedule(id)
+	return nil
+}
+
+func GetJobRuns(id uint, pageIndex, pageSize int) ([]model.ScheduledJobRun, int64, error) {
+	return db.GetScheduledJobRuns(id, pageIndex, pageSize)
+}
+
+// Run run the job once and record the result after the tasks added by it are finished, it's called by cron
+func Run(id uint) (*model.ScheduledJobRun, error) {
+	run, wait, err := start(id)
+	if err != nil {
+		return nil, err
+	}
+	wait()
+	return run, nil
+}
+
+// Trigger run the job once manually, the run is returned once the tasks are added
+// and its result is recorded in background
+func Trigger(id uint) (*model.ScheduledJobRun, error) {
+	run, wait, err := start(id)
+	if err != nil {
+		return nil, err
+	}
+	res := *run
+	go wait()
+	return &res, nil
+}
+
+// start the run of the job, the returned wait func waits for the tasks and records the result
+func start(id uint) (*model.ScheduledJobRun, func(), error) {
+	job, err := db.GetScheduledJobById(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	runner, ok := runners[job.Type]
+	if !ok {
+		return nil, nil, errors.Errorf("unknown job type: %s", job.Type)
+	}
+	if _, loaded := running.LoadOrStore(job.ID, struct{}{}); loaded {
+		return nil, nil, errors.Errorf("the last run of job [%s] has not finished", job.Name)
+	}
+	run := &model.ScheduledJobRun{
+		JobID:     job.ID,
+		StartTime: time.Now(),
+		Status:    model.ScheduleRunning,
+	}
+	if err = db.CreateScheduledJobRun(run); err != nil {
+		running.Delete(job.ID)
+		return nil, nil, err
+	}
+	job.LastRunTime, job.LastStatus, job.LastMessage = &run.StartTime, run.Status, ""
+	if err = db.UpdateScheduledJobStatus(job); err != nil {
+		log.Errorf("failed update status of scheduled job [%s]: %+v", job.Name, err)
+	}
+
+	msg, tasks, err := execute(runner, job)
+	run.Message = msg
+	if err == nil && len(tasks) > 0 {
+		if err = db.UpdateScheduledJobRun(run); err != nil {
+			log.Errorf("failed update run of scheduled job [%s]: %+v", job.Name, err)
+		}
+	}
+	return run, func() {
+		defer running.Delete(job.ID)
+		if err == nil {
+			err = waitTasks(runCtx, tasks)
+		}
+		finish(job, run, err)
+	}, nil
+}
+
+func finish(job *model.ScheduledJob, run *model.ScheduledJobRun, err error) {
+	end := time.Now()
+	run.EndTime = &end
+	run.Status = model.ScheduleSucceeded
+	if err != nil {
+		run.Status = model.ScheduleFailed
+		if run.Message != "" {
+			run.Message += ": "
+		}
+		run.Message += err.Error()
+		log.Warnf("scheduled job [%s] failed: %+v", job.Name, err)
+	}
+	if err := db.UpdateScheduledJobRun(run); err != nil {
+		log.Errorf("failed update run of scheduled job [%s]: %+v", job.Name, err)
+	}
+	job.LastStatus, job.LastMessage = run.Status, run.Message
+	if err := db.UpdateScheduledJobStatus(job); err != nil {
+		log.Errorf("failed update status of scheduled job [%s]: %+v", job.Name, err)
+	}
+	if err := db.PruneScheduledJobRuns(job.ID, MaxHistory); err != nil {
+		log.Errorf("failed prune runs of scheduled job [%s]: %+v", job.Name, err)
+	}
+}
+
+// waitTasks wait for the tasks and the tasks added by them, e.g. the copy tasks of the objs in a dir,
+// the failed ones are returned as the error
+func waitTasks(ctx context.Context, tasks []task.TaskExtensionInfo) error {
+	waited := make(map[string]struct{}, len(tasks))
+	for _, t := range tasks {
+		waited[t.GetID()] = struct{}{}
+	}
+	var total, failed int
+	var firstErr error
+	for len(tasks) > 0 {
+		total += len(tasks)
+		bases := make([]tache.TaskBase, 0, len(tasks))
+		for _, t := range tasks {
+			bases = append(bases, t)
+		}
+		if err := task.WaitTasks(ctx, bases...); err != nil {
+			return errors.WithMessage(err, "stopped before the tasks finished")
+		}
+		for _, t := range tasks {
+			if err := task.GetTaskErr(t); err != nil {
+				failed++
+				if firstErr == nil {
+					firstErr = errors.WithMessage(err, t.GetName())
+				}
+			}
+		}
+		// the children are added before their parent is finished
+		tasks = nil
+		for _, t := range getTasks() {
+			if _, ok := waited[t.GetParentID()]; !ok {
+				continue
+			}
+			if _, ok := waited[t.GetID()]; !ok {
+				waited[t.GetID()] = struct{}{}
+				tasks = append(tasks, t)
+			}
+		}
+	}
+	if failed > 0 {
+		return errors.WithMessagef(firstErr, "%d of %d tasks failed, the first one", failed, total)
+	}
+	return nil
+}
+
+func execute(runner runner, job *model.ScheduledJob) (msg string, tasks []task.TaskExtensionInfo, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v", r)
+		}
+	}()
+	// the tasks added by the jobs are created by admin
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return "", nil, err
+	}
+	ctx := context.WithValue(context.Background(), "user", admin)
+	return runner.run(ctx, job.Args)
+}
+
+type cronLogger struct{}
+
+func (cronLogger) Info(msg string, keysAndValues ...interface{}) {
+	log.WithField("keys", keysAndValues).Debugf("cron: %s", msg)
+}
+
+func (cronLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	log.WithField("keys", keysAndValues).Errorf("cron: %s: %+v", msg, err)
+}
@@ -0,0 +1,169 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/task"
+	"github.com/xhofe/tache"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+	if err = db.CreateUser(&model.User{Username: "admin", Role: model.ADMIN}); err != nil {
+		panic(err)
+	}
+}
+
+type testTask struct {
+	task.TaskExtension
+}
+
+func (t *testTask) GetName() string {
+	return "test " + t.GetID()
+}
+
+func (t *testTask) GetStatus() string {
+	return ""
+}
+
+func (t *testTask) Run() error {
+	return nil
+}
+
+func newTestTask(id, parentID string) *testTask {
+	t := &testTask{TaskExtension: task.TaskExtension{ParentID: parentID}}
+	t.SetID(id)
+	return t
+}
+
+// testTasks replace the tasks of the managers
+type testTasks struct {
+	sync.Mutex
+	tasks []task.TaskExtensionInfo
+}
+
+func (ts *testTasks) add(t *testTask) {
+	ts.Lock()
+	defer ts.Unlock()
+	ts.tasks = append(ts.tasks, t)
+}
+
+func (ts *testTasks) get() []task.TaskExtensionInfo {
+	ts.Lock()
+	defer ts.Unlock()
+	return append([]task.TaskExtensionInfo(nil), ts.tasks...)
+}
+
+func setupJob(t *testing.T, name string, tasks ...task.TaskExtensionInfo) (*model.ScheduledJob, *testTasks) {
+	all := &testTasks{tasks: tasks}
+	runners[name] = typedRunner[struct{}]{
+		do: func(ctx context.Context, _ *struct{}) (string, []task.TaskExtensionInfo, error) {
+			return tasksMessage(tasks), tasks, nil
+		},
+	}
+	getTasks = all.get
+	job := &model.ScheduledJob{Name: name, Cron: "@every 1h", Type: name}
+	if err := db.CreateScheduledJob(job); err != nil {
+		t.Fatalf("failed create job: %+v", err)
+	}
+	return job, all
+}
+
+// lastRun returns the last run of the job once it's finished
+func lastRun(t *testing.T, job *model.ScheduledJob) model.ScheduledJobRun {
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		runs, _, err := db.GetScheduledJobRuns(job.ID, 1, 1)
+		if err != nil {
+			t.Fatalf("failed get runs: %+v", err)
+		}
+		if len(runs) > 0 && (runs[0].Status != model.ScheduleRunning || time.Now().After(deadline)) {
+			return runs[0]
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunSucceeded(t *testing.T) {
+	done := newTestTask("done", "")
+	done.SetState(tache.StateSucceeded)
+	job, _ := setupJob(t, "succeeded", done)
+	run, err := Run(job.ID)
+	if err != nil {
+		t.Fatalf("failed run job: %+v", err)
+	}
+	if run.Status != model.ScheduleSucceeded || run.EndTime == nil {
+		t.Errorf("the run should be succeeded, got %+v", run)
+	}
+}
+
+func TestRunWaitsForTasks(t *testing.T) {
+	parent := newTestTask("parent", "")
+	job, all := setupJob(t, "wait", parent)
+	run, err := Trigger(job.ID)
+	if err != nil {
+		t.Fatalf("failed trigger job: %+v", err)
+	}
+	if run.Status != model.ScheduleRunning {
+		t.Errorf("the run should be running until the tasks are finished, got %s", run.Status)
+	}
+	if _, err = Trigger(job.ID); err == nil {
+		t.Errorf("the job should not be run again before the last run is finished")
+	}
+
+	// the parent adds a child before it's finished, which is waited for as well
+	child := newTestTask("child", parent.GetID())
+	all.add(child)
+	all.add(newTestTask("other", ""))
+	parent.SetState(tache.StateSucceeded)
+	time.Sleep(1500 * time.Millisecond)
+	if runs, _, _ := db.GetScheduledJobRuns(job.ID, 1, 1); len(runs) == 0 || runs[0].Status != model.ScheduleRunning {
+		t.Errorf("the run should wait for the child, got %+v", runs)
+	}
+	child.SetErr(errors.New("upload failed"))
+	child.SetState(tache.StateFailed)
+	r := lastRun(t, job)
+	if r.Status != model.ScheduleFailed || !strings.Contains(r.Message, "1 of 2 tasks failed") ||
+		!strings.Contains(r.Message, "upload failed") {
+		t.Errorf("the run should be failed with the error of the child, got %s: %s", r.Status, r.Message)
+	}
+	// the job is released right after the run is recorded
+	for i := 0; ; i++ {
+		if _, ok := running.Load(job.ID); !ok {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("the job should be able to run again")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunStopped(t *testing.T) {
+	job, _ := setupJob(t, "stopped", newTestTask("pending", ""))
+	if _, err := Trigger(job.ID); err != nil {
+		t.Fatalf("failed trigger job: %+v", err)
+	}
+	cancelRuns()
+	defer func() {
+		runCtx, cancelRuns = context.WithCancel(context.Background())
+	}()
+	if r := lastRun(t, job); r.Status != model.ScheduleFailed || !strings.Contains(r.Message, "stopped before the tasks finished") {
+		t.Errorf("the run should be failed when stopped, got %s: %s", r.Status, r.Message)
+	}
+}
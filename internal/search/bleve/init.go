@@ -25,7 +25,17 @@ func Init(indexPath *string) (bleve.Index, error) {
 		// TODO: appoint analyzer
 		nameFieldMapping := bleve.NewKeywordFieldMapping()
 		searchNodeMapping.AddFieldMappingsAt("name", nameFieldMapping)
+		searchNodeMapping.AddFieldMappingsAt("content", bleve.NewTextFieldMapping())
 		indexMapping.AddDocumentMapping("SearchNode", searchNodeMapping)
+		// the documents are indexed by the default mapping, the fields are mapped dynamically
+		// except the ones to be filtered and sorted exactly
+		indexMapping.DefaultMapping.AddFieldMappingsAt("size", bleve.NewNumericFieldMapping())
+		indexMapping.DefaultMapping.AddFieldMappingsAt("modified", bleve.NewDateTimeFieldMapping())
+		indexMapping.DefaultMapping.AddFieldMappingsAt("obj_type", bleve.NewNumericFieldMapping())
+		indexMapping.DefaultMapping.AddFieldMappingsAt("ext", bleve.NewKeywordFieldMapping())
+		indexMapping.DefaultMapping.AddFieldMappingsAt("dir", bleve.NewKeywordFieldMapping())
+		indexMapping.DefaultMapping.AddFieldMappingsAt("path", bleve.NewKeywordFieldMapping())
+		indexMapping.DefaultMapping.AddFieldMappingsAt("base", bleve.NewKeywordFieldMapping())
 		fileIndex, err = bleve.New(*indexPath, indexMapping)
 		if err != nil {
 			return nil, err
@@ -3,6 +3,9 @@ package bleve
 import (
 	"context"
 	"os"
+	"path"
+	"strings"
+	"time"
 
 	query2 "github.com/blevesearch/bleve/v2/search/query"
 
@@ -13,7 +16,9 @@ import (
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/blevesearch/bleve/v2"
 	search2 "github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/vellum/regexp"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -21,23 +26,51 @@ type Bleve struct {
 	BIndex bleve.Index
 }
 
+// document is the node indexed, Dir, Path and Base are keywords to filter the nodes by the paths
+// and match the names as a whole, they are missing in the indexes built by the old versions
+type document struct {
+	model.SearchNode
+	Dir  string `json:"dir"`
+	Path string `json:"path"`
+	Base string `json:"base"`
+}
+
+func newDocument(node model.SearchNode) document {
+	return document{
+		SearchNode: node,
+		Dir:        node.Parent,
+		Path:       path.Join(node.Parent, node.Name),
+		Base:       node.Name,
+	}
+}
+
 func (b *Bleve) Config() searcher.Config {
 	return config
 }
 
 func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
-	var queries []query2.Query
-	query := bleve.NewMatchQuery(req.Keywords)
-	query.SetField("name")
-	queries = append(queries, query)
-	if req.Scope != 0 {
-		isDir := req.Scope == 1
-		isDirQuery := bleve.NewBoolFieldQuery(isDir)
-		queries = append(queries, isDirQuery)
+	queries := filterQueries(req)
+	if req.Keywords != "" {
+		q, err := keywordsQuery(req)
+		if err != nil {
+			return nil, 0, err
+		}
+		queries = append(queries, q)
+	}
+	visible, hidden := visibilityQueries(req)
+	queries = append(queries, visible...)
+	if len(queries) == 0 {
+		queries = append(queries, bleve.NewMatchAllQuery())
 	}
-	reqQuery := bleve.NewConjunctionQuery(queries...)
-	search := bleve.NewSearchRequest(reqQuery)
-	search.SortBy([]string{"name"})
+	search := bleve.NewSearchRequest(boolQuery(queries, hidden))
+	sortBy := "name"
+	if req.OrderBy != "" {
+		sortBy = req.OrderBy
+	}
+	if req.OrderDirection == "desc" {
+		sortBy = "-" + sortBy
+	}
+	search.SortBy([]string{sortBy})
 	search.From = (req.Page - 1) * req.PerPage
 	search.Size = req.PerPage
 	search.Fields = []string{"*"}
@@ -47,28 +80,237 @@ func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.Search
 		return nil, 0, err
 	}
 	res, err := utils.SliceConvert(searchResults.Hits, func(src *search2.DocumentMatch) (model.SearchNode, error) {
-		return model.SearchNode{
-			Parent: src.Fields["parent"].(string),
-			Name:   src.Fields["name"].(string),
-			IsDir:  src.Fields["is_dir"].(bool),
-			Size:   int64(src.Fields["size"].(float64)),
-		}, nil
+		// the nodes indexed by the old versions have no such fields
+		content, _ := src.Fields["content"].(string)
+		objType, _ := src.Fields["obj_type"].(float64)
+		ext, _ := src.Fields["ext"].(string)
+		modified, _ := src.Fields["modified"].(string)
+		node := model.SearchNode{
+			Parent:  src.Fields["parent"].(string),
+			Name:    src.Fields["name"].(string),
+			IsDir:   src.Fields["is_dir"].(bool),
+			Size:    int64(src.Fields["size"].(float64)),
+			ObjType: int(objType),
+			Ext:     ext,
+			Content: content,
+		}
+		node.Modified, _ = time.Parse(time.RFC3339Nano, modified)
+		return node, nil
 	})
 	return res, int64(searchResults.Total), nil
 }
 
+// keywordsQuery returns the query of the keywords by the mode, the name is matched as a whole
+// by the keyword field base in the wildcard and regex modes
+func keywordsQuery(req model.SearchReq) (query2.Query, error) {
+	switch req.Mode {
+	case model.SearchPhrase:
+		nameQuery := bleve.NewWildcardQuery("*" + escapeWildcard(req.Keywords) + "*")
+		nameQuery.SetField("base")
+		contentQuery := bleve.NewMatchPhraseQuery(req.Keywords)
+		contentQuery.SetField("content")
+		return bleve.NewDisjunctionQuery(nameQuery, contentQuery), nil
+	case model.SearchWildcard:
+		q := bleve.NewWildcardQuery(req.Keywords)
+		q.SetField("base")
+		return q, nil
+	case model.SearchRegex:
+		re, err := termRegexp(req.Keywords)
+		if err != nil {
+			return nil, errors.WithMessage(err, "the regex is not supported by bleve")
+		}
+		q := bleve.NewRegexpQuery(re)
+		q.SetField("base")
+		return q, nil
+	}
+	nameQuery := bleve.NewMatchQuery(req.Keywords)
+	nameQuery.SetField("name")
+	contentQuery := bleve.NewMatchQuery(req.Keywords)
+	contentQuery.SetField("content")
+	return bleve.NewDisjunctionQuery(nameQuery, contentQuery), nil
+}
+
+// visibilityQueries returns the queries of the nodes in the parent and visible to the user,
+// and the ones of the nodes hidden from the user
+func visibilityQueries(req model.SearchReq) (visible, hidden []query2.Query) {
+	if req.Parent != "" && req.Parent != "/" {
+		visible = append(visible, inDirQuery(req.Parent))
+	}
+	if len(req.Paths) > 0 {
+		var paths []query2.Query
+		for _, p := range req.Paths {
+			if p == "/" {
+				paths = nil
+				break
+			}
+			paths = append(paths, nodeQuery(p), inDirQuery(p))
+			// the parents of the paths can be accessed to walk to them
+			for dir := path.Dir(p); dir != "/"; dir = path.Dir(dir) {
+				paths = append(paths, nodeQuery(dir))
+			}
+		}
+		if len(paths) > 0 {
+			visible = append(visible, bleve.NewDisjunctionQuery(paths...))
+		}
+	}
+	for _, rule := range req.Rules {
+		var region query2.Query = termQuery("dir", rule.Parent)
+		if rule.Sub {
+			var except []query2.Query
+			for _, e := range rule.Except {
+				except = append(except, inDirQuery(e))
+			}
+			region = boolQuery([]query2.Query{inDirQuery(rule.Parent)}, except)
+		}
+		var names []query2.Query
+		for _, name := range rule.Names {
+			// the regexes bleve doesn't support are left to the caller
+			if re, err := termRegexp(name); err == nil {
+				q := bleve.NewRegexpQuery(re)
+				q.SetField("base")
+				names = append(names, q)
+			}
+		}
+		switch {
+		case len(rule.Names) == 0:
+			hidden = append(hidden, region)
+		case len(names) > 0:
+			hidden = append(hidden, bleve.NewConjunctionQuery(region, bleve.NewDisjunctionQuery(names...)))
+		}
+	}
+	for _, hidePath := range req.HidePaths {
+		if re, err := termRegexp(hidePath); err == nil {
+			q := bleve.NewRegexpQuery(re)
+			q.SetField("path")
+			hidden = append(hidden, q)
+		}
+	}
+	return visible, hidden
+}
+
+func boolQuery(must, mustNot []query2.Query) query2.Query {
+	q := bleve.NewBooleanQuery()
+	q.AddMust(must...)
+	q.AddMustNot(mustNot...)
+	return q
+}
+
+func termQuery(field, term string) query2.Query {
+	q := bleve.NewTermQuery(term)
+	q.SetField(field)
+	return q
+}
+
+// inDirQuery returns the query of the nodes in dir and its sub folders
+func inDirQuery(dir string) query2.Query {
+	if dir == "/" {
+		return bleve.NewMatchAllQuery()
+	}
+	prefixQuery := bleve.NewPrefixQuery(dir + "/")
+	prefixQuery.SetField("dir")
+	return bleve.NewDisjunctionQuery(termQuery("dir", dir), prefixQuery)
+}
+
+// nodeQuery returns the query of the node of the path
+func nodeQuery(p string) query2.Query {
+	return bleve.NewConjunctionQuery(termQuery("dir", path.Dir(p)), termQuery("base", path.Base(p)))
+}
+
+// termRegexp returns the regex matching the whole term by re which matches a part of it,
+// since the regex of bleve matches the whole term and doesn't support the zero width assertions
+func termRegexp(re string) (string, error) {
+	prefix, suffix := ".*", ".*"
+	if strings.HasPrefix(re, "^") {
+		re, prefix = re[1:], ""
+	}
+	if strings.HasSuffix(re, "$") && !strings.HasSuffix(re, `\$`) {
+		re, suffix = re[:len(re)-1], ""
+	}
+	re = prefix + "(?:" + re + ")" + suffix
+	if _, err := regexp.New(re); err != nil {
+		return "", err
+	}
+	return re, nil
+}
+
+func filterQueries(req model.SearchReq) []query2.Query {
+	var queries []query2.Query
+	inclusive := true
+	if req.Scope != 0 {
+		isDirQuery := bleve.NewBoolFieldQuery(req.Scope == 1)
+		isDirQuery.SetField("is_dir")
+		queries = append(queries, isDirQuery)
+	}
+	if req.MinSize > 0 || req.MaxSize > 0 {
+		var minSize, maxSize *float64
+		if req.MinSize > 0 {
+			v := float64(req.MinSize)
+			minSize = &v
+		}
+		if req.MaxSize > 0 {
+			v := float64(req.MaxSize)
+			maxSize = &v
+		}
+		sizeQuery := bleve.NewNumericRangeInclusiveQuery(minSize, maxSize, &inclusive, &inclusive)
+		sizeQuery.SetField("size")
+		queries = append(queries, sizeQuery)
+	}
+	if req.ModifiedAfter != nil || req.ModifiedBefore != nil {
+		var start, end time.Time
+		if req.ModifiedAfter != nil {
+			start = *req.ModifiedAfter
+		}
+		if req.ModifiedBefore != nil {
+			end = *req.ModifiedBefore
+		}
+		modifiedQuery := bleve.NewDateRangeInclusiveQuery(start, end, &inclusive, &inclusive)
+		modifiedQuery.SetField("modified")
+		queries = append(queries, modifiedQuery)
+	}
+	if len(req.Exts) > 0 {
+		extQueries := make([]query2.Query, 0, len(req.Exts))
+		for _, ext := range req.Exts {
+			q := bleve.NewTermQuery(ext)
+			q.SetField("ext")
+			extQueries = append(extQueries, q)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(extQueries...))
+	}
+	if len(req.Types) > 0 {
+		typeQueries := make([]query2.Query, 0, len(req.Types))
+		for _, t := range req.Types {
+			v := float64(t)
+			q := bleve.NewNumericRangeInclusiveQuery(&v, &v, &inclusive, &inclusive)
+			q.SetField("obj_type")
+			typeQueries = append(typeQueries, q)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(typeQueries...))
+	}
+	return queries
+}
+
+func escapeWildcard(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`).Replace(s)
+}
+
 func (b *Bleve) Index(ctx context.Context, node model.SearchNode) error {
-	return b.BIndex.Index(uuid.NewString(), node)
+	return b.BIndex.Index(uuid.NewString(), newDocument(node))
 }
 
 func (b *Bleve) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	batch := b.BIndex.NewBatch()
 	for _, node := range nodes {
-		batch.Index(uuid.NewString(), node)
+		batch.Index(uuid.NewString(), newDocument(node))
 	}
 	return b.BIndex.Batch(batch)
 }
 
+// SupportRegexp returns whether the regex is supported by the regexp query of bleve
+func (b *Bleve) SupportRegexp(re string) bool {
+	_, err := termRegexp(re)
+	return err == nil
+}
+
 func (b *Bleve) Get(ctx context.Context, parent string) ([]model.SearchNode, error) {
 	return nil, errs.NotSupport
 }
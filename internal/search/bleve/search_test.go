@@ -0,0 +1,67 @@
+package bleve
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestSearchVisibility(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "index")
+	index, err := Init(&indexPath)
+	if err != nil {
+		t.Fatalf("failed init index: %+v", err)
+	}
+	b := &Bleve{BIndex: index}
+	defer b.Release(context.Background())
+	err = b.BatchIndex(context.Background(), []model.SearchNode{
+		{Parent: "/", Name: "pub", IsDir: true},
+		{Parent: "/pub", Name: "a.txt"},
+		{Parent: "/pub", Name: "secret.txt"},
+		{Parent: "/pub/locked", Name: "b.txt"},
+		{Parent: "/pub/locked/open", Name: "c.txt"},
+		{Parent: "/", Name: "private", IsDir: true},
+		{Parent: "/private", Name: "d.txt"},
+		{Parent: "/", Name: "README.md"},
+	})
+	if err != nil {
+		t.Fatalf("failed index: %+v", err)
+	}
+	tests := []struct {
+		name   string
+		req    model.SearchReq
+		expect []string
+	}{
+		{"all", model.SearchReq{}, []string{"README.md", "a.txt", "b.txt", "c.txt", "d.txt", "private", "pub", "secret.txt"}},
+		{"parent", model.SearchReq{Parent: "/pub/locked"}, []string{"b.txt", "c.txt"}},
+		{"paths", model.SearchReq{Paths: []string{"/pub/locked"}}, []string{"b.txt", "c.txt", "pub"}},
+		{"hide", model.SearchReq{Rules: []model.SearchRule{{Parent: "/pub", Names: []string{"^secret"}}}},
+			[]string{"README.md", "a.txt", "b.txt", "c.txt", "d.txt", "private", "pub"}},
+		{"password", model.SearchReq{Rules: []model.SearchRule{{Parent: "/pub/locked", Sub: true, Except: []string{"/pub/locked/open"}}}},
+			[]string{"README.md", "a.txt", "c.txt", "d.txt", "private", "pub", "secret.txt"}},
+		{"hide paths", model.SearchReq{Parent: "/", HidePaths: []string{`(?i)/readme\.md`}},
+			[]string{"a.txt", "b.txt", "c.txt", "d.txt", "private", "pub", "secret.txt"}},
+		{"regex", model.SearchReq{Keywords: `^[a-c]\.txt$`, Mode: model.SearchRegex}, []string{"a.txt", "b.txt", "c.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Page, req.PerPage = 1, 20
+			nodes, total, err := b.Search(context.Background(), req)
+			if err != nil {
+				t.Fatalf("failed search: %+v", err)
+			}
+			var names []string
+			for _, node := range nodes {
+				names = append(names, node.Name)
+			}
+			slices.Sort(names)
+			if int(total) != len(tt.expect) || !slices.Equal(names, tt.expect) {
+				t.Errorf("expected %v, got %v (total %d)", tt.expect, names, total)
+			}
+		})
+	}
+}
@@ -38,6 +38,9 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 	)
 	log.Infof("build index for: %+v", indexPaths)
 	log.Infof("ignore paths: %+v", ignorePaths)
+	if StorageIndexRunning() {
+		return errs.BuildIndexIsRunning
+	}
 	quit := make(chan struct{}, 1)
 	if !Quit.CompareAndSwap(nil, &quit) {
 		// other goroutine is running
@@ -168,8 +171,9 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 			}
 			indexMQ.Publish(mq.Message[ObjWithParent]{
 				Content: ObjWithParent{
-					Obj:    info,
-					Parent: path.Dir(indexPath),
+					Obj:     info,
+					Parent:  path.Dir(indexPath),
+					Content: getContent(ctx, indexPath, info),
 				},
 			})
 			return nil
@@ -203,7 +207,7 @@ func Update(parent string, objs []model.Obj) {
 	if instance == nil || !instance.Config().AutoUpdate || !setting.GetBool(conf.AutoUpdateIndex) || Running() {
 		return
 	}
-	if isIgnorePath(parent) {
+	if isIgnorePath(parent) || indexJobRunningAt(parent) {
 		return
 	}
 	ctx := context.Background()
@@ -267,6 +271,30 @@ func Update(parent string, objs []model.Obj) {
 	}
 }
 
+// onDirChanged refresh the listing of dir, so that the index is updated by the objs update hook
+func onDirChanged(dir string) {
+	if instance == nil || !instance.Config().AutoUpdate || !setting.GetBool(conf.AutoUpdateIndex) || Running() {
+		return
+	}
+	if isIgnorePath(dir) || indexJobRunningAt(dir) {
+		return
+	}
+	ctx := context.Background()
+	_, err := fs.List(ctx, dir, &fs.ListArgs{Refresh: true, NoLog: true})
+	if err == nil {
+		return
+	}
+	// the dir itself has been removed
+	if errs.IsNotFoundError(err) && !op.HasStorage(dir) {
+		log.Debugf("delete index: %s", dir)
+		err = instance.Del(ctx, dir)
+	}
+	if err != nil {
+		log.Errorf("update search index error while refresh changed dir %s: %+v", dir, err)
+	}
+}
+
 func init() {
 	op.RegisterObjsUpdateHook(Update)
+	op.RegisterDirChangedHook(onDirChanged)
 }
@@ -0,0 +1,72 @@
+package search
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the text extracted from a file is truncated to maxContentLength bytes
+const maxContentLength = 1 << 20
+
+// getContent returns the text of the file at path to be indexed, it's empty if the content of
+// the storage is not indexed, or the file is not supported or too large
+func getContent(ctx context.Context, path string, obj model.Obj) string {
+	if obj.IsDir() || obj.GetSize() <= 0 {
+		return ""
+	}
+	extract := getExtractor(obj.GetName())
+	if extract == nil {
+		return ""
+	}
+	maxSize := int64(setting.GetInt(conf.IndexContentMaxSize, 10)) << 20
+	if obj.GetSize() > maxSize {
+		return ""
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil || !storage.GetStorage().IndexContent {
+		return ""
+	}
+	data, err := readContent(ctx, storage, actualPath, maxSize)
+	if err == nil {
+		var text string
+		text, err = extract(data)
+		if err == nil {
+			return truncateContent(text)
+		}
+	}
+	log.Warnf("failed to extract the content of %s: %+v", path, err)
+	return ""
+}
+
+func readContent(ctx context.Context, storage driver.Driver, actualPath string, maxSize int64) ([]byte, error) {
+	link, obj, err := op.Link(ctx, storage, actualPath, model.LinkArgs{Header: http.Header{}})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get link")
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: obj, Ctx: ctx}, link)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get stream")
+	}
+	defer ss.Close()
+	data, err := io.ReadAll(io.LimitReader(ss, maxSize))
+	return data, errors.WithStack(err)
+}
+
+// truncateContent cut the text to maxContentLength without breaking the last character
+func truncateContent(text string) string {
+	if len(text) <= maxContentLength {
+		return text
+	}
+	return strings.ToValidUTF8(text[:maxContentLength], "")
+}
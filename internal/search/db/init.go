@@ -22,10 +22,12 @@ func init() {
 		switch conf.Conf.Database.Type {
 		case "mysql":
 			tableName := fmt.Sprintf("%ssearch_nodes", conf.Conf.Database.TablePrefix)
-			tx := db.Exec(fmt.Sprintf("CREATE FULLTEXT INDEX idx_%s_name_fulltext ON %s(name);", tableName, tableName))
-			if err := tx.Error; err != nil && !strings.Contains(err.Error(), "Error 1061 (42000)") { // duplicate error
-				log.Errorf("failed to create full text index: %v", err)
-				return nil, err
+			for _, column := range []string{"name", "content"} {
+				tx := db.Exec(fmt.Sprintf("CREATE FULLTEXT INDEX idx_%s_%s_fulltext ON %s(%s);", tableName, column, tableName, column))
+				if err := tx.Error; err != nil && !strings.Contains(err.Error(), "Error 1061 (42000)") { // duplicate error
+					log.Errorf("failed to create full text index: %v", err)
+					return nil, err
+				}
 			}
 		case "postgres":
 			db.Exec("CREATE EXTENSION pg_trgm;")
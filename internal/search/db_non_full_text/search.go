@@ -18,6 +18,11 @@ func (D DB) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode
 	return db.SearchNode(req, false)
 }
 
+// SupportRegexp the regexes are matched by the database
+func (D DB) SupportRegexp(re string) bool {
+	return true
+}
+
 func (D DB) Index(ctx context.Context, node model.SearchNode) error {
 	return db.CreateSearchNode(&node)
 }
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	stdpath "path"
+	"regexp"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/ledongthuc/pdf"
+	"github.com/pkg/errors"
+)
+
+// extractor returns the plain text in the content of a file
+type extractor func(data []byte) (string, error)
+
+var (
+	// the xml files containing the text in the office documents
+	docxFiles = regexp.MustCompile(`^word/(document|header\d*|footer\d*|footnotes|endnotes)\.xml$`)
+	xlsxFiles = regexp.MustCompile(`^xl/(sharedStrings|worksheets/sheet\d+)\.xml$`)
+	pptxFiles = regexp.MustCompile(`^ppt/(slides/slide\d+|notesSlides/notesSlide\d+)\.xml$`)
+	odfFiles  = regexp.MustCompile(`^content\.xml$`)
+
+	extractors = map[string]extractor{
+		".pdf":  extractPDF,
+		".docx": officeExtractor(docxFiles),
+		".xlsx": officeExtractor(xlsxFiles),
+		".pptx": officeExtractor(pptxFiles),
+		".odt":  officeExtractor(odfFiles),
+		".ods":  officeExtractor(odfFiles),
+		".odp":  officeExtractor(odfFiles),
+	}
+)
+
+// getExtractor returns the extractor by the extension of the file, nil if it's not supported
+func getExtractor(name string) extractor {
+	if e, ok := extractors[strings.ToLower(stdpath.Ext(name))]; ok {
+		return e
+	}
+	if utils.GetFileType(name) == conf.TEXT {
+		return extractText
+	}
+	return nil
+}
+
+func extractText(data []byte) (string, error) {
+	return strings.ToValidUTF8(string(data), ""), nil
+}
+
+func extractPDF(data []byte) (text string, err error) {
+	// the pdf lib panics with some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("failed to parse pdf: %v", r)
+		}
+	}()
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	reader, err := r.GetPlainText()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	var buf strings.Builder
+	if _, err = io.Copy(&buf, io.LimitReader(reader, maxContentLength)); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return buf.String(), nil
+}
+
+// officeExtractor returns the extractor of the office documents, which are zip files
+// with the text in the xml files matched by files
+func officeExtractor(files *regexp.Regexp) extractor {
+	return func(data []byte) (string, error) {
+		r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		var buf strings.Builder
+		for _, f := range r.File {
+			if !files.MatchString(f.Name) {
+				continue
+			}
+			if err = extractXMLText(f, &buf); err != nil {
+				return "", errors.WithMessagef(err, "failed to extract %s", f.Name)
+			}
+			if buf.Len() >= maxContentLength {
+				break
+			}
+		}
+		return buf.String(), nil
+	}
+}
+
+// extractXMLText write the character data in the xml file into buf,
+// the paragraphs and cells are separated by spaces
+func extractXMLText(f *zip.File, buf *strings.Builder) error {
+	rc, err := f.Open()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rc.Close()
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			buf.Write(t)
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p", "h", "si", "c", "tc", "br", "tab":
+				buf.WriteByte(' ')
+			}
+		}
+	}
+}
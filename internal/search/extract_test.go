@@ -0,0 +1,49 @@
+package search
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExtractDocx(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, _ := w.Create("word/document.xml")
+	_, _ = f.Write([]byte(`<?xml version="1.0"?><w:document xmlns:w="w"><w:body>` +
+		`<w:p><w:r><w:t>Quarterly</w:t></w:r><w:r><w:t> report</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Revenue &amp; costs</w:t></w:r></w:p></w:body></w:document>`))
+	f, _ = w.Create("word/styles.xml")
+	_, _ = f.Write([]byte(`<styles>ignored</styles>`))
+	_ = w.Close()
+
+	extract := getExtractor("Report.DOCX")
+	if extract == nil {
+		t.Fatalf("expected the extractor of docx")
+	}
+	text, err := extract(buf.Bytes())
+	if err != nil {
+		t.Fatalf("failed extract: %+v", err)
+	}
+	if got := strings.Join(strings.Fields(text), " "); got != "Quarterly report Revenue & costs" {
+		t.Errorf("unexpected text: %q", got)
+	}
+	if getExtractor("a.bin") != nil {
+		t.Errorf("expected no extractor for unknown type")
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	content := strings.Repeat("lorem ipsum ", 20) + "the <Secret> plan\n\tis here " + strings.Repeat("dolor sit ", 30)
+	s := snippet(content, "secret PLAN")
+	if !strings.Contains(s, "&lt;<mark>Secret</mark>&gt; <mark>plan</mark> is here") {
+		t.Errorf("unexpected snippet: %s", s)
+	}
+	if !strings.HasPrefix(s, "…") || !strings.HasSuffix(s, "…") {
+		t.Errorf("expected the snippet to be truncated: %s", s)
+	}
+	if s = snippet(content, "missing"); s != "" {
+		t.Errorf("expected empty snippet, got: %s", s)
+	}
+}
@@ -0,0 +1,353 @@
+package search
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the progress of the running index jobs is saved at most once in indexJobSaveInterval
+const indexJobSaveInterval = 5 * time.Second
+
+type indexJobRun struct {
+	mu     sync.Mutex
+	job    model.IndexJob
+	saved  time.Time
+	cancel context.CancelFunc
+}
+
+var indexJobs generic_sync.MapOf[uint, *indexJobRun]
+
+// StorageIndexRunning returns whether any index job of the storages is running
+func StorageIndexRunning() bool {
+	return !indexJobs.Empty()
+}
+
+// indexJobRunningAt returns whether p is being crawled by an index job, the objs in it
+// needn't be updated by the hooks
+func indexJobRunningAt(p string) bool {
+	running := false
+	indexJobs.Range(func(_ uint, run *indexJobRun) bool {
+		run.mu.Lock()
+		running = utils.IsSubPath(run.job.MountPath, p)
+		run.mu.Unlock()
+		return !running
+	})
+	return running
+}
+
+// GetIndexJobs returns the index jobs of the storages, with the current progress of the running ones
+func GetIndexJobs() ([]model.IndexJob, error) {
+	jobs, err := db.GetIndexJobs()
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs {
+		if run, ok := indexJobs.Load(jobs[i].StorageID); ok {
+			run.mu.Lock()
+			jobs[i] = run.job
+			run.mu.Unlock()
+		}
+	}
+	return jobs, nil
+}
+
+// StartStorageIndex start the index job of the storage in background, the job which hasn't
+// succeeded is resumed after its cursor, otherwise the storage is crawled from the root.
+// Only the objs added, removed or changed since the last index are written to the searcher.
+func StartStorageIndex(storageId uint) error {
+	if instance == nil {
+		return errs.SearchNotAvailable
+	}
+	if !instance.Config().AutoUpdate {
+		return errors.New("update is not supported for current index")
+	}
+	if Running() {
+		return errs.BuildIndexIsRunning
+	}
+	s, err := db.GetStorageById(storageId)
+	if err != nil {
+		return err
+	}
+	storage, err := op.GetStorageByMountPath(s.MountPath)
+	if err != nil {
+		return err
+	}
+	if storage.GetStorage().DisableIndex {
+		return errors.New("index is disabled for the storage")
+	}
+	return startIndexJob(storage)
+}
+
+func startIndexJob(storage driver.Driver) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &indexJobRun{cancel: cancel}
+	id := storage.GetStorage().ID
+	if _, loaded := indexJobs.LoadOrStore(id, run); loaded {
+		cancel()
+		return errors.New("the index job of the storage is running")
+	}
+	now := time.Now()
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	job, err := db.GetIndexJob(id)
+	if err != nil || job.Status == model.IndexJobSucceeded || job.Cursor == "" {
+		job = &model.IndexJob{StorageID: id, Started: &now}
+	}
+	job.MountPath = op.GetVirtualMountPath(storage)
+	job.Status = model.IndexJobRunning
+	job.Error = ""
+	job.Finished = nil
+	run.job = *job
+	if err = db.SaveIndexJob(job); err != nil {
+		indexJobs.Delete(id)
+		cancel()
+		return err
+	}
+	go run.run(ctx)
+	return nil
+}
+
+// StopStorageIndex cancel the running index job of the storage, it can be resumed by StartStorageIndex
+func StopStorageIndex(storageId uint) error {
+	run, ok := indexJobs.Load(storageId)
+	if !ok {
+		return errors.New("the index job of the storage is not running")
+	}
+	run.cancel()
+	return nil
+}
+
+func (r *indexJobRun) run(ctx context.Context) {
+	defer r.cancel()
+	root, cursor := r.job.MountPath, r.job.Cursor
+	if cursor != "" {
+		log.Infof("resume index of %s after %s", root, cursor)
+	} else {
+		log.Infof("start index of %s", root)
+	}
+	admin, err := op.GetAdmin()
+	if err == nil {
+		c := &indexCrawler{
+			ctx:    context.WithValue(ctx, "user", admin),
+			run:    r,
+			cursor: cursor,
+		}
+		err = c.crawl(root, setting.GetInt(conf.MaxIndexDepth, 20)-strings.Count(root, "/"))
+	}
+	now := time.Now()
+	r.update(func(job *model.IndexJob) {
+		job.Finished = &now
+		switch {
+		case err == nil:
+			job.Status = model.IndexJobSucceeded
+			job.Cursor = ""
+		case errors.Is(err, context.Canceled):
+			job.Status = model.IndexJobCanceled
+		default:
+			job.Status = model.IndexJobFailed
+			job.Error = err.Error()
+		}
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Errorf("failed index %s: %+v", root, err)
+	} else {
+		log.Infof("index of %s is %s", root, r.job.Status)
+	}
+	r.save(true)
+	indexJobs.Delete(r.job.StorageID)
+}
+
+func (r *indexJobRun) update(f func(job *model.IndexJob)) {
+	r.mu.Lock()
+	f(&r.job)
+	r.mu.Unlock()
+}
+
+// save the job to db if force or it has not been saved in indexJobSaveInterval
+func (r *indexJobRun) save(force bool) {
+	r.mu.Lock()
+	if !force && time.Since(r.saved) < indexJobSaveInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.saved = time.Now()
+	job := r.job
+	r.mu.Unlock()
+	if err := db.SaveIndexJob(&job); err != nil {
+		log.Errorf("failed save index job of %s: %+v", job.MountPath, err)
+	}
+}
+
+type indexCrawler struct {
+	ctx context.Context
+	run *indexJobRun
+	// the folders up to cursor have been indexed by the last run
+	cursor string
+}
+
+// crawl index the children of dir and crawl its sub folders by name in depth-first order,
+// the other storages mounted in the storage are skipped
+func (c *indexCrawler) crawl(dir string, depth int) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	if depth <= 0 || isIgnorePath(dir) {
+		return nil
+	}
+	done := c.cursor != "" && comparePath(dir, c.cursor) <= 0
+	// the whole sub tree of the folder before the cursor has been indexed
+	if done && !utils.IsSubPath(dir, c.cursor) {
+		return nil
+	}
+	meta, _ := op.GetNearestMeta(dir)
+	objs, err := fs.List(context.WithValue(c.ctx, "meta", meta), dir, &fs.ListArgs{Refresh: true, NoLog: true})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list %s", dir)
+	}
+	if !done {
+		added, removed, err := c.diff(dir, objs)
+		if err != nil {
+			return err
+		}
+		c.run.update(func(job *model.IndexJob) {
+			job.Cursor = dir
+			job.Folders++
+			job.Added += added
+			job.Removed += removed
+		})
+		c.run.save(false)
+	}
+	var dirs []string
+	for _, obj := range objs {
+		if obj.IsDir() {
+			dirs = append(dirs, obj.GetName())
+		}
+	}
+	slices.Sort(dirs)
+	for _, name := range dirs {
+		child := path.Join(dir, name)
+		if op.HasStorage(child) {
+			continue
+		}
+		if err := c.crawl(child, depth-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diff write the objs added, removed or changed in dir by the nodes in the searcher
+func (c *indexCrawler) diff(dir string, objs []model.Obj) (added, removed uint64, err error) {
+	nodes, err := instance.Get(c.ctx, dir)
+	if err != nil {
+		return 0, 0, errors.WithMessagef(err, "failed get index of %s", dir)
+	}
+	old := make(map[string]model.SearchNode, len(nodes))
+	for _, node := range nodes {
+		old[node.Name] = node
+	}
+	var toAdd []ObjWithParent
+	for _, obj := range objs {
+		objPath := path.Join(dir, obj.GetName())
+		if isIgnorePath(objPath) {
+			continue
+		}
+		node, ok := old[obj.GetName()]
+		delete(old, obj.GetName())
+		if ok {
+			if !nodeChanged(node, obj) {
+				continue
+			}
+			if err = instance.Del(c.ctx, objPath); err != nil {
+				return added, removed, errors.WithMessagef(err, "failed delete index of %s", objPath)
+			}
+			removed++
+		}
+		toAdd = append(toAdd, ObjWithParent{
+			Parent:  dir,
+			Obj:     obj,
+			Content: getContent(c.ctx, objPath, obj),
+		})
+	}
+	for name := range old {
+		objPath := path.Join(dir, name)
+		if op.HasStorage(objPath) {
+			continue
+		}
+		if err = instance.Del(c.ctx, objPath); err != nil {
+			return added, removed, errors.WithMessagef(err, "failed delete index of %s", objPath)
+		}
+		removed++
+	}
+	if err = BatchIndex(c.ctx, toAdd); err != nil {
+		return added, removed, errors.WithMessagef(err, "failed index children of %s", dir)
+	}
+	return added + uint64(len(toAdd)), removed, nil
+}
+
+// nodeChanged returns whether the obj is changed since it's indexed as node,
+// the modified time is compared in seconds as some searchers don't keep the rest
+func nodeChanged(node model.SearchNode, obj model.Obj) bool {
+	if node.IsDir != obj.IsDir() {
+		return true
+	}
+	if obj.IsDir() {
+		return false
+	}
+	if node.Size != obj.GetSize() {
+		return true
+	}
+	// the nodes indexed by the old versions have no modified time
+	return !node.Modified.IsZero() && node.Modified.Unix() != obj.ModTime().Unix()
+}
+
+// comparePath compare the paths in the order of the crawl, in which a folder is before its
+// children and the children are sorted by name
+func comparePath(a, b string) int {
+	return slices.Compare(strings.Split(strings.TrimSuffix(a, "/"), "/"),
+		strings.Split(strings.TrimSuffix(b, "/"), "/"))
+}
+
+// resumeIndexJob resume the index job of the storage which was interrupted by the exit
+func resumeIndexJob(storage driver.Driver) {
+	job, err := db.GetIndexJob(storage.GetStorage().ID)
+	if err != nil || job.Status != model.IndexJobRunning || indexJobs.Has(job.StorageID) {
+		return
+	}
+	if instance == nil || !instance.Config().AutoUpdate || storage.GetStorage().DisableIndex {
+		return
+	}
+	if err = startIndexJob(storage); err != nil {
+		log.Errorf("failed resume index job of %s: %+v", job.MountPath, err)
+	}
+}
+
+func init() {
+	op.RegisterStorageHook(func(typ string, storage driver.Driver) {
+		switch typ {
+		case "add":
+			resumeIndexJob(storage)
+		case "del":
+			if run, ok := indexJobs.Load(storage.GetStorage().ID); ok {
+				run.cancel()
+			}
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+	if err = db.CreateUser(&model.User{Username: "admin", Role: model.ADMIN}); err != nil {
+		panic(err)
+	}
+	if err = Init("database"); err != nil {
+		panic(err)
+	}
+}
+
+func TestComparePath(t *testing.T) {
+	// the folders in the order of the crawl
+	crawled := []string{"/", "/a", "/a/b", "/a/b/c", "/a/c", "/a b", "/a-b", "/b", "/b/a"}
+	for i := range crawled {
+		for j := range crawled {
+			got := comparePath(crawled[i], crawled[j])
+			if (i < j && got >= 0) || (i == j && got != 0) || (i > j && got <= 0) {
+				t.Errorf("comparePath(%s, %s) = %d", crawled[i], crawled[j], got)
+			}
+		}
+	}
+}
+
+func writeFile(t *testing.T, root, name, content string) {
+	p := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupIndexStorage mount a local storage with the files at mountPath
+func setupIndexStorage(t *testing.T, mountPath string, files map[string]string) (string, uint) {
+	root := t.TempDir()
+	for name, content := range files {
+		writeFile(t, root, name, content)
+	}
+	addition, _ := utils.Json.MarshalToString(map[string]string{"root_folder_path": root})
+	id, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: mountPath, Addition: addition})
+	if err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+	t.Cleanup(func() { _ = op.DeleteStorageById(context.Background(), id) })
+	return root, id
+}
+
+// waitIndexJob wait for the index job of the storage to be finished
+func waitIndexJob(t *testing.T, id uint) *model.IndexJob {
+	deadline := time.Now().Add(10 * time.Second)
+	for indexJobs.Has(id) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for the index job")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	job, err := db.GetIndexJob(id)
+	if err != nil {
+		t.Fatalf("failed get index job: %+v", err)
+	}
+	return job
+}
+
+// indexed returns the names of the nodes in the parent, the files are suffixed by their size
+func indexed(t *testing.T, parent string) []string {
+	nodes, err := instance.Get(context.Background(), parent)
+	if err != nil {
+		t.Fatalf("failed get nodes of %s: %+v", parent, err)
+	}
+	var names []string
+	for _, n := range nodes {
+		if n.IsDir {
+			names = append(names, n.Name+"/")
+		} else {
+			names = append(names, fmt.Sprintf("%s:%d", n.Name, n.Size))
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func checkIndexed(t *testing.T, parent string, expected ...string) {
+	if got := indexed(t, parent); !slices.Equal(got, expected) {
+		t.Errorf("the nodes of %s should be %v, got %v", parent, expected, got)
+	}
+}
+
+func TestIndexJobDiff(t *testing.T) {
+	root, id := setupIndexStorage(t, "/diff", map[string]string{
+		"a/x.txt": "x",
+		"b/y.txt": "y",
+		"top.txt": "top",
+	})
+	if err := StartStorageIndex(id); err != nil {
+		t.Fatalf("failed start index: %+v", err)
+	}
+	job := waitIndexJob(t, id)
+	if job.Status != model.IndexJobSucceeded || job.Folders != 3 || job.Added != 5 || job.Removed != 0 {
+		t.Fatalf("the first run should index all the objs, got %+v", job)
+	}
+	checkIndexed(t, "/diff", "a/", "b/", "top.txt:3")
+
+	// only the changes since the last run are written
+	writeFile(t, root, "a/x.txt", "xxxx")
+	writeFile(t, root, "c/new.txt", "new")
+	if err := os.RemoveAll(filepath.Join(root, "b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := StartStorageIndex(id); err != nil {
+		t.Fatalf("failed start index: %+v", err)
+	}
+	job = waitIndexJob(t, id)
+	// x.txt is replaced, b is removed, c and new.txt are added
+	if job.Status != model.IndexJobSucceeded || job.Folders != 3 || job.Added != 3 || job.Removed != 2 {
+		t.Errorf("the second run should only write the changes, got %+v", job)
+	}
+	checkIndexed(t, "/diff", "a/", "c/", "top.txt:3")
+	checkIndexed(t, "/diff/a", "x.txt:4")
+	checkIndexed(t, "/diff/c", "new.txt:3")
+}
+
+func TestIndexJobResume(t *testing.T) {
+	root, id := setupIndexStorage(t, "/resume", map[string]string{
+		"a/x.txt":   "x",
+		"b/y.txt":   "y",
+		"b/d/z.txt": "z",
+		"c/w.txt":   "w",
+	})
+	if err := StartStorageIndex(id); err != nil {
+		t.Fatalf("failed start index: %+v", err)
+	}
+	waitIndexJob(t, id)
+
+	// the job was interrupted by the exit after the children of /resume/b were indexed
+	writeFile(t, root, "a/x.txt", "changed before the cursor")
+	writeFile(t, root, "b/d/z.txt", "zz")
+	writeFile(t, root, "c/w.txt", "www")
+	if err := db.SaveIndexJob(&model.IndexJob{
+		StorageID: id,
+		MountPath: "/resume",
+		Status:    model.IndexJobRunning,
+		Cursor:    "/resume/b",
+		Folders:   3,
+		Added:     5,
+	}); err != nil {
+		t.Fatalf("failed save index job: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/resume")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	resumeIndexJob(storage)
+	job := waitIndexJob(t, id)
+	// only /resume/b/d and /resume/c after the cursor are crawled
+	if job.Status != model.IndexJobSucceeded || job.Cursor != "" || job.Folders != 5 || job.Added != 7 || job.Removed != 2 {
+		t.Errorf("the job should be resumed after the cursor, got %+v", job)
+	}
+	checkIndexed(t, "/resume/a", "x.txt:1")
+	checkIndexed(t, "/resume/b/d", "z.txt:2")
+	checkIndexed(t, "/resume/c", "w.txt:3")
+
+	// the job which has succeeded is started from the root
+	if err = StartStorageIndex(id); err != nil {
+		t.Fatalf("failed start index: %+v", err)
+	}
+	job = waitIndexJob(t, id)
+	if job.Status != model.IndexJobSucceeded || job.Folders != 5 || job.Added != 1 || job.Removed != 1 {
+		t.Errorf("the job should be started from the root, got %+v", job)
+	}
+	checkIndexed(t, "/resume/a", "x.txt:25")
+}
@@ -24,8 +24,9 @@ func init() {
 				meilisearch.WithAPIKey(conf.Conf.Meilisearch.APIKey),
 			),
 			IndexUid:             conf.Conf.Meilisearch.IndexPrefix + "alist",
-			FilterableAttributes: []string{"parent", "is_dir", "name"},
-			SearchableAttributes: []string{"name"},
+			FilterableAttributes: []string{"parent", "is_dir", "name", "size", "modified_at", "obj_type", "ext", "parents"},
+			SearchableAttributes: []string{"name", "content"},
+			SortableAttributes:   []string{"name", "size", "modified_at"},
 		}
 
 		_, err := m.Client.GetIndex(m.IndexUid)
@@ -73,6 +74,17 @@ func init() {
 			}
 		}
 
+		attributes, err = m.Client.Index(m.IndexUid).GetSortableAttributes()
+		if err != nil {
+			return nil, err
+		}
+		if attributes == nil || !utils.SliceAllContains(*attributes, m.SortableAttributes...) {
+			_, err = m.Client.Index(m.IndexUid).UpdateSortableAttributes(&m.SortableAttributes)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		pagination, err := m.Client.Index(m.IndexUid).GetPagination()
 		if err != nil {
 			return nil, err
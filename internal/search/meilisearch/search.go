@@ -3,12 +3,15 @@ package meilisearch
 import (
 	"context"
 	"fmt"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/meilisearch/meilisearch-go"
+	"github.com/pkg/errors"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,6 +19,10 @@ import (
 type searchDocument struct {
 	ID string `json:"id"`
 	model.SearchNode
+	// ModifiedAt is the unix time of Modified, which can be filtered and sorted by meilisearch
+	ModifiedAt int64 `json:"modified_at"`
+	// Parents are the parent and all its parents, to filter the nodes under a folder
+	Parents []string `json:"parents"`
 }
 
 type Meilisearch struct {
@@ -23,6 +30,7 @@ type Meilisearch struct {
 	IndexUid             string
 	FilterableAttributes []string
 	SearchableAttributes []string
+	SortableAttributes   []string
 }
 
 func (m *Meilisearch) Config() searcher.Config {
@@ -30,26 +38,58 @@ func (m *Meilisearch) Config() searcher.Config {
 }
 
 func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
+	keywords := req.Keywords
+	switch req.Mode {
+	case model.SearchPhrase:
+		if keywords != "" {
+			keywords = `"` + strings.ReplaceAll(keywords, `"`, "") + `"`
+		}
+	case model.SearchWildcard, model.SearchRegex:
+		return nil, 0, errors.WithMessagef(errs.NotSupport, "%s mode of meilisearch", req.Mode)
+	}
 	mReq := &meilisearch.SearchRequest{
 		AttributesToSearchOn: m.SearchableAttributes,
 		Page:                 int64(req.Page),
 		HitsPerPage:          int64(req.PerPage),
 	}
-	if req.Scope != 0 {
-		mReq.Filter = fmt.Sprintf("is_dir = %v", req.Scope == 1)
+	if filter := buildFilter(req); filter != "" {
+		mReq.Filter = filter
+	}
+	if req.OrderBy != "" {
+		field := req.OrderBy
+		if field == "modified" {
+			field = "modified_at"
+		}
+		direction := "asc"
+		if req.OrderDirection == "desc" {
+			direction = "desc"
+		}
+		mReq.Sort = []string{field + ":" + direction}
 	}
-	search, err := m.Client.Index(m.IndexUid).Search(req.Keywords, mReq)
+	search, err := m.Client.Index(m.IndexUid).Search(keywords, mReq)
 	if err != nil {
 		return nil, 0, err
 	}
 	nodes, err := utils.SliceConvert(search.Hits, func(src any) (model.SearchNode, error) {
 		srcMap := src.(map[string]any)
-		return model.SearchNode{
-			Parent: srcMap["parent"].(string),
-			Name:   srcMap["name"].(string),
-			IsDir:  srcMap["is_dir"].(bool),
-			Size:   int64(srcMap["size"].(float64)),
-		}, nil
+		// the documents indexed by the old versions have no such fields
+		content, _ := srcMap["content"].(string)
+		objType, _ := srcMap["obj_type"].(float64)
+		ext, _ := srcMap["ext"].(string)
+		modifiedAt, _ := srcMap["modified_at"].(float64)
+		node := model.SearchNode{
+			Parent:  srcMap["parent"].(string),
+			Name:    srcMap["name"].(string),
+			IsDir:   srcMap["is_dir"].(bool),
+			Size:    int64(srcMap["size"].(float64)),
+			ObjType: int(objType),
+			Ext:     ext,
+			Content: content,
+		}
+		if modifiedAt != 0 {
+			node.Modified = time.Unix(int64(modifiedAt), 0)
+		}
+		return node, nil
 	})
 	if err != nil {
 		return nil, 0, err
@@ -57,6 +97,83 @@ func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.
 	return nodes, search.TotalHits, nil
 }
 
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "\\'") + "'"
+}
+
+// inDirFilter returns the filter of the nodes in dir and its sub folders
+func inDirFilter(dir string) string {
+	return "parents = " + quote(dir)
+}
+
+// nodeFilter returns the filter of the node of the path
+func nodeFilter(p string) string {
+	return fmt.Sprintf("(parent = %s AND name = %s)", quote(path.Dir(p)), quote(path.Base(p)))
+}
+
+// buildFilter returns the filter expression of the filters and the visibility in req,
+// the regexes of the names and paths hidden are not supported, which are left to the caller
+func buildFilter(req model.SearchReq) string {
+	var filters []string
+	if req.Parent != "" && req.Parent != "/" {
+		filters = append(filters, inDirFilter(req.Parent))
+	}
+	if len(req.Paths) > 0 && !utils.SliceContains(req.Paths, "/") {
+		var paths []string
+		for _, p := range req.Paths {
+			paths = append(paths, nodeFilter(p), inDirFilter(p))
+			// the parents of the paths can be accessed to walk to them
+			for dir := path.Dir(p); dir != "/"; dir = path.Dir(dir) {
+				paths = append(paths, nodeFilter(dir))
+			}
+		}
+		filters = append(filters, "("+strings.Join(paths, " OR ")+")")
+	}
+	for _, rule := range req.Rules {
+		if len(rule.Names) > 0 {
+			continue
+		}
+		region := "parent = " + quote(rule.Parent)
+		if rule.Sub {
+			region = inDirFilter(rule.Parent)
+			for _, e := range rule.Except {
+				region += " AND NOT " + inDirFilter(e)
+			}
+		}
+		filters = append(filters, "NOT ("+region+")")
+	}
+	if req.Scope != 0 {
+		filters = append(filters, fmt.Sprintf("is_dir = %v", req.Scope == 1))
+	}
+	if req.MinSize > 0 {
+		filters = append(filters, fmt.Sprintf("size >= %d", req.MinSize))
+	}
+	if req.MaxSize > 0 {
+		filters = append(filters, fmt.Sprintf("size <= %d", req.MaxSize))
+	}
+	if req.ModifiedAfter != nil {
+		filters = append(filters, fmt.Sprintf("modified_at >= %d", req.ModifiedAfter.Unix()))
+	}
+	if req.ModifiedBefore != nil {
+		filters = append(filters, fmt.Sprintf("modified_at <= %d", req.ModifiedBefore.Unix()))
+	}
+	if len(req.Exts) > 0 {
+		exts := make([]string, len(req.Exts))
+		for i, ext := range req.Exts {
+			exts[i] = quote(ext)
+		}
+		filters = append(filters, fmt.Sprintf("ext IN [%s]", strings.Join(exts, ",")))
+	}
+	if len(req.Types) > 0 {
+		types := make([]string, len(req.Types))
+		for i, t := range req.Types {
+			types[i] = strconv.Itoa(t)
+		}
+		filters = append(filters, fmt.Sprintf("obj_type IN [%s]", strings.Join(types, ",")))
+	}
+	return strings.Join(filters, " AND ")
+}
+
 func (m *Meilisearch) Index(ctx context.Context, node model.SearchNode) error {
 	return m.BatchIndex(ctx, []model.SearchNode{node})
 }
@@ -64,10 +181,20 @@ func (m *Meilisearch) Index(ctx context.Context, node model.SearchNode) error {
 func (m *Meilisearch) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	documents, _ := utils.SliceConvert(nodes, func(src model.SearchNode) (*searchDocument, error) {
 
-		return &searchDocument{
+		document := &searchDocument{
 			ID:         uuid.NewString(),
 			SearchNode: src,
-		}, nil
+		}
+		for dir := src.Parent; ; dir = path.Dir(dir) {
+			document.Parents = append(document.Parents, dir)
+			if dir == "/" {
+				break
+			}
+		}
+		if !src.Modified.IsZero() {
+			document.ModifiedAt = src.Modified.Unix()
+		}
+		return document, nil
 	})
 
 	_, err := m.Client.Index(m.IndexUid).AddDocuments(documents)
@@ -3,12 +3,15 @@ package search
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -48,24 +51,70 @@ func Init(mode string) error {
 }
 
 func Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
-	return instance.Search(ctx, req)
+	nodes, total, err := instance.Search(ctx, req)
+	if err != nil {
+		return nil, 0, err
+	}
+	// the content is too large to be returned
+	for i := range nodes {
+		if nodes[i].Content != "" {
+			nodes[i].Snippet = snippet(nodes[i].Content, req.Keywords)
+			nodes[i].Content = ""
+		}
+	}
+	return nodes, total, nil
+}
+
+// FilteredNatively returns whether the visibility in req is applied by the searcher entirely,
+// otherwise the nodes should be checked again by the caller
+func FilteredNatively(req model.SearchReq) bool {
+	s, ok := instance.(searcher.RegexpSupporter)
+	supported := func(re string) bool {
+		return ok && s.SupportRegexp(re)
+	}
+	for _, rule := range req.Rules {
+		for _, name := range rule.Names {
+			if !supported(name) {
+				return false
+			}
+		}
+	}
+	for _, hidePath := range req.HidePaths {
+		if !supported(hidePath) {
+			return false
+		}
+	}
+	return true
 }
 
 func Index(ctx context.Context, parent string, obj model.Obj) error {
 	if instance == nil {
 		return errs.SearchNotAvailable
 	}
-	return instance.Index(ctx, model.SearchNode{
-		Parent: parent,
-		Name:   obj.GetName(),
-		IsDir:  obj.IsDir(),
-		Size:   obj.GetSize(),
-	})
+	return instance.Index(ctx, newSearchNode(parent, obj, getContent(ctx, path.Join(parent, obj.GetName()), obj)))
+}
+
+func newSearchNode(parent string, obj model.Obj, content string) model.SearchNode {
+	node := model.SearchNode{
+		Parent:   parent,
+		Name:     obj.GetName(),
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		ObjType:  utils.GetObjType(obj.GetName(), obj.IsDir()),
+		Content:  content,
+	}
+	if !obj.IsDir() {
+		node.Ext = strings.ToLower(strings.TrimPrefix(path.Ext(obj.GetName()), "."))
+	}
+	return node
 }
 
 type ObjWithParent struct {
 	Parent string
 	model.Obj
+	// Content is the text extracted to be indexed, it's empty if not supported
+	Content string
 }
 
 func BatchIndex(ctx context.Context, objs []ObjWithParent) error {
@@ -77,12 +126,7 @@ func BatchIndex(ctx context.Context, objs []ObjWithParent) error {
 	}
 	var searchNodes []model.SearchNode
 	for i := range objs {
-		searchNodes = append(searchNodes, model.SearchNode{
-			Parent: objs[i].Parent,
-			Name:   objs[i].GetName(),
-			IsDir:  objs[i].IsDir(),
-			Size:   objs[i].GetSize(),
-		})
+		searchNodes = append(searchNodes, newSearchNode(objs[i].Parent, objs[i].Obj, objs[i].Content))
 	}
 	return instance.BatchIndex(ctx, searchNodes)
 }
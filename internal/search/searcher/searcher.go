@@ -29,3 +29,9 @@ type Searcher interface {
 	// Clear all index
 	Clear(ctx context.Context) error
 }
+
+// RegexpSupporter is implemented by the searchers which hide the nodes by the regexes of
+// SearchRule.Names and SearchReq.HidePaths natively, the unsupported ones are left to the caller
+type RegexpSupporter interface {
+	SupportRegexp(re string) bool
+}
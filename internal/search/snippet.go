@@ -0,0 +1,69 @@
+package search
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// the runes kept before the first keyword matched in the snippet
+	snippetBefore = 40
+	snippetLength = 160
+)
+
+// snippet returns the part of content around the first keyword matched, the keywords in it are
+// highlighted by <mark> and the rest is html escaped, it's empty if no keyword is in the content
+func snippet(content, keywords string) string {
+	var words [][]rune
+	for _, w := range strings.Fields(keywords) {
+		words = append(words, []rune(strings.ToLower(w)))
+	}
+	runes := []rune(strings.Join(strings.Fields(content), " "))
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	// returns the length of the longest keyword at i, 0 if none
+	match := func(i int) int {
+		n := 0
+		for _, w := range words {
+			if len(w) > n && i+len(w) <= len(lower) && slices.Equal(lower[i:i+len(w)], w) {
+				n = len(w)
+			}
+		}
+		return n
+	}
+	first := -1
+	for i := range lower {
+		if match(i) > 0 {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+	start := max(first-snippetBefore, 0)
+	end := min(start+snippetLength, len(runes))
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		if n := match(i); n > 0 {
+			b.WriteString("<mark>")
+			b.WriteString(html.EscapeString(string(runes[i : i+n])))
+			b.WriteString("</mark>")
+			i += n
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		i++
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -59,10 +59,10 @@ func updateIgnorePaths(customIgnorePaths string) {
 				}
 				log.Debugf("%s allow_indexed: %v", addition.Address, allowIndexed)
 				if !allowIndexed {
-					ignorePaths = append(ignorePaths, storage.GetStorage().MountPath)
+					ignorePaths = append(ignorePaths, op.GetVirtualMountPath(storage))
 				}
 			} else {
-				ignorePaths = append(ignorePaths, storage.GetStorage().MountPath)
+				ignorePaths = append(ignorePaths, op.GetVirtualMountPath(storage))
 			}
 		}
 	}
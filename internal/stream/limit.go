@@ -2,6 +2,7 @@ package stream
 
 import (
 	"context"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -34,6 +35,30 @@ var (
 	ServerUploadLimit   Limiter
 )
 
+// limiterName returns the name of the limiter in metrics
+func limiterName(l Limiter) string {
+	switch l {
+	case ClientDownloadLimit:
+		return "client_download"
+	case ClientUploadLimit:
+		return "client_upload"
+	case ServerDownloadLimit:
+		return "server_download"
+	case ServerUploadLimit:
+		return "server_upload"
+	}
+	return "other"
+}
+
+// waitN wait for n bytes and count them in metrics
+func waitN(ctx context.Context, l Limiter, n int) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	metrics.RateLimitedBytes.WithLabelValues(limiterName(l)).Add(float64(n))
+	return l.WaitN(ctx, n)
+}
+
 type RateLimitReader struct {
 	io.Reader
 	Limiter Limiter
@@ -49,10 +74,7 @@ func (r *RateLimitReader) Read(p []byte) (n int, err error) {
 		return
 	}
 	if r.Limiter != nil {
-		if r.Ctx == nil {
-			r.Ctx = context.Background()
-		}
-		err = r.Limiter.WaitN(r.Ctx, n)
+		err = waitN(r.Ctx, r.Limiter, n)
 	}
 	return
 }
@@ -79,10 +101,7 @@ func (w *RateLimitWriter) Write(p []byte) (n int, err error) {
 		return
 	}
 	if w.Limiter != nil {
-		if w.Ctx == nil {
-			w.Ctx = context.Background()
-		}
-		err = w.Limiter.WaitN(w.Ctx, n)
+		err = waitN(w.Ctx, w.Limiter, n)
 	}
 	return
 }
@@ -109,10 +128,7 @@ func (r *RateLimitFile) Read(p []byte) (n int, err error) {
 		return
 	}
 	if r.Limiter != nil {
-		if r.Ctx == nil {
-			r.Ctx = context.Background()
-		}
-		err = r.Limiter.WaitN(r.Ctx, n)
+		err = waitN(r.Ctx, r.Limiter, n)
 	}
 	return
 }
@@ -126,10 +142,7 @@ func (r *RateLimitFile) ReadAt(p []byte, off int64) (n int, err error) {
 		return
 	}
 	if r.Limiter != nil {
-		if r.Ctx == nil {
-			r.Ctx = context.Background()
-		}
-		err = r.Limiter.WaitN(r.Ctx, n)
+		err = waitN(r.Ctx, r.Limiter, n)
 	}
 	return
 }
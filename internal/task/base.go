@@ -2,9 +2,13 @@ package task
 
 import (
 	"context"
+	"errors"
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/event"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/tracing"
 	"github.com/xhofe/tache"
+	"go.opentelemetry.io/otel/attribute"
 	"sync"
 	"time"
 )
@@ -17,6 +21,8 @@ type TaskExtension struct {
 	startTime    *time.Time
 	endTime      *time.Time
 	totalBytes   int64
+	// ParentID is the id of the task which added this one, e.g. the copy task of the dir
+	ParentID string `json:"parent_id,omitempty"`
 }
 
 func (t *TaskExtension) SetCreator(creator *model.User) {
@@ -28,6 +34,10 @@ func (t *TaskExtension) GetCreator() *model.User {
 	return t.Creator
 }
 
+func (t *TaskExtension) GetParentID() string {
+	return t.ParentID
+}
+
 func (t *TaskExtension) SetStartTime(startTime time.Time) {
 	t.startTime = &startTime
 }
@@ -67,6 +77,46 @@ func (t *TaskExtension) Ctx() context.Context {
 	return t.ctx
 }
 
+// StartRun start the span of a run of the task and publish the task state as running, Ctx returns
+// the ctx of the span until the returned func is called with the result of the run
+func (t *TaskExtension) StartRun(kind, name string) func(err *error) {
+	prev := t.Ctx()
+	ctx, span := tracing.Start(prev, "task."+kind,
+		attribute.String("alist.task.id", t.GetID()),
+		attribute.String("alist.task.name", name),
+	)
+	t.ctx = ctx
+	t.publishState(kind, name, "running", nil)
+	return func(err *error) {
+		t.ctx = prev
+		tracing.End(span, *err)
+		state := "succeeded"
+		if *err != nil {
+			state = "failed"
+			if errors.Is(*err, context.Canceled) || t.Base.Ctx().Err() != nil {
+				state = "canceled"
+			}
+		}
+		t.publishState(kind, name, state, *err)
+	}
+}
+
+func (t *TaskExtension) publishState(kind, name, state string, err error) {
+	data := event.TaskData{
+		ID:    t.GetID(),
+		Kind:  kind,
+		Name:  name,
+		State: state,
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	if t.Creator != nil {
+		data.User = t.Creator.Username
+	}
+	event.Publish(event.TaskStateChanged, data)
+}
+
 func (t *TaskExtension) ReinitCtx() {
 	if !conf.Conf.Tasks.AllowRetryCanceled {
 		return
@@ -84,7 +134,14 @@ func (t *TaskExtension) ReinitCtx() {
 type TaskExtensionInfo interface {
 	tache.TaskWithInfo
 	GetCreator() *model.User
+	GetParentID() string
 	GetStartTime() *time.Time
 	GetEndTime() *time.Time
 	GetTotalBytes() int64
 }
+
+// TaskWithDetail is implemented by the tasks which have more to report than the status,
+// the detail is only returned by the info api
+type TaskWithDetail interface {
+	GetDetail() any
+}
@@ -0,0 +1,92 @@
+package task
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// UploadCheckpoint is a driver.UploadCheckpoint saved in the persisted json of task,
+// so the upload can be resumed when the task is retried or restored after restart
+type UploadCheckpoint struct {
+	mu sync.Mutex
+	// key identifies the file being uploaded, the state of another file is never loaded
+	key     string
+	state   json.RawMessage
+	persist func()
+}
+
+type uploadCheckpointJson struct {
+	Key   string          `json:"key"`
+	State json.RawMessage `json:"state,omitempty"`
+}
+
+func (c *UploadCheckpoint) MarshalJSON() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return utils.Json.Marshal(uploadCheckpointJson{Key: c.key, State: c.state})
+}
+
+func (c *UploadCheckpoint) UnmarshalJSON(data []byte) error {
+	var j uploadCheckpointJson
+	if err := utils.Json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.key, c.state = j.Key, j.State
+	return nil
+}
+
+// Bind the checkpoint to the file identified by key, the state saved for another key is discarded.
+// persist is called after the state is changed
+func (c *UploadCheckpoint) Bind(key string, persist func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.key != key {
+		c.key = key
+		c.state = nil
+	}
+	c.persist = persist
+}
+
+func (c *UploadCheckpoint) Load(v any) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.state) == 0 {
+		return false
+	}
+	if err := utils.Json.Unmarshal(c.state, v); err != nil {
+		log.Warnf("failed to load upload checkpoint of %s: %+v", c.key, err)
+		return false
+	}
+	return true
+}
+
+func (c *UploadCheckpoint) Save(v any) {
+	data, err := utils.Json.Marshal(v)
+	if err != nil {
+		log.Warnf("failed to save upload checkpoint of %s: %+v", c.key, err)
+		return
+	}
+	c.set(data)
+}
+
+func (c *UploadCheckpoint) Clear() {
+	c.set(nil)
+}
+
+func (c *UploadCheckpoint) set(state json.RawMessage) {
+	c.mu.Lock()
+	c.state = state
+	persist := c.persist
+	c.mu.Unlock()
+	if persist != nil {
+		persist()
+	}
+}
+
+var _ driver.UploadCheckpoint = (*UploadCheckpoint)(nil)
@@ -0,0 +1,73 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type uploadState struct {
+	UploadId string `json:"upload_id"`
+	Parts    []int  `json:"parts"`
+}
+
+func TestUploadCheckpoint(t *testing.T) {
+	type persistedTask struct {
+		Checkpoint *UploadCheckpoint `json:"checkpoint,omitempty"`
+	}
+	var saved []byte
+	tsk := &persistedTask{Checkpoint: &UploadCheckpoint{}}
+	persist := func() {
+		var err error
+		if saved, err = utils.Json.Marshal(tsk); err != nil {
+			t.Fatalf("failed to persist task: %+v", err)
+		}
+	}
+	tsk.Checkpoint.Bind("/a.txt|5", persist)
+	var state uploadState
+	if tsk.Checkpoint.Load(&state) {
+		t.Errorf("new checkpoint should have no state")
+	}
+	ctx := driver.WithUploadCheckpoint(context.Background(), tsk.Checkpoint)
+	if !driver.HasUploadCheckpoint(ctx) {
+		t.Fatalf("checkpoint should be found in ctx")
+	}
+	driver.GetUploadCheckpoint(ctx).Save(uploadState{UploadId: "id", Parts: []int{1, 2}})
+	if saved == nil {
+		t.Fatalf("task should be persisted after the state is saved")
+	}
+
+	// the task is restored after restart
+	restored := &persistedTask{}
+	if err := utils.Json.Unmarshal(saved, restored); err != nil {
+		t.Fatalf("failed to restore task: %+v", err)
+	}
+	restored.Checkpoint.Bind("/a.txt|5", nil)
+	if !restored.Checkpoint.Load(&state) || state.UploadId != "id" || len(state.Parts) != 2 {
+		t.Errorf("the state should be resumed, got %+v", state)
+	}
+
+	// the state of another file is discarded
+	restored.Checkpoint.Bind("/a.txt|6", nil)
+	if restored.Checkpoint.Load(&state) {
+		t.Errorf("the state of the changed file should be discarded")
+	}
+
+	tsk.Checkpoint.Clear()
+	restored = &persistedTask{}
+	if err := utils.Json.Unmarshal(saved, restored); err != nil {
+		t.Fatalf("failed to restore task: %+v", err)
+	}
+	if restored.Checkpoint.Load(&state) {
+		t.Errorf("the cleared state should be persisted")
+	}
+
+	// the drivers get a no-op checkpoint if the caller provides none
+	cp := driver.GetUploadCheckpoint(context.Background())
+	cp.Save(state)
+	if driver.HasUploadCheckpoint(context.Background()) || cp.Load(&state) {
+		t.Errorf("no-op checkpoint should keep nothing")
+	}
+}
@@ -0,0 +1,59 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+// the states of tasks are polled once in waitInterval
+var waitInterval = time.Second
+
+// WaitTasks wait until all the tasks are succeeded, failed or canceled, the error of ctx is returned
+// if it's done before
+func WaitTasks(ctx context.Context, tasks ...tache.TaskBase) error {
+	ticker := time.NewTicker(waitInterval)
+	defer ticker.Stop()
+	for {
+		finished := true
+		for _, t := range tasks {
+			if !IsFinished(t) {
+				finished = false
+				break
+			}
+		}
+		if finished {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsFinished returns whether the task is succeeded, failed or canceled, so it won't be run again
+func IsFinished(t tache.TaskBase) bool {
+	switch t.GetState() {
+	case tache.StateSucceeded, tache.StateFailed, tache.StateCanceled:
+		return true
+	}
+	return false
+}
+
+// GetTaskErr returns the error of the finished task, nil if it's succeeded
+func GetTaskErr(t tache.TaskBase) error {
+	switch t.GetState() {
+	case tache.StateSucceeded:
+		return nil
+	case tache.StateCanceled:
+		return context.Canceled
+	}
+	if err := t.GetErr(); err != nil {
+		return err
+	}
+	return errors.New("task failed")
+}
@@ -0,0 +1,47 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xhofe/tache"
+)
+
+type waitTask struct {
+	tache.Base
+}
+
+func (t *waitTask) Run() error {
+	return nil
+}
+
+func TestWaitTasks(t *testing.T) {
+	waitInterval = time.Millisecond
+	succeeded, failed := &waitTask{}, &waitTask{}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		succeeded.SetState(tache.StateSucceeded)
+		// the errored task is retried, so it's not finished
+		failed.SetState(tache.StateErrored)
+		time.Sleep(10 * time.Millisecond)
+		failed.SetErr(errors.New("upload failed"))
+		failed.SetState(tache.StateFailed)
+	}()
+	if err := WaitTasks(context.Background(), succeeded, failed); err != nil {
+		t.Fatalf("failed wait tasks: %+v", err)
+	}
+	if err := GetTaskErr(succeeded); err != nil {
+		t.Errorf("succeeded task should have no error, got %+v", err)
+	}
+	if err := GetTaskErr(failed); err == nil || err.Error() != "upload failed" {
+		t.Errorf("the error of failed task should be returned, got %+v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := WaitTasks(ctx, &waitTask{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the error of ctx, got %+v", err)
+	}
+}
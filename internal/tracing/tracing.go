@@ -0,0 +1,113 @@
+// Package tracing exports the spans of the requests, ops, driver http calls and tasks by OpenTelemetry
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanKey is the key of the span of the request in gin.Context,
+// as the values of the request context can't be got from gin.Context
+const SpanKey = "trace_span"
+
+var provider *sdktrace.TracerProvider
+
+// Init set up the exporter by the config, the spans are dropped if tracing is disabled
+func Init() {
+	c := conf.Conf.Tracing
+	if !c.Enable {
+		return
+	}
+	exporter, err := newExporter(c)
+	if err != nil {
+		log.Errorf("failed to init tracing: %+v", err)
+		return
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", "alist"),
+		attribute.String("service.version", conf.Version),
+	))
+	if err != nil {
+		log.Warnf("failed to merge tracing resource: %+v", err)
+		res = resource.Default()
+	}
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(c.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	log.Infof("tracing is enabled with %s exporter", c.Exporter)
+}
+
+func newExporter(c conf.Tracing) (sdktrace.SpanExporter, error) {
+	switch c.Exporter {
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "otlp", "":
+		var opts []otlptracehttp.Option
+		// the OTEL_EXPORTER_OTLP_* env are used if the endpoint is not set
+		if c.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(c.Endpoint))
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	}
+	return nil, errors.Errorf("unknown tracing exporter: %s", c.Exporter)
+}
+
+// Shutdown export the remaining spans
+func Shutdown() {
+	if provider == nil {
+		return
+	}
+	if err := provider.Shutdown(context.Background()); err != nil {
+		log.Errorf("failed to shutdown tracing: %+v", err)
+	}
+	provider = nil
+}
+
+// Start start a span as the child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		if span, ok := ctx.Value(SpanKey).(trace.Span); ok {
+			ctx = trace.ContextWithSpan(ctx, span)
+		}
+	}
+	return otel.Tracer("github.com/alist-org/alist/v3").Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End end the span and record the error if any
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract returns ctx with the span context propagated by the header of the request
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Storage returns the attributes of the storage of the op
+func Storage(mountPath, driver string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("alist.storage.mount_path", mountPath),
+		attribute.String("alist.storage.driver", driver),
+	}
+}
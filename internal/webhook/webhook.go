@@ -0,0 +1,259 @@
+// Package webhook posts the events on the bus to the configured urls, each delivery is retried
+// on failure and recorded in the delivery log
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/event"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxAttempts  = 3
+	retryDelay   = 2 * time.Second
+	timeout      = 10 * time.Second
+	concurrency  = 16
+	queueSize    = 1024
+	deliveryKeep = 100
+	// the signature is valid for signExpire, so that the receiver can reject the replayed requests
+	signExpire = 5 * time.Minute
+)
+
+var (
+	mu       sync.RWMutex
+	webhooks []model.Webhook
+	initOnce sync.Once
+	client   = &http.Client{Timeout: timeout}
+
+	// the deliveries are posted by a fixed number of workers from the queue
+	qmu     sync.RWMutex
+	queue   chan delivery
+	workers sync.WaitGroup
+	cancel  context.CancelFunc
+)
+
+type delivery struct {
+	webhook model.Webhook
+	event   event.Event
+}
+
+// Init load the webhooks and start to post the events to them
+func Init() {
+	if err := Load(); err != nil {
+		log.Errorf("failed load webhooks: %+v", err)
+	}
+	start(concurrency, queueSize)
+	initOnce.Do(func() {
+		event.Subscribe(handle)
+	})
+}
+
+func start(n, size int) {
+	qmu.Lock()
+	defer qmu.Unlock()
+	if queue != nil {
+		return
+	}
+	var ctx context.Context
+	ctx, cancel = context.WithCancel(context.Background())
+	queue = make(chan delivery, size)
+	workers.Add(n)
+	for i := 0; i < n; i++ {
+		go work(ctx, queue)
+	}
+}
+
+// Stop stop the workers, the queued deliveries are not retried any more and recorded as failed
+func Stop() {
+	qmu.Lock()
+	defer qmu.Unlock()
+	if queue == nil {
+		return
+	}
+	cancel()
+	close(queue)
+	workers.Wait()
+	queue = nil
+}
+
+func work(ctx context.Context, queue chan delivery) {
+	defer workers.Done()
+	for d := range queue {
+		deliver(ctx, &d.webhook, d.event)
+	}
+}
+
+// Load load the webhooks in db to memory, it's called after they are changed
+func Load() error {
+	list, err := db.GetWebhooks()
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	webhooks = list
+	mu.Unlock()
+	return nil
+}
+
+func handle(e event.Event) {
+	mu.RLock()
+	targets := make([]model.Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		if w.Accept(e.Type) {
+			targets = append(targets, w)
+		}
+	}
+	mu.RUnlock()
+	qmu.RLock()
+	defer qmu.RUnlock()
+	if queue == nil {
+		return
+	}
+	for _, w := range targets {
+		select {
+		case queue <- delivery{webhook: w, event: e}:
+		default:
+			log.Warnf("webhook queue is full, dropped event %s to webhook %s", e.Type, w.URL)
+		}
+	}
+}
+
+// deliver post the event to the webhook until it succeeds or maxAttempts is reached,
+// then save the delivery log
+func deliver(ctx context.Context, w *model.Webhook, e event.Event) *model.WebhookDelivery {
+	d := &model.WebhookDelivery{
+		WebhookID: w.ID,
+		EventID:   e.ID,
+		EventType: e.Type,
+		Time:      time.Now(),
+	}
+	body, err := utils.Json.Marshal(e)
+	if err == nil {
+		for d.Attempts < maxAttempts {
+			if d.Attempts > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(retryDelay * time.Duration(d.Attempts)):
+				}
+			}
+			d.Attempts++
+			d.StatusCode, err = post(ctx, w, e, body)
+			if err == nil || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	d.Duration = time.Since(d.Time).Milliseconds()
+	d.Success = err == nil
+	if err != nil {
+		d.Error = err.Error()
+		log.Warnf("failed deliver event %s to webhook %s: %+v", e.Type, w.URL, err)
+	}
+	if err = db.CreateWebhookDelivery(d); err == nil {
+		err = db.PruneWebhookDeliveries(w.ID, deliveryKeep)
+	}
+	if err != nil {
+		log.Errorf("failed save webhook delivery: %+v", err)
+	}
+	return d
+}
+
+func post(ctx context.Context, w *model.Webhook, e event.Event, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "alist/"+conf.Version)
+	req.Header.Set("X-Alist-Event", e.Type)
+	req.Header.Set("X-Alist-Delivery", e.ID)
+	if w.Secret != "" {
+		expire := time.Now().Add(signExpire).Unix()
+		req.Header.Set("X-Alist-Signature", sign.NewHMACSign([]byte(w.Secret)).Sign(string(body), expire))
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, errors.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// Test post a ping event to the webhook and returns the delivery
+func Test(ctx context.Context, id uint) (*model.WebhookDelivery, error) {
+	w, err := db.GetWebhookById(id)
+	if err != nil {
+		return nil, err
+	}
+	return deliver(ctx, w, event.New(event.Ping, map[string]string{"webhook": w.Name})), nil
+}
+
+func GetWebhooks() ([]model.Webhook, error) {
+	return db.GetWebhooks()
+}
+
+func GetWebhookById(id uint) (*model.Webhook, error) {
+	return db.GetWebhookById(id)
+}
+
+func check(w *model.Webhook) error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return errors.WithMessage(err, "invalid url")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("the url of webhook must be http or https")
+	}
+	return nil
+}
+
+func CreateWebhook(w *model.Webhook) error {
+	if err := check(w); err != nil {
+		return err
+	}
+	if err := db.CreateWebhook(w); err != nil {
+		return err
+	}
+	return Load()
+}
+
+func UpdateWebhook(w *model.Webhook) error {
+	if _, err := db.GetWebhookById(w.ID); err != nil {
+		return err
+	}
+	if err := check(w); err != nil {
+		return err
+	}
+	if err := db.UpdateWebhook(w); err != nil {
+		return err
+	}
+	return Load()
+}
+
+func DeleteWebhookById(id uint) error {
+	if err := db.DeleteWebhookById(id); err != nil {
+		return err
+	}
+	return Load()
+}
+
+func GetDeliveries(webhookId uint, pageIndex, pageSize int) ([]model.WebhookDelivery, int64, error) {
+	return db.GetWebhookDeliveries(webhookId, pageIndex, pageSize)
+}
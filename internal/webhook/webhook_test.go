@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/event"
+	"github.com/alist-org/alist/v3/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+// waitFor polls the condition for at most 5 seconds
+func waitFor(t *testing.T, msg string, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWorkerPool(t *testing.T) {
+	var (
+		lock               sync.Mutex
+		inflight, maxIn, n int
+		release            = make(chan struct{})
+	)
+	count := func() (int, int, int) {
+		lock.Lock()
+		defer lock.Unlock()
+		return inflight, maxIn, n
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		inflight++
+		n++
+		maxIn = max(maxIn, inflight)
+		lock.Unlock()
+		<-release
+		lock.Lock()
+		inflight--
+		lock.Unlock()
+	}))
+	defer srv.Close()
+
+	hook := &model.Webhook{Name: "test", URL: srv.URL}
+	if err := db.CreateWebhook(hook); err != nil {
+		t.Fatalf("failed create webhook: %+v", err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed load webhooks: %+v", err)
+	}
+	start(2, 3)
+	defer Stop()
+
+	// both of the workers are busy, the following deliveries wait in the queue
+	for i := 0; i < 2; i++ {
+		handle(event.New(event.StorageStatusChanged, nil))
+	}
+	waitFor(t, "the workers to post", func() bool { in, _, _ := count(); return in == 2 })
+	for i := 0; i < 5; i++ {
+		handle(event.New(event.StorageStatusChanged, nil))
+	}
+	// the ones out of the queue are dropped instead of waiting
+	time.Sleep(100 * time.Millisecond)
+	if in, _, total := count(); in != 2 || total != 2 {
+		t.Errorf("only the workers should post, got %d in flight of %d", in, total)
+	}
+	close(release)
+	waitFor(t, "the deliveries to be recorded", func() bool {
+		_, c, _ := db.GetWebhookDeliveries(hook.ID, 1, 10)
+		return c == 5
+	})
+	if _, m, total := count(); m != 2 || total != 5 {
+		t.Errorf("expect 5 posts by at most 2 workers, got %d posts by %d", total, m)
+	}
+	deliveries, _, _ := db.GetWebhookDeliveries(hook.ID, 1, 10)
+	for _, d := range deliveries {
+		if !d.Success || d.Attempts != 1 {
+			t.Errorf("the delivery should succeed at the first attempt, got %+v", d)
+		}
+	}
+}
@@ -43,6 +43,15 @@ func IsSubPath(path string, subPath string) bool {
 	return path == subPath || strings.HasPrefix(subPath, PathAddSeparatorSuffix(path))
 }
 
+// CommonParent returns the deepest path which contains both of the paths
+func CommonParent(path1, path2 string) string {
+	path1, path2 = FixAndCleanPath(path1), FixAndCleanPath(path2)
+	for !IsSubPath(path1, path2) {
+		path1 = stdpath.Dir(path1)
+	}
+	return path1
+}
+
 func Ext(path string) string {
 	ext := stdpath.Ext(path)
 	if len(ext) > 0 && ext[0] == '.' {
@@ -20,3 +20,18 @@ func TestFixAndCleanPath(t *testing.T) {
 		}
 	}
 }
+
+func TestCommonParent(t *testing.T) {
+	datas := [][3]string{
+		{"/a/b", "/a/c", "/a"},
+		{"/a/b", "/a/b/c", "/a/b"},
+		{"/ab", "/a", "/"},
+		{"/a", "/b", "/"},
+		{"/", "/a", "/"},
+	}
+	for _, data := range datas {
+		if res := CommonParent(data[0], data[1]); res != data[2] {
+			t.Errorf("common parent of %s and %s should be %s, got %s", data[0], data[1], data[2], res)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"maps"
 
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/net"
 	"github.com/alist-org/alist/v3/internal/stream"
@@ -20,6 +21,7 @@ import (
 )
 
 func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.Obj) error {
+	w = &countResponseWriter{ResponseWriter: w}
 	if link.MFile != nil {
 		defer link.MFile.Close()
 		attachHeader(w, file)
@@ -138,6 +140,17 @@ func (iw *InterceptResponseWriter) Write(p []byte) (int, error) {
 	return iw.Writer.Write(p)
 }
 
+// countResponseWriter count the bytes written in metrics
+type countResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (cw *countResponseWriter) Write(p []byte) (int, error) {
+	n, err := cw.ResponseWriter.Write(p)
+	metrics.ProxiedBytes.Add(float64(n))
+	return n, err
+}
+
 type WrittenResponseWriter struct {
 	http.ResponseWriter
 	written bool
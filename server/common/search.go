@@ -0,0 +1,135 @@
+package common
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// SetSearchVisibility push the visibility of the user by CanAccess into req, so that the searchers
+// filter the nodes natively. The folders of the metas, the group metas and the ACLs split the tree
+// into regions, in each of which the nearest meta and the permission of the user are the same.
+// The regexes not supported by go are left to be checked by CanAccess, and false is returned
+// if there are such ones.
+func SetSearchVisibility(user *model.User, req *model.SearchReq, password string) (bool, error) {
+	if user.HasLimitedPaths() {
+		req.Paths = user.LimitedPaths()
+	}
+	req.HidePaths = HideFilesRegexes()
+	metas, _, err := op.GetMetas(1, model.MaxInt)
+	if err != nil {
+		return false, err
+	}
+	var regions []string
+	native := true
+	for _, m := range metas {
+		regions = append(regions, utils.FixAndCleanPath(m.Path))
+	}
+	regions = append(regions, user.GroupMetaPaths()...)
+	regions = append(regions, req.Paths...)
+	slices.Sort(regions)
+	regions = slices.Compact(regions)
+	for _, region := range regions {
+		if !utils.IsSubPath(region, req.Parent) && !utils.IsSubPath(req.Parent, region) {
+			continue
+		}
+		meta, err := op.GetNearestMetaForUser(user, region)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return false, err
+		}
+		if meta == nil {
+			continue
+		}
+		u := user.At(region)
+		rule := model.SearchRule{Parent: region, Sub: true, Except: subRegions(region, regions)}
+		// the password of the meta is required by all the nodes in it if applied to the sub folders
+		if !u.CanAccessWithoutPassword() && meta.Password != "" && meta.PSub && meta.Password != password {
+			req.Rules = append(req.Rules, rule)
+			continue
+		}
+		if u.CanSeeHides() || meta.Hide == "" || (!meta.HSub && !utils.PathEqual(meta.Path, region)) {
+			continue
+		}
+		rule.Sub = meta.HSub
+		for _, hide := range strings.Split(meta.Hide, "\n") {
+			if hide == "" {
+				continue
+			}
+			if _, err := regexp.Compile(hide); err != nil {
+				native = false
+				continue
+			}
+			rule.Names = append(rule.Names, hide)
+		}
+		if len(rule.Names) > 0 {
+			req.Rules = append(req.Rules, rule)
+		}
+	}
+	return native, nil
+}
+
+// subRegions returns the nearest regions in the region
+func subRegions(region string, regions []string) []string {
+	var res []string
+	for _, r := range regions {
+		if r == region || !utils.IsSubPath(region, r) {
+			continue
+		}
+		if slices.ContainsFunc(res, func(sub string) bool { return utils.IsSubPath(sub, r) }) {
+			continue
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// HideFilesRegexes returns the regexes of the paths hidden by the setting, which are written
+// as /regex/flags in javascript
+func HideFilesRegexes() []string {
+	var res []string
+	for _, line := range strings.Split(setting.GetStr(conf.HideFiles), "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.LastIndex(line, "/"); strings.HasPrefix(line, "/") && i > 0 {
+			re := line[1:i]
+			if strings.Contains(line[i+1:], "i") {
+				re = "(?i)" + re
+			}
+			line = re
+		}
+		if _, err := regexp.Compile(line); line != "" && err == nil {
+			res = append(res, line)
+		}
+	}
+	return res
+}
+
+// CompileRegexes compile the regexes of the paths hidden, such as the ones of HideFilesRegexes
+func CompileRegexes(exprs []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid regex of the hidden paths: %s", expr)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// IsHiddenPath check the path against the regexes compiled by CompileRegexes
+func IsHiddenPath(regexes []*regexp.Regexp, p string) bool {
+	for _, re := range regexes {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
@@ -141,7 +141,8 @@ func (d *FtpMainDriver) AuthUser(cc ftpserver.ClientContext, user, pass string)
 	} else {
 		ctx = context.WithValue(ctx, "meta_pass", "")
 	}
-	ctx = context.WithValue(ctx, "client_ip", cc.RemoteAddr().String())
+	ctx = context.WithValue(ctx, conf.ClientIPKey, cc.RemoteAddr().String())
+	ctx = context.WithValue(ctx, conf.ProtocolKey, model.ProtocolFTP)
 	ctx = context.WithValue(ctx, "proxy_header", d.proxyHeader)
 	return ftp.NewAferoAdapter(ctx), nil
 }
@@ -18,8 +18,8 @@ func Mkdir(ctx context.Context, path string) error {
 	if err != nil {
 		return err
 	}
-	if !user.CanWrite() || !user.CanFTPManage() {
-		meta, err := op.GetNearestMeta(stdpath.Dir(reqPath))
+	if !user.At(reqPath).CanWrite() || !user.CanFTPManage() {
+		meta, err := op.GetNearestMetaForUser(user, stdpath.Dir(reqPath))
 		if err != nil {
 			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 				return err
@@ -34,13 +34,13 @@ func Mkdir(ctx context.Context, path string) error {
 
 func Remove(ctx context.Context, path string) error {
 	user := ctx.Value("user").(*model.User)
-	if !user.CanRemove() || !user.CanFTPManage() {
-		return errs.PermissionDenied
-	}
 	reqPath, err := user.JoinPath(path)
 	if err != nil {
 		return err
 	}
+	if !user.At(reqPath).CanRemove() || !user.CanFTPManage() {
+		return errs.PermissionDenied
+	}
 	return fs.Remove(ctx, reqPath)
 }
 
@@ -57,12 +57,13 @@ func Rename(ctx context.Context, oldPath, newPath string) error {
 	srcDir, srcBase := stdpath.Split(srcPath)
 	dstDir, dstBase := stdpath.Split(dstPath)
 	if srcDir == dstDir {
-		if !user.CanRename() || !user.CanFTPManage() {
+		if !user.At(srcPath).CanRename() || !user.CanFTPManage() {
 			return errs.PermissionDenied
 		}
 		return fs.Rename(ctx, srcPath, dstBase)
 	} else {
-		if !user.CanFTPManage() || !user.CanMove() || (srcBase != dstBase && !user.CanRename()) {
+		srcUser, dstUser := user.At(srcPath), user.At(dstPath)
+		if !user.CanFTPManage() || !srcUser.CanMove() || !dstUser.CanMove() || (srcBase != dstBase && !dstUser.CanRename()) {
 			return errs.PermissionDenied
 		}
 		if err = fs.Move(ctx, srcPath, dstDir); err != nil {
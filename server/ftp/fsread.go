@@ -23,7 +23,7 @@ type FileDownloadProxy struct {
 
 func OpenDownload(ctx context.Context, reqPath string, offset int64) (*FileDownloadProxy, error) {
 	user := ctx.Value("user").(*model.User)
-	meta, err := op.GetNearestMeta(reqPath)
+	meta, err := op.GetNearestMetaForUser(user, reqPath)
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			return nil, err
@@ -36,7 +36,7 @@ func OpenDownload(ctx context.Context, reqPath string, offset int64) (*FileDownl
 
 	// directly use proxy
 	header := *(ctx.Value("proxy_header").(*http.Header))
-	link, obj, err := fs.Link(ctx, reqPath, model.LinkArgs{
+	link, obj, err := fs.LinkDown(ctx, reqPath, model.LinkArgs{
 		IP:     ctx.Value("client_ip").(string),
 		Header: header,
 	})
@@ -118,7 +118,7 @@ func Stat(ctx context.Context, path string) (os.FileInfo, error) {
 	if err != nil {
 		return nil, err
 	}
-	meta, err := op.GetNearestMeta(reqPath)
+	meta, err := op.GetNearestMetaForUser(user, reqPath)
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			return nil, err
@@ -141,7 +141,7 @@ func List(ctx context.Context, path string) ([]os.FileInfo, error) {
 	if err != nil {
 		return nil, err
 	}
-	meta, err := op.GetNearestMeta(reqPath)
+	meta, err := op.GetNearestMetaForUser(user, reqPath)
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			return nil, err
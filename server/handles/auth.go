@@ -7,11 +7,14 @@ import (
 	"time"
 
 	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/event"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	"github.com/pquerna/otp/totp"
+	log "github.com/sirupsen/logrus"
 )
 
 var loginCache = cache.NewMemCache[int]()
@@ -61,12 +64,14 @@ func loginHash(c *gin.Context, req *LoginReq) {
 	if err != nil {
 		common.ErrorResp(c, err, 400)
 		loginCache.Set(ip, count+1)
+		publishLogin(c, req.Username, "password", err)
 		return
 	}
 	// validate password hash
 	if err := user.ValidatePwdStaticHash(req.Password); err != nil {
 		common.ErrorResp(c, err, 400)
 		loginCache.Set(ip, count+1)
+		publishLogin(c, req.Username, "password", err)
 		return
 	}
 	// check 2FA
@@ -74,6 +79,7 @@ func loginHash(c *gin.Context, req *LoginReq) {
 		if !totp.Validate(req.OtpCode, user.OtpSecret) {
 			common.ErrorStrResp(c, "Invalid 2FA code", 402)
 			loginCache.Set(ip, count+1)
+			publishLogin(c, req.Username, "password", errors.New("invalid 2FA code"))
 			return
 		}
 	}
@@ -85,17 +91,30 @@ func loginHash(c *gin.Context, req *LoginReq) {
 	}
 	common.SuccessResp(c, gin.H{"token": token})
 	loginCache.Del(ip)
+	publishLogin(c, user.Username, "password", nil)
+}
+
+// publishLogin publish the login event, err is the reason if the login is failed
+func publishLogin(c *gin.Context, username, method string, err error) {
+	data := event.LoginData{
+		Username: username,
+		IP:       c.ClientIP(),
+		Method:   method,
+		Success:  err == nil,
+	}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	event.Publish(event.UserLogin, data)
 }
 
 type UserResp struct {
 	model.User
-	Otp bool `json:"otp"`
+	Otp   bool             `json:"otp"`
+	Usage *model.UserUsage `json:"usage,omitempty"`
 }
 
-// CurrentUser get current user by token
-// if token is empty, return guest user
-func CurrentUser(c *gin.Context) {
-	user := c.MustGet("user").(*model.User)
+func toUserResp(user *model.User) UserResp {
 	userResp := UserResp{
 		User: *user,
 	}
@@ -103,6 +122,23 @@ func CurrentUser(c *gin.Context) {
 	if userResp.OtpSecret != "" {
 		userResp.Otp = true
 	}
+	usage, err := op.GetUserUsage(user.ID)
+	if err != nil {
+		log.Warnf("failed get usage of user [%s]: %+v", user.Username, err)
+	} else {
+		userResp.Usage = usage
+	}
+	return userResp
+}
+
+// CurrentUser get current user by token
+// if token is empty, return guest user
+func CurrentUser(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	userResp := toUserResp(user)
+	// the permission and base path inherited from the groups are applied
+	userResp.Permission = user.GetPermission()
+	userResp.BasePath = user.GetBasePath()
 	common.SuccessResp(c, userResp)
 }
 
@@ -0,0 +1,23 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type InvalidateCacheReq struct {
+	Path string `json:"path" form:"path" binding:"required"`
+}
+
+// InvalidateCache drop the list cache of the path and all its sub-folders, the path is not joined with the base path
+func InvalidateCache(c *gin.Context) {
+	var req InvalidateCacheReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"storages": op.InvalidateCache(req.Path),
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -34,7 +35,7 @@ func Down(c *gin.Context) {
 		Proxy(c)
 		return
 	} else {
-		link, _, err := fs.Link(c, rawPath, model.LinkArgs{
+		link, _, err := fs.LinkDown(c, rawPath, model.LinkArgs{
 			IP:       c.ClientIP(),
 			Header:   c.Request.Header,
 			Type:     c.Query("type"),
@@ -70,7 +71,10 @@ func Proxy(c *gin.Context) {
 				return
 			}
 		}
-		link, file, err := fs.Link(c, rawPath, model.LinkArgs{
+		transfer := op.NewTransfer()
+		defer transfer.Done()
+		c.Set(conf.TransferKey, transfer)
+		link, file, err := fs.LinkDown(c, rawPath, model.LinkArgs{
 			Header:  c.Request.Header,
 			Type:    c.Query("type"),
 			HttpReq: c.Request,
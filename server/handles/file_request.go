@@ -0,0 +1,183 @@
+package handles
+
+import (
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type FileRequestReq struct {
+	Token       string     `json:"token"`
+	Path        string     `json:"path"`
+	MaxSize     int64      `json:"max_size"`
+	AllowedExts string     `json:"allowed_exts"`
+	Expires     *time.Time `json:"expires"`
+	Disabled    bool       `json:"disabled"`
+}
+
+func ListFileRequests(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	requests, total, err := op.GetFileRequests(user.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: requests,
+		Total:   total,
+	})
+}
+
+// ListAllFileRequests list the file requests of all users for admin
+func ListAllFileRequests(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	requests, total, err := op.GetFileRequests(0, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: requests,
+		Total:   total,
+	})
+}
+
+func CreateFileRequest(c *gin.Context) {
+	var req FileRequestReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.MaxSize < 0 {
+		common.ErrorStrResp(c, "max_size can't be negative", 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if err = checkSharePermission(user, reqPath, true); err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	obj, err := fs.Get(c, reqPath, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if !obj.IsDir() {
+		common.ErrorStrResp(c, "file request must be bound to a folder", 400)
+		return
+	}
+	fr := &model.FileRequest{
+		Path:        reqPath,
+		CreatorID:   user.ID,
+		MaxSize:     req.MaxSize,
+		AllowedExts: req.AllowedExts,
+		Expires:     req.Expires,
+		Disabled:    req.Disabled,
+	}
+	if err = op.CreateFileRequest(fr); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, fr)
+}
+
+// getOwnFileRequest get the file request which is created by the user, admin can get any one
+func getOwnFileRequest(c *gin.Context, token string) (*model.FileRequest, bool) {
+	user := c.MustGet("user").(*model.User)
+	fr, err := op.GetFileRequestByToken(token)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return nil, false
+	}
+	if !user.IsAdmin() && fr.CreatorID != user.ID {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return nil, false
+	}
+	return fr, true
+}
+
+func UpdateFileRequest(c *gin.Context) {
+	var req FileRequestReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.MaxSize < 0 {
+		common.ErrorStrResp(c, "max_size can't be negative", 400)
+		return
+	}
+	fr, ok := getOwnFileRequest(c, req.Token)
+	if !ok {
+		return
+	}
+	fr.MaxSize = req.MaxSize
+	fr.AllowedExts = req.AllowedExts
+	fr.Expires = req.Expires
+	fr.Disabled = req.Disabled
+	if err := op.UpdateFileRequest(fr); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteFileRequest(c *gin.Context) {
+	token := c.Query("token")
+	if _, ok := getOwnFileRequest(c, token); !ok {
+		return
+	}
+	if err := op.DeleteFileRequestByToken(token); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+type FileRequestResp struct {
+	Name        string     `json:"name"`
+	MaxSize     int64      `json:"max_size"`
+	AllowedExts string     `json:"allowed_exts"`
+	Expires     *time.Time `json:"expires"`
+}
+
+// GetFileRequestInfo returns the limits of the file request for the uploaders,
+// the content of the folder is never exposed
+func GetFileRequestInfo(c *gin.Context) {
+	fr, err := op.GetFileRequestByToken(c.Param("token"))
+	if err != nil {
+		common.ErrorStrResp(c, "file request not found", 404)
+		return
+	}
+	if err = fr.Validate(); err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	common.SuccessResp(c, FileRequestResp{
+		Name:        stdpath.Base(fr.Path),
+		MaxSize:     fr.MaxSize,
+		AllowedExts: fr.AllowedExts,
+		Expires:     fr.Expires,
+	})
+}
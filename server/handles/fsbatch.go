@@ -29,10 +29,6 @@ func FsRecursiveMove(c *gin.Context) {
 	}
 
 	user := c.MustGet("user").(*model.User)
-	if !user.CanMove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -43,8 +39,12 @@ func FsRecursiveMove(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !user.At(srcDir).CanMove() || !user.At(dstDir).CanMove() {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 
-	meta, err := op.GetNearestMeta(srcDir)
+	meta, err := op.GetNearestMetaForUser(user, srcDir)
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			common.ErrorResp(c, err, 500, true)
@@ -149,18 +149,17 @@ func FsBatchRename(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRename() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
-
 	reqPath, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !user.At(reqPath).CanRename() {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 
-	meta, err := op.GetNearestMeta(reqPath)
+	meta, err := op.GetNearestMetaForUser(user, reqPath)
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			common.ErrorResp(c, err, 500, true)
@@ -194,18 +193,17 @@ func FsRegexRename(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRename() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
-
 	reqPath, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !user.At(reqPath).CanRename() {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 
-	meta, err := op.GetNearestMeta(reqPath)
+	meta, err := op.GetNearestMetaForUser(user, reqPath)
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			common.ErrorResp(c, err, 500, true)
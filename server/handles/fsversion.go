@@ -0,0 +1,104 @@
+package handles
+
+import (
+	"fmt"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type FsVersionsReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+}
+
+type FsVersionResp struct {
+	model.FileVersion
+	RawURL string `json:"raw_url"`
+}
+
+func FsVersions(c *gin.Context) {
+	var req FsVersionsReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestMetaForUser(user, reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	versions, err := fs.ListVersions(c, reqPath)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	resp := make([]FsVersionResp, 0, len(versions))
+	for _, v := range versions {
+		// the versions are always downloaded with sign, since they are not under the meta of the file
+		rawURL := fmt.Sprintf("%s/d%s?sign=%s",
+			common.GetApiUrl(c.Request),
+			utils.EncodePath(v.Path, true),
+			sign.Sign(v.Path))
+		v.Path = ""
+		resp = append(resp, FsVersionResp{FileVersion: v, RawURL: rawURL})
+	}
+	common.SuccessResp(c, resp)
+}
+
+type FsRestoreVersionReq struct {
+	Path string `json:"path" form:"path"`
+	ID   string `json:"id" form:"id"`
+}
+
+func FsRestoreVersion(c *gin.Context) {
+	var req FsRestoreVersionReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if !user.At(reqPath).CanWrite() {
+		meta, err := op.GetNearestMetaForUser(user, stdpath.Dir(reqPath))
+		if err != nil {
+			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+				common.ErrorResp(c, err, 500, true)
+				return
+			}
+		}
+		if !common.CanWrite(meta, stdpath.Dir(reqPath)) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
+	if err := fs.RestoreVersion(c, reqPath, req.ID); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -2,6 +2,7 @@ package handles
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -19,7 +20,7 @@ type UpdateIndexReq struct {
 }
 
 func BuildIndex(c *gin.Context) {
-	if search.Running() {
+	if search.Running() || search.StorageIndexRunning() {
 		common.ErrorStrResp(c, "index is running", 400)
 		return
 	}
@@ -45,7 +46,7 @@ func UpdateIndex(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if search.Running() {
+	if search.Running() || search.StorageIndexRunning() {
 		common.ErrorStrResp(c, "index is running", 400)
 		return
 	}
@@ -85,7 +86,7 @@ func StopIndex(c *gin.Context) {
 }
 
 func ClearIndex(c *gin.Context) {
-	if search.Running() {
+	if search.Running() || search.StorageIndexRunning() {
 		common.ErrorStrResp(c, "index is running", 400)
 		return
 	}
@@ -107,3 +108,39 @@ func GetProgress(c *gin.Context) {
 	}
 	common.SuccessResp(c, progress)
 }
+
+func ListIndexJobs(c *gin.Context) {
+	jobs, err := search.GetIndexJobs()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, jobs)
+}
+
+// StartStorageIndex start or resume the incremental index job of the storage
+func StartStorageIndex(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err = search.StartStorageIndex(uint(id)); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func StopStorageIndex(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err = search.StopStorageIndex(uint(id)); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c)
+}
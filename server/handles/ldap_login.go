@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
-	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
@@ -95,6 +94,7 @@ func loginLdap(c *gin.Context, req *LoginReq) {
 		utils.Log.Errorf("Failed to auth. %v", err)
 		common.ErrorResp(c, err, 400)
 		loginCache.Set(ip, count+1)
+		publishLogin(c, req.Username, "ldap", err)
 		return
 	} else {
 		utils.Log.Infof("Auth successful username:%s", req.Username)
@@ -119,22 +119,27 @@ func loginLdap(c *gin.Context, req *LoginReq) {
 	}
 	common.SuccessResp(c, gin.H{"token": token})
 	loginCache.Del(ip)
+	publishLogin(c, user.Username, "ldap", nil)
 }
 
 func ladpRegister(username string) (*model.User, error) {
 	if username == "" {
 		return nil, errors.New("cannot get username from ldap provider")
 	}
+	groupIds, err := op.GetGroupIdsByNames(setting.GetStr(conf.LdapDefaultGroups))
+	if err != nil {
+		return nil, err
+	}
 	user := &model.User{
-		ID:         0,
-		Username:   username,
-		Password:   random.String(16),
-		Permission: int32(setting.GetInt(conf.LdapDefaultPermission, 0)),
-		BasePath:   setting.GetStr(conf.LdapDefaultDir),
-		Role:       0,
-		Disabled:   false,
-	}
-	if err := db.CreateUser(user); err != nil {
+		ID:       0,
+		Username: username,
+		Password: random.String(16),
+		BasePath: setting.GetStr(conf.LdapDefaultDir),
+		GroupIDs: groupIds,
+		Role:     0,
+		Disabled: false,
+	}
+	if err = op.CreateUser(user); err != nil {
 		return nil, err
 	}
 	return user, nil
@@ -2,7 +2,6 @@ package handles
 
 import (
 	"path"
-	"strings"
 
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -43,31 +42,88 @@ func Search(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	nodes, total, err := search.Search(c, req.SearchReq)
+	native, err := common.SetSearchVisibility(user, &req.SearchReq, req.Password)
 	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
-	var filteredNodes []model.SearchNode
-	for _, node := range nodes {
-		if !strings.HasPrefix(node.Parent, user.BasePath) {
-			continue
-		}
-		meta, err := op.GetNearestMeta(node.Parent)
-		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
-			continue
-		}
-		if !common.CanAccess(user, meta, path.Join(node.Parent, node.Name), req.Password) {
-			continue
-		}
-		filteredNodes = append(filteredNodes, node)
+	var (
+		nodes []model.SearchNode
+		total int64
+	)
+	if native && search.FilteredNatively(req.SearchReq) {
+		nodes, total, err = search.Search(c, req.SearchReq)
+	} else {
+		nodes, total, err = searchChecked(c, user, req)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
 	}
 	common.SuccessResp(c, common.PageResp{
-		Content: utils.MustSliceConvert(filteredNodes, nodeToSearchResp),
+		Content: utils.MustSliceConvert(nodes, nodeToSearchResp),
 		Total:   total,
 	})
 }
 
+// searchCheckSize is the number of the nodes fetched at once by searchChecked
+const searchCheckSize = 1000
+
+// searchChecked search all the nodes matched and check them one by one for the hides the searcher
+// can't filter natively, such as the regexes of meilisearch, so that the page is filled and the
+// total is the count of the nodes visible
+func searchChecked(c *gin.Context, user *model.User, req SearchReq) ([]model.SearchNode, int64, error) {
+	hides, err := common.CompileRegexes(req.HidePaths)
+	if err != nil {
+		return nil, 0, err
+	}
+	metas := make(map[string]*model.Meta)
+	visible := func(node model.SearchNode) (bool, error) {
+		p := path.Join(node.Parent, node.Name)
+		if common.IsHiddenPath(hides, p) {
+			return false, nil
+		}
+		meta, ok := metas[node.Parent]
+		if !ok {
+			meta, err = op.GetNearestMetaForUser(user, node.Parent)
+			if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+				return false, err
+			}
+			metas[node.Parent] = meta
+		}
+		return common.CanAccess(user, meta, p, req.Password), nil
+	}
+	skip := (req.Page - 1) * req.PerPage
+	var (
+		res   []model.SearchNode
+		total int64
+	)
+	scan := req.SearchReq
+	scan.PerPage = searchCheckSize
+	for scan.Page = 1; ; scan.Page++ {
+		nodes, _, err := search.Search(c, scan)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, node := range nodes {
+			ok, err := visible(node)
+			if err != nil {
+				return nil, 0, err
+			}
+			if !ok {
+				continue
+			}
+			if total >= int64(skip) && len(res) < req.PerPage {
+				res = append(res, node)
+			}
+			total++
+		}
+		if len(nodes) < scan.PerPage {
+			return res, total, nil
+		}
+	}
+}
+
 func nodeToSearchResp(node model.SearchNode) SearchResp {
 	return SearchResp{
 		SearchNode: node,
@@ -0,0 +1,94 @@
+package handles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// pagedSearcher returns the pages of the nodes without filtering them by the visibility,
+// as meilisearch does for the regexes
+type pagedSearcher struct {
+	searcher.Searcher
+	nodes []model.SearchNode
+}
+
+func (s *pagedSearcher) Config() searcher.Config {
+	return searcher.Config{Name: "paged"}
+}
+
+func (s *pagedSearcher) Release(ctx context.Context) error {
+	return nil
+}
+
+func (s *pagedSearcher) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
+	start := min((req.Page-1)*req.PerPage, len(s.nodes))
+	end := min(start+req.PerPage, len(s.nodes))
+	return s.nodes[start:end], int64(len(s.nodes)), nil
+}
+
+func TestSearchChecked(t *testing.T) {
+	s := &pagedSearcher{}
+	for _, name := range []string{"a.txt", "b.tmp", "c.txt", "d.tmp", "e.txt"} {
+		s.nodes = append(s.nodes, model.SearchNode{Parent: "/docs", Name: name})
+	}
+	searcher.RegisterSearcher(s.Config(), func() (searcher.Searcher, error) { return s, nil })
+	if err := search.Init("paged"); err != nil {
+		t.Fatalf("failed init searcher: %+v", err)
+	}
+	defer func() { _ = search.Init("none") }()
+	if err := op.SaveSettingItem(&model.SettingItem{Key: conf.HideFiles, Value: `/\.tmp$/`, Type: conf.TypeText,
+		Group: model.GLOBAL, Flag: model.PRIVATE}); err != nil {
+		t.Fatalf("failed save setting: %+v", err)
+	}
+	defer func() {
+		_ = op.SaveSettingItem(&model.SettingItem{Key: conf.HideFiles, Value: "", Type: conf.TypeText,
+			Group: model.GLOBAL, Flag: model.PRIVATE})
+	}()
+
+	r := gin.New()
+	r.POST("/search", func(c *gin.Context) {
+		c.Set("user", &model.User{Username: "admin", Role: model.ADMIN, BasePath: "/", Permission: 0xffff})
+		Search(c)
+	})
+	get := func(page int) ([]string, int64) {
+		t.Helper()
+		body, _ := utils.Json.MarshalToString(map[string]any{"parent": "/", "keywords": "x", "page": page, "per_page": 2})
+		req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp struct {
+			Code int `json:"code"`
+			Data struct {
+				Content []SearchResp `json:"content"`
+				Total   int64        `json:"total"`
+			} `json:"data"`
+		}
+		if err := utils.Json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Code != 200 {
+			t.Fatalf("failed search %s: %+v", w.Body.String(), err)
+		}
+		var names []string
+		for _, node := range resp.Data.Content {
+			names = append(names, node.Name)
+		}
+		return names, resp.Data.Total
+	}
+	// the hidden nodes are neither in the pages nor counted
+	if names, total := get(1); strings.Join(names, ",") != "a.txt,c.txt" || total != 3 {
+		t.Errorf("expected the full first page of the 3 visible nodes, got %v of %d", names, total)
+	}
+	if names, total := get(2); strings.Join(names, ",") != "e.txt" || total != 3 {
+		t.Errorf("expected the last visible node, got %v of %d", names, total)
+	}
+}
@@ -0,0 +1,355 @@
+package handles
+
+import (
+	"net/http"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type ShareReq struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	// Password is kept when updating if it's nil, and removed if it's empty
+	Password    *string    `json:"password"`
+	Expires     *time.Time `json:"expires"`
+	MaxAccess   int        `json:"max_access"`
+	AllowUpload bool       `json:"allow_upload"`
+	Disabled    bool       `json:"disabled"`
+}
+
+func ListShares(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	shares, total, err := op.GetShares(user.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: shares,
+		Total:   total,
+	})
+}
+
+// ListAllShares list the shares of all users for admin
+func ListAllShares(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	shares, total, err := op.GetShares(0, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: shares,
+		Total:   total,
+	})
+}
+
+// checkSharePermission check whether the user can share the path with the given options
+func checkSharePermission(user *model.User, reqPath string, allowUpload bool) error {
+	meta, err := op.GetNearestMetaForUser(user, reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return err
+	}
+	if !common.CanAccess(user, meta, reqPath, "") {
+		return errs.PermissionDenied
+	}
+	if allowUpload && !user.At(reqPath).CanWrite() && !common.CanWrite(meta, reqPath) {
+		return errs.PermissionDenied
+	}
+	return nil
+}
+
+func CreateShare(c *gin.Context) {
+	var req ShareReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	if err = checkSharePermission(user, reqPath, req.AllowUpload); err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	obj, err := fs.Get(c, reqPath, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if req.AllowUpload && !obj.IsDir() {
+		common.ErrorStrResp(c, "upload is only allowed for shared folders", 400)
+		return
+	}
+	share := &model.Share{
+		Path:        reqPath,
+		CreatorID:   user.ID,
+		Expires:     req.Expires,
+		MaxAccess:   req.MaxAccess,
+		AllowUpload: req.AllowUpload,
+		Disabled:    req.Disabled,
+	}
+	if req.Password != nil {
+		share.SetPassword(*req.Password)
+	}
+	if err = op.CreateShare(share); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, share)
+}
+
+// getOwnShare get the share which is created by the user, admin can get any share
+func getOwnShare(c *gin.Context, id string) (*model.Share, bool) {
+	user := c.MustGet("user").(*model.User)
+	share, err := op.GetShareById(id)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return nil, false
+	}
+	if !user.IsAdmin() && share.CreatorID != user.ID {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return nil, false
+	}
+	return share, true
+}
+
+func UpdateShare(c *gin.Context) {
+	var req ShareReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	share, ok := getOwnShare(c, req.ID)
+	if !ok {
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if req.AllowUpload && !share.AllowUpload {
+		if err := checkSharePermission(user, share.Path, true); err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+	}
+	if req.Password != nil {
+		share.SetPassword(*req.Password)
+	}
+	share.Expires = req.Expires
+	share.MaxAccess = req.MaxAccess
+	share.AllowUpload = req.AllowUpload
+	share.Disabled = req.Disabled
+	if err := op.UpdateShare(share); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteShare(c *gin.Context) {
+	id := c.Query("id")
+	if _, ok := getOwnShare(c, id); !ok {
+		return
+	}
+	if err := op.DeleteShareById(id); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// checkShareCreator check whether the creator can still access the path in the share,
+// since the permission may have been changed after it's shared
+func checkShareCreator(creator *model.User, path string, upload bool) error {
+	if !creator.InBasePath(path) {
+		return errs.PermissionDenied
+	}
+	return checkSharePermission(creator, path, upload)
+}
+
+// getShareTarget check the share and returns the full path requested in it
+func getShareTarget(c *gin.Context, upload bool) (*model.Share, string, bool) {
+	share, err := op.GetShareById(c.Param("id"))
+	if err != nil {
+		common.ErrorStrResp(c, "share not found", 404)
+		return nil, "", false
+	}
+	// the requests not counted continue the downloads counted already, which may be the last ones
+	validate := share.Validate
+	if !upload && !countedAccess(c) {
+		validate = share.ValidateState
+	}
+	if err = validate(); err != nil {
+		common.ErrorResp(c, err, 403)
+		return nil, "", false
+	}
+	creator, err := op.GetUserById(share.CreatorID)
+	if err != nil || creator.Disabled {
+		common.ErrorStrResp(c, "share is no longer available", 403)
+		return nil, "", false
+	}
+	if share.HasPassword() {
+		password := c.Query("pwd")
+		if password == "" {
+			password = c.GetHeader("Share-Password")
+		}
+		if !share.ValidatePassword(password) {
+			common.ErrorStrResp(c, "password is incorrect", 403)
+			return nil, "", false
+		}
+	}
+	reqPath := stdpath.Join(share.Path, utils.FixAndCleanPath(c.Param("path")))
+	if err = checkShareCreator(creator, reqPath, upload && share.AllowUpload); err != nil {
+		common.ErrorStrResp(c, "share is no longer available", 403)
+		return nil, "", false
+	}
+	// the operations in the share are done as the creator
+	c.Set("user", creator)
+	return share, reqPath, true
+}
+
+type ShareObjResp struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	IsDir    bool      `json:"is_dir"`
+	Modified time.Time `json:"modified"`
+}
+
+type ShareResp struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Path        string         `json:"path"`
+	Expires     *time.Time     `json:"expires"`
+	AllowUpload bool           `json:"allow_upload"`
+	Content     []ShareObjResp `json:"content"`
+}
+
+// ShareGet list the folder or download the file in the share
+func ShareGet(c *gin.Context) {
+	share, reqPath, ok := getShareTarget(c, false)
+	if !ok {
+		return
+	}
+	obj, err := fs.Get(c, reqPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if !obj.IsDir() {
+		if countedAccess(c) {
+			if err = op.AccessShare(share); err != nil {
+				common.ErrorResp(c, err, 403)
+				return
+			}
+		}
+		c.Set("path", reqPath)
+		Down(c)
+		return
+	}
+	// the objs are listed as the creator, the path can't be out of the shared folder
+	meta, _ := op.GetNearestMetaForUser(c.MustGet("user").(*model.User), reqPath)
+	c.Set("meta", meta)
+	objs, err := fs.List(c, reqPath, &fs.ListArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	content := make([]ShareObjResp, 0, len(objs))
+	for _, o := range objs {
+		content = append(content, ShareObjResp{
+			Name:     o.GetName(),
+			Size:     o.GetSize(),
+			IsDir:    o.IsDir(),
+			Modified: o.ModTime(),
+		})
+	}
+	common.SuccessResp(c, ShareResp{
+		ID:          share.ID,
+		Name:        stdpath.Base(share.Path),
+		Path:        utils.FixAndCleanPath(c.Param("path")),
+		Expires:     share.Expires,
+		AllowUpload: share.AllowUpload,
+		Content:     content,
+	})
+}
+
+// countedAccess returns whether the request starts a download of the share. The HEAD requests and
+// the range requests not from the beginning, which resume a download or are the parts of a
+// multi-threaded one, aren't counted.
+func countedAccess(c *gin.Context) bool {
+	if c.Request.Method == http.MethodHead {
+		return false
+	}
+	r := c.GetHeader("Range")
+	return r == "" || strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(r, "bytes=")), "0-")
+}
+
+// SharePut upload a file into the shared folder, the body is the content of the file
+func SharePut(c *gin.Context) {
+	share, reqPath, ok := getShareTarget(c, true)
+	if !ok {
+		return
+	}
+	defer c.Request.Body.Close()
+	if !share.AllowUpload {
+		common.ErrorStrResp(c, "upload is not allowed", 403)
+		return
+	}
+	if utils.PathEqual(reqPath, share.Path) {
+		common.ErrorStrResp(c, "file name is required", 400)
+		return
+	}
+	if res, _ := fs.Get(c, reqPath, &fs.GetArgs{NoLog: true}); res != nil {
+		common.ErrorStrResp(c, "file exists", 403)
+		return
+	}
+	size, err := strconv.ParseInt(c.GetHeader("Content-Length"), 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	dir, name := stdpath.Split(reqPath)
+	mimetype := c.GetHeader("Content-Type")
+	if len(mimetype) == 0 {
+		mimetype = utils.GetMimeType(name)
+	}
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     size,
+			Modified: time.Now(),
+		},
+		Reader:   c.Request.Body,
+		Mimetype: mimetype,
+	}
+	if err = fs.PutDirectly(c, dir, s, true); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,207 @@
+package handles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+	gin.SetMode(gin.TestMode)
+}
+
+func TestSharePut(t *testing.T) {
+	dir := t.TempDir()
+	root, _ := utils.Json.MarshalToString(map[string]string{"root_folder_path": dir})
+	ctx := context.Background()
+	if _, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/shared", Addition: root}); err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "exists.txt"), []byte("exists"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %+v", err)
+	}
+	creator := &model.User{Username: "sharer", BasePath: "/", Permission: 1 << 3}
+	if err := op.CreateUser(creator); err != nil {
+		t.Fatalf("failed to create user: %+v", err)
+	}
+	newShare := func(s model.Share, pwd string) string {
+		t.Helper()
+		s.Path, s.CreatorID = "/shared", creator.ID
+		s.SetPassword(pwd)
+		if err := op.CreateShare(&s); err != nil {
+			t.Fatalf("failed to create share: %+v", err)
+		}
+		return s.ID
+	}
+	r := gin.New()
+	r.PUT("/s/:id/*path", SharePut)
+	put := func(id, name, pwd string) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPut, "/s/"+id+"/"+name, strings.NewReader("content"))
+		req.Header.Set("Content-Length", "7")
+		req.Header.Set("Share-Password", pwd)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp struct {
+			Code int `json:"code"`
+		}
+		if err := utils.Json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response %s: %+v", w.Body.String(), err)
+		}
+		return resp.Code
+	}
+
+	upload := newShare(model.Share{AllowUpload: true}, "pwd")
+	if code := put(upload, "a.txt", "wrong"); code != 403 {
+		t.Errorf("the upload with wrong password should be denied, got %d", code)
+	}
+	if code := put(upload, "a.txt", "pwd"); code != 200 {
+		t.Fatalf("failed to upload, got %d", code)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "a.txt")); err != nil || string(data) != "content" {
+		t.Errorf("the file should be uploaded, got %q, %+v", data, err)
+	}
+	if code := put(upload, "exists.txt", "pwd"); code != 403 {
+		t.Errorf("the existing file should not be overwritten, got %d", code)
+	}
+	if code := put(newShare(model.Share{}, ""), "b.txt", ""); code != 403 {
+		t.Errorf("the upload should be denied if it's not allowed, got %d", code)
+	}
+	expired := time.Now().Add(-time.Minute)
+	if code := put(newShare(model.Share{AllowUpload: true, Expires: &expired}, ""), "c.txt", ""); code != 403 {
+		t.Errorf("the upload into expired share should be denied, got %d", code)
+	}
+
+	// the share is unavailable once the creator can't write or access the path any more
+	creator.Permission = 0
+	if err := op.UpdateUser(creator); err != nil {
+		t.Fatalf("failed to update user: %+v", err)
+	}
+	if code := put(upload, "d.txt", "pwd"); code != 403 {
+		t.Errorf("the upload should be denied since the creator can't write, got %d", code)
+	}
+	creator.Permission, creator.BasePath = 1<<3, "/other"
+	if err := op.UpdateUser(creator); err != nil {
+		t.Fatalf("failed to update user: %+v", err)
+	}
+	if code := put(upload, "e.txt", "pwd"); code != 403 {
+		t.Errorf("the upload should be denied since the path is out of the base path of the creator, got %d", code)
+	}
+	for _, name := range []string{"b.txt", "c.txt", "d.txt", "e.txt"} {
+		if utils.Exists(filepath.Join(dir, name)) {
+			t.Errorf("%s should not be uploaded", name)
+		}
+	}
+}
+
+func TestShareGet(t *testing.T) {
+	dir := t.TempDir()
+	root, _ := utils.Json.MarshalToString(map[string]string{"root_folder_path": dir})
+	ctx := context.Background()
+	if _, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/shared-get", Addition: root}); err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	for _, name := range []string{"file.txt", "secret.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("0123456789"), 0o644); err != nil {
+			t.Fatalf("failed to write file: %+v", err)
+		}
+	}
+	if err := op.CreateMeta(&model.Meta{Path: "/shared-get", Hide: "secret", HSub: true}); err != nil {
+		t.Fatalf("failed to create meta: %+v", err)
+	}
+	newShare := func(username string, permission int32, s model.Share) string {
+		t.Helper()
+		creator := &model.User{Username: username, BasePath: "/", Permission: permission}
+		if err := op.CreateUser(creator); err != nil {
+			t.Fatalf("failed to create user: %+v", err)
+		}
+		s.CreatorID = creator.ID
+		if err := op.CreateShare(&s); err != nil {
+			t.Fatalf("failed to create share: %+v", err)
+		}
+		return s.ID
+	}
+	r := gin.New()
+	r.GET("/s/:id", ShareGet)
+	r.HEAD("/s/:id", ShareGet)
+	do := func(method, id, rangeHeader string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, "/s/"+id, nil)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// only the requests starting a download are counted
+	file := newShare("getter", 0, model.Share{Path: "/shared-get/file.txt", MaxAccess: 2})
+	downloads := []struct {
+		method, rangeHeader string
+		count               int
+	}{
+		{http.MethodHead, "", 0},
+		{http.MethodGet, "bytes=3-", 0},
+		{http.MethodGet, "", 1},
+		{http.MethodGet, "bytes=0-4", 2},
+		// the parts of the last download are still served
+		{http.MethodGet, "bytes=5-", 2},
+	}
+	for _, d := range downloads {
+		if w := do(d.method, file, d.rangeHeader); w.Code != http.StatusOK && w.Code != http.StatusPartialContent {
+			t.Errorf("%s %s should be served, got %d: %s", d.method, d.rangeHeader, w.Code, w.Body.String())
+		}
+		if share, err := op.GetShareById(file); err != nil || share.AccessCount != d.count {
+			t.Errorf("the access count should be %d after %s %s, got %+v, %+v", d.count, d.method, d.rangeHeader, share, err)
+		}
+	}
+	if w := do(http.MethodGet, file, ""); !strings.Contains(w.Body.String(), "max access count") {
+		t.Errorf("the new download should be denied, got %s", w.Body.String())
+	}
+
+	// the folder is listed with the visibility of the creator
+	list := func(id string) []string {
+		t.Helper()
+		var resp struct {
+			Code int       `json:"code"`
+			Data ShareResp `json:"data"`
+		}
+		w := do(http.MethodGet, id, "")
+		if err := utils.Json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Code != 200 {
+			t.Fatalf("failed to list %s: %+v", w.Body.String(), err)
+		}
+		var names []string
+		for _, o := range resp.Data.Content {
+			names = append(names, o.Name)
+		}
+		return names
+	}
+	if names := list(newShare("seer", 1, model.Share{Path: "/shared-get"})); strings.Join(names, ",") != "file.txt,secret.txt" {
+		t.Errorf("the creator who can see the hides should list all, got %v", names)
+	}
+	if names := list(newShare("blind", 0, model.Share{Path: "/shared-get"})); strings.Join(names, ",") != "file.txt" {
+		t.Errorf("the hidden file should not be listed, got %v", names)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/pkg/utils/random"
@@ -148,20 +149,24 @@ func autoRegister(username, userID string, err error) (*model.User, error) {
 	if username == "" {
 		return nil, errors.New("cannot get username from SSO provider")
 	}
+	groupIds, err := op.GetGroupIdsByNames(setting.GetStr(conf.SSODefaultGroups))
+	if err != nil {
+		return nil, err
+	}
 	user := &model.User{
-		ID:         0,
-		Username:   username,
-		Password:   random.String(16),
-		Permission: int32(setting.GetInt(conf.SSODefaultPermission, 0)),
-		BasePath:   setting.GetStr(conf.SSODefaultDir),
-		Role:       0,
-		Disabled:   false,
-		SsoID:      userID,
-	}
-	if err = db.CreateUser(user); err != nil {
+		ID:       0,
+		Username: username,
+		Password: random.String(16),
+		BasePath: setting.GetStr(conf.SSODefaultDir),
+		GroupIDs: groupIds,
+		Role:     0,
+		Disabled: false,
+		SsoID:    userID,
+	}
+	if err = op.CreateUser(user); err != nil {
 		if strings.HasPrefix(err.Error(), "UNIQUE constraint failed") && strings.HasSuffix(err.Error(), "username") {
 			user.Username = user.Username + "_" + userID
-			if err = db.CreateUser(user); err != nil {
+			if err = op.CreateUser(user); err != nil {
 				return nil, err
 			}
 		} else {
@@ -261,6 +266,8 @@ func OIDCLoginCallback(c *gin.Context) {
 		token, err := common.GenerateToken(user)
 		if err != nil {
 			common.ErrorResp(c, err, 400)
+		} else {
+			publishLogin(c, user.Username, "sso", nil)
 		}
 		if useCompatibility {
 			c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token)
@@ -427,6 +434,8 @@ func SSOLoginCallback(c *gin.Context) {
 	token, err := common.GenerateToken(user)
 	if err != nil {
 		common.ErrorResp(c, err, 400)
+	} else {
+		publishLogin(c, user.Username, "sso", nil)
 	}
 	if usecompatibility {
 		c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token)
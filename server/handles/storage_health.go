@@ -0,0 +1,59 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListStoragesHealth(c *gin.Context) {
+	common.SuccessResp(c, op.GetStoragesHealth())
+}
+
+// CheckStorageHealth check the storage immediately, the failed storage is re-initialized
+func CheckStorageHealth(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	storage, err := db.GetStorageById(uint(id))
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	health, err := op.CheckStorageHealth(c, storage.MountPath)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, health)
+}
+
+type StorageHealthHistoryReq struct {
+	model.PageReq
+	ID uint `json:"id" form:"id"`
+}
+
+func ListStorageHealthHistory(c *gin.Context) {
+	var req StorageHealthHistoryReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	logs, total, err := db.GetStorageHealthLogs(req.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: logs,
+		Total:   total,
+	})
+}
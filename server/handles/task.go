@@ -26,6 +26,7 @@ type TaskInfo struct {
 	EndTime     *time.Time  `json:"end_time"`
 	TotalBytes  int64       `json:"total_bytes"`
 	Error       string      `json:"error"`
+	Detail      any         `json:"detail,omitempty"`
 }
 
 func getTaskInfo[T task.TaskExtensionInfo](task T) TaskInfo {
@@ -59,6 +60,14 @@ func getTaskInfo[T task.TaskExtensionInfo](task T) TaskInfo {
 	}
 }
 
+func getTaskDetailInfo[T task.TaskExtensionInfo](t T) TaskInfo {
+	info := getTaskInfo(t)
+	if d, ok := any(t).(task.TaskWithDetail); ok {
+		info.Detail = d.GetDetail()
+	}
+	return info
+}
+
 func getTaskInfos[T task.TaskExtensionInfo](tasks []T) []TaskInfo {
 	return utils.MustSliceConvert(tasks, getTaskInfo[T])
 }
@@ -150,7 +159,7 @@ func taskRoute[T task.TaskExtensionInfo](g *gin.RouterGroup, manager task.Manage
 		})))
 	})
 	g.POST("/info", getTargetedHandler(manager, func(c *gin.Context, task T) {
-		common.SuccessResp(c, getTaskInfo(task))
+		common.SuccessResp(c, getTaskDetailInfo(task))
 	}))
 	g.POST("/cancel", getTargetedHandler(manager, func(c *gin.Context, task T) {
 		manager.Cancel(task.GetID())
@@ -222,4 +231,5 @@ func SetupTaskRoute(g *gin.RouterGroup) {
 	taskRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager)
 	taskRoute(g.Group("/decompress"), fs.ArchiveDownloadTaskManager)
 	taskRoute(g.Group("/decompress_upload"), fs.ArchiveContentUploadTaskManager)
+	taskRoute(g.Group("/sync"), fs.SyncTaskManager)
 }
@@ -23,8 +23,12 @@ func ListUsers(c *gin.Context) {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
+	content := make([]UserResp, 0, len(users))
+	for i := range users {
+		content = append(content, toUserResp(&users[i]))
+	}
 	common.SuccessResp(c, common.PageResp{
-		Content: users,
+		Content: content,
 		Total:   total,
 	})
 }
@@ -111,7 +115,21 @@ func GetUser(c *gin.Context) {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
-	common.SuccessResp(c, user)
+	common.SuccessResp(c, toUserResp(user))
+}
+
+func ResetUserUsage(c *gin.Context) {
+	idStr := c.Query("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err = op.ResetUserUsage(uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
 }
 
 func Cancel2FAById(c *gin.Context) {
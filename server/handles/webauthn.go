@@ -117,6 +117,7 @@ func FinishAuthnLogin(c *gin.Context) {
 		return
 	}
 	common.SuccessResp(c, gin.H{"token": token})
+	publishLogin(c, user.Username, "webauthn", nil)
 }
 
 func BeginAuthnRegistration(c *gin.Context) {
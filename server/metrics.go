@@ -0,0 +1,110 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/offline_download/tool"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/xhofe/tache"
+)
+
+var registerCollectors sync.Once
+
+func Metrics(g *gin.RouterGroup) {
+	if !conf.Conf.Metrics.Enable {
+		return
+	}
+	registerCollectors.Do(func() {
+		prometheus.MustRegister(storageCollector{}, taskCollector{})
+	})
+	g.GET("/metrics", middlewares.MetricsAuth, gin.WrapH(promhttp.Handler()))
+}
+
+var storageUpDesc = prometheus.NewDesc("alist_storage_up",
+	"Whether the storage works (1) or not (0).", []string{"storage", "driver"}, nil)
+
+// storageCollector collect the status of the storages when scraped
+type storageCollector struct{}
+
+func (storageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- storageUpDesc
+}
+
+func (storageCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, storage := range op.GetAllStorages() {
+		s := storage.GetStorage()
+		up := 0.0
+		if s.Status == op.WORK {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(storageUpDesc, prometheus.GaugeValue, up, s.MountPath, s.Driver)
+	}
+}
+
+var (
+	tasksDesc = prometheus.NewDesc("alist_tasks",
+		"The number of tasks by manager and state.", []string{"manager", "state"}, nil)
+	taskQueueDesc = prometheus.NewDesc("alist_task_queue_depth",
+		"The number of pending tasks by manager.", []string{"manager"}, nil)
+)
+
+var taskStateNames = map[tache.State]string{
+	tache.StatePending:      "pending",
+	tache.StateRunning:      "running",
+	tache.StateSucceeded:    "succeeded",
+	tache.StateCanceling:    "canceling",
+	tache.StateCanceled:     "canceled",
+	tache.StateErrored:      "errored",
+	tache.StateFailing:      "failing",
+	tache.StateFailed:       "failed",
+	tache.StateWaitingRetry: "waiting_retry",
+	tache.StateBeforeRetry:  "before_retry",
+}
+
+// taskCollector collect the states of the tasks in all the managers when scraped
+type taskCollector struct{}
+
+func (taskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- tasksDesc
+	ch <- taskQueueDesc
+}
+
+func (taskCollector) Collect(ch chan<- prometheus.Metric) {
+	managers := map[string][]tache.State{
+		"upload":            taskStates(fs.UploadTaskManager),
+		"copy":              taskStates(fs.CopyTaskManager),
+		"sync":              taskStates(fs.SyncTaskManager),
+		"decompress":        taskStates(fs.ArchiveDownloadTaskManager),
+		"decompress_upload": taskStates(fs.ArchiveContentUploadTaskManager.Manager),
+		"offline_download":  taskStates(tool.DownloadTaskManager),
+		"offline_transfer":  taskStates(tool.TransferTaskManager),
+	}
+	for name, states := range managers {
+		counts := make(map[tache.State]int, len(taskStateNames))
+		for _, state := range states {
+			counts[state]++
+		}
+		for state, stateName := range taskStateNames {
+			ch <- prometheus.MustNewConstMetric(tasksDesc, prometheus.GaugeValue, float64(counts[state]), name, stateName)
+		}
+		ch <- prometheus.MustNewConstMetric(taskQueueDesc, prometheus.GaugeValue, float64(counts[tache.StatePending]), name)
+	}
+}
+
+func taskStates[T tache.Task](m *tache.Manager[T]) []tache.State {
+	if m == nil {
+		return nil
+	}
+	tasks := m.GetAll()
+	states := make([]tache.State, 0, len(tasks))
+	for _, t := range tasks {
+		states = append(states, t.GetState())
+	}
+	return states
+}
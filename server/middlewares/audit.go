@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientInfo set the client ip and protocol for the audit logs and metrics
+func ClientInfo(protocol string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(conf.ClientIPKey, c.ClientIP())
+		c.Set(conf.ProtocolKey, protocol)
+		c.Next()
+	}
+}
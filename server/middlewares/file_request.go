@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/url"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// multipartOverhead is the extra size allowed for the boundaries and headers of the form upload
+const multipartOverhead = 1 << 20
+
+// FileRequest check the file request of the token and rewrite the upload into it,
+// so that the request can be handled by FsUp and the upload handlers as the creator
+func FileRequest(c *gin.Context) {
+	fr, err := op.GetFileRequestByToken(c.Param("token"))
+	if err != nil {
+		common.ErrorStrResp(c, "file request not found", 404)
+		return
+	}
+	if err = fr.Validate(); err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	creator, err := op.GetUserById(fr.CreatorID)
+	if err != nil || creator.Disabled {
+		common.ErrorStrResp(c, "file request is no longer available", 403)
+		return
+	}
+	limit := fr.MaxSize
+	if limit > 0 && c.Request.Method == http.MethodPost {
+		limit += multipartOverhead
+	}
+	if limit > 0 {
+		if c.Request.ContentLength > limit {
+			common.ErrorStrResp(c, "file is too large", 413)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	}
+	name, err := url.PathUnescape(c.GetHeader("File-Path"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if name == "" && c.Request.Method == http.MethodPost {
+		// the name of the file in the form is used if it's not given in the header
+		if file, err := c.FormFile("file"); err == nil {
+			name = file.Filename
+			if fr.MaxSize > 0 && file.Size > fr.MaxSize {
+				common.ErrorStrResp(c, "file is too large", 413)
+				return
+			}
+		}
+	}
+	// only the files directly in the folder can be uploaded
+	name = stdpath.Base(name)
+	if name == "" || name == "." || name == "/" || name == ".." {
+		common.ErrorStrResp(c, "file name is required", 400)
+		return
+	}
+	if !fr.AllowExt(name) {
+		common.ErrorStrResp(c, "file type is not allowed", 403)
+		return
+	}
+	c.Request.Header.Set("File-Path", url.PathEscape("/"+name))
+	c.Request.Header.Set("Overwrite", "false")
+	c.Request.Header.Del("Password")
+	user := *creator
+	user.BasePath = fr.Path
+	c.Set("user", &user)
+	c.Next()
+	if !c.IsAborted() {
+		if err = op.IncreaseFileRequestUploadCount(fr.Token); err != nil {
+			log.Errorf("failed increase upload count of file request %s: %+v", fr.Token, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+	gin.SetMode(gin.TestMode)
+}
+
+func TestFileRequest(t *testing.T) {
+	creator := &model.User{Username: "requester", BasePath: "/", Permission: 1 << 3}
+	if err := op.CreateUser(creator); err != nil {
+		t.Fatalf("failed to create user: %+v", err)
+	}
+	newRequest := func(fr model.FileRequest) string {
+		t.Helper()
+		fr.Path, fr.CreatorID = "/inbox", creator.ID
+		if err := op.CreateFileRequest(&fr); err != nil {
+			t.Fatalf("failed to create file request: %+v", err)
+		}
+		return fr.Token
+	}
+	// the upload handler is replaced by reading the body
+	var uploaded string
+	upload := func(c *gin.Context) {
+		if _, err := io.Copy(io.Discard, c.Request.Body); err != nil {
+			common.ErrorStrResp(c, "file is too large", 413)
+			return
+		}
+		user := c.MustGet("user").(*model.User)
+		name, _ := url.PathUnescape(c.GetHeader("File-Path"))
+		uploaded = user.BasePath + name
+		common.SuccessResp(c)
+	}
+	r := gin.New()
+	r.PUT("/r/:token", FileRequest, upload)
+	r.POST("/r/:token", FileRequest, upload)
+	serve := func(req *http.Request) int {
+		t.Helper()
+		uploaded = ""
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp struct {
+			Code int `json:"code"`
+		}
+		if err := utils.Json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response %s: %+v", w.Body.String(), err)
+		}
+		return resp.Code
+	}
+	put := func(token, name string, body []byte, chunked bool) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPut, "/r/"+token, bytes.NewReader(body))
+		req.Header.Set("File-Path", url.PathEscape(name))
+		if chunked {
+			req.ContentLength = -1
+		}
+		return serve(req)
+	}
+	post := func(token, name string, body []byte) int {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		form := multipart.NewWriter(buf)
+		w, _ := form.CreateFormFile("file", name)
+		_, _ = w.Write(body)
+		_ = form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/r/"+token, buf)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		return serve(req)
+	}
+
+	limited := newRequest(model.FileRequest{MaxSize: 4, AllowedExts: "txt, .PDF"})
+	if code := put(limited, "a.txt", []byte("1234"), false); code != 200 || uploaded != "/inbox/a.txt" {
+		t.Errorf("the file within the limits should be uploaded, got %d %s", code, uploaded)
+	}
+	if code := put(limited, "../../b.PDF", []byte("1"), false); code != 200 || uploaded != "/inbox/b.PDF" {
+		t.Errorf("the file should be uploaded into the folder directly, got %d %s", code, uploaded)
+	}
+	if fr, err := op.GetFileRequestByToken(limited); err != nil || fr.UploadCount != 2 {
+		t.Errorf("the uploads should be counted, got %+v, %+v", fr, err)
+	}
+
+	// size
+	if code := put(limited, "c.txt", []byte("12345"), false); code != 413 {
+		t.Errorf("the file larger than the max size should be rejected, got %d", code)
+	}
+	if code := put(limited, "c.txt", []byte("12345"), true); code != 413 || uploaded != "" {
+		t.Errorf("the body without length should be limited, got %d %s", code, uploaded)
+	}
+	if code := post(limited, "c.txt", []byte("12345")); code != 413 {
+		t.Errorf("the form file larger than the max size should be rejected, got %d", code)
+	}
+	if code := post(limited, "c.txt", []byte("123")); code != 200 || uploaded != "/inbox/c.txt" {
+		t.Errorf("the form file within the limits should be uploaded, got %d %s", code, uploaded)
+	}
+
+	// extension
+	for _, name := range []string{"d.exe", "d.txt.exe", "txt"} {
+		if code := put(limited, name, []byte("1"), false); code != 403 {
+			t.Errorf("the file %s should be rejected by the extension, got %d", name, code)
+		}
+	}
+	if code := put(newRequest(model.FileRequest{}), "d.exe", bytes.Repeat([]byte("1"), 10), false); code != 200 {
+		t.Errorf("all the files should be allowed without limits, got %d", code)
+	}
+
+	// expiry
+	expired := time.Now().Add(-time.Minute)
+	if code := put(newRequest(model.FileRequest{Expires: &expired}), "e.txt", []byte("1"), false); code != 403 {
+		t.Errorf("the upload into expired file request should be rejected, got %d", code)
+	}
+	valid := time.Now().Add(time.Hour)
+	if code := put(newRequest(model.FileRequest{Expires: &valid}), "e.txt", []byte("1"), false); code != 200 {
+		t.Errorf("the upload before expiry should be allowed, got %d", code)
+	}
+	if code := put(newRequest(model.FileRequest{Disabled: true}), "e.txt", []byte("1"), false); code != 403 {
+		t.Errorf("the upload into disabled file request should be rejected, got %d", code)
+	}
+	if code := put("unknown", "e.txt", []byte("1"), false); code != 404 {
+		t.Errorf("unknown file request should not be found, got %d", code)
+	}
+}
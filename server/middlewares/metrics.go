@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics record the count and latency of the requests, the protocol is set by ClientInfo
+func Metrics(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+	protocol := c.GetString(conf.ProtocolKey)
+	if protocol == "" {
+		protocol = model.ProtocolWeb
+	}
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	metrics.HTTPRequests.WithLabelValues(protocol, c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+	metrics.HTTPRequestDuration.WithLabelValues(protocol, c.Request.Method, route).Observe(time.Since(start).Seconds())
+}
+
+// MetricsAuth check the bearer token for /metrics if it's set in config
+func MetricsAuth(c *gin.Context) {
+	token := conf.Conf.Metrics.Token
+	if token == "" {
+		c.Next()
+		return
+	}
+	reqToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(reqToken), []byte(token)) != 1 {
+		c.AbortWithStatus(401)
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Tracing start a span for the request, the span can be got from gin.Context by tracing.SpanKey
+func Tracing(c *gin.Context) {
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	ctx, span := tracing.Start(tracing.Extract(c.Request.Context(), c.Request.Header), c.Request.Method+" "+route,
+		attribute.String("http.request.method", c.Request.Method),
+		attribute.String("http.route", route),
+		attribute.String("url.path", c.Request.URL.Path),
+	)
+	c.Request = c.Request.WithContext(ctx)
+	c.Set(tracing.SpanKey, span)
+	c.Next()
+	status := c.Writer.Status()
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+	var err error
+	if status >= http.StatusInternalServerError {
+		err = errors.New(http.StatusText(status))
+	}
+	tracing.End(span, err)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/alist-org/alist/v3/cmd/flags"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/message"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -29,16 +30,19 @@ func Init(e *gin.Engine) {
 	g.Any("/ping", func(c *gin.Context) {
 		c.String(200, "pong")
 	})
+	Metrics(g)
 	g.GET("/favicon.ico", handles.Favicon)
 	g.GET("/robots.txt", handles.Robots)
 	g.GET("/i/:link_name", handles.Plist)
 	common.SecretKey = []byte(conf.Conf.JwtSecret)
-	g.Use(middlewares.StoragesLoaded)
+	g.Use(middlewares.Metrics, middlewares.Tracing, middlewares.StoragesLoaded)
 	if conf.Conf.MaxConnections > 0 {
 		g.Use(middlewares.MaxAllowed(conf.Conf.MaxConnections))
 	}
-	WebDav(g.Group("/dav"))
-	S3(g.Group("/s3"))
+	WebDav(g.Group("/dav", middlewares.ClientInfo(model.ProtocolWebDAV)))
+	S3(g.Group("/s3", middlewares.ClientInfo(model.ProtocolS3)))
+	// the middlewares are only applied to the routes added after them
+	g.Use(middlewares.ClientInfo(model.ProtocolWeb))
 
 	downloadLimiter := middlewares.DownloadRateLimiter(stream.ClientDownloadLimit)
 	signCheck := middlewares.Down(sign.Verify)
@@ -53,6 +57,14 @@ func Init(e *gin.Engine) {
 	g.HEAD("/ad/*path", archiveSignCheck, handles.ArchiveDown)
 	g.HEAD("/ap/*path", archiveSignCheck, handles.ArchiveProxy)
 	g.HEAD("/ae/*path", archiveSignCheck, handles.ArchiveInternalExtract)
+	g.GET("/s/:id", downloadLimiter, handles.ShareGet)
+	g.HEAD("/s/:id", handles.ShareGet)
+	g.GET("/s/:id/*path", downloadLimiter, handles.ShareGet)
+	g.HEAD("/s/:id/*path", handles.ShareGet)
+	g.PUT("/s/:id/*path", middlewares.UploadRateLimiter(stream.ClientUploadLimit), handles.SharePut)
+	g.GET("/r/:token", handles.GetFileRequestInfo)
+	g.PUT("/r/:token", middlewares.FileRequest, middlewares.FsUp, middlewares.UploadRateLimiter(stream.ClientUploadLimit), handles.FsStream)
+	g.POST("/r/:token", middlewares.FileRequest, middlewares.FsUp, middlewares.UploadRateLimiter(stream.ClientUploadLimit), handles.FsForm)
 
 	api := g.Group("/api")
 	auth := api.Group("", middlewares.Auth)
@@ -91,6 +103,8 @@ func Init(e *gin.Engine) {
 	public.Any("/archive_extensions", handles.ArchiveExtensions)
 
 	_fs(auth.Group("/fs"))
+	_share(auth.Group("/share", middlewares.AuthNotGuest))
+	_fileRequest(auth.Group("/file_request", middlewares.AuthNotGuest))
 	_task(auth.Group("/task", middlewares.AuthNotGuest))
 	admin(auth.Group("/admin", middlewares.AuthAdmin))
 	if flags.Debug || flags.Dev {
@@ -102,6 +116,24 @@ func Init(e *gin.Engine) {
 }
 
 func admin(g *gin.RouterGroup) {
+	group := g.Group("/group")
+	group.GET("/list", handles.ListGroups)
+	group.GET("/get", handles.GetGroup)
+	group.POST("/create", handles.CreateGroup)
+	group.POST("/update", handles.UpdateGroup)
+	group.POST("/delete", handles.DeleteGroup)
+	group.GET("/meta/list", handles.ListGroupMetas)
+	group.POST("/meta/create", handles.CreateGroupMeta)
+	group.POST("/meta/update", handles.UpdateGroupMeta)
+	group.POST("/meta/delete", handles.DeleteGroupMeta)
+
+	acl := g.Group("/acl")
+	acl.GET("/list", handles.ListACLs)
+	acl.GET("/get", handles.GetACL)
+	acl.POST("/create", handles.CreateACL)
+	acl.POST("/update", handles.UpdateACL)
+	acl.POST("/delete", handles.DeleteACL)
+
 	meta := g.Group("/meta")
 	meta.GET("/list", handles.ListMetas)
 	meta.GET("/get", handles.GetMeta)
@@ -117,6 +149,7 @@ func admin(g *gin.RouterGroup) {
 	user.POST("/cancel_2fa", handles.Cancel2FAById)
 	user.POST("/delete", handles.DeleteUser)
 	user.POST("/del_cache", handles.DelUserCache)
+	user.POST("/reset_usage", handles.ResetUserUsage)
 	user.GET("/sshkey/list", handles.ListPublicKeys)
 	user.POST("/sshkey/delete", handles.DeletePublicKey)
 
@@ -129,6 +162,27 @@ func admin(g *gin.RouterGroup) {
 	storage.POST("/enable", handles.EnableStorage)
 	storage.POST("/disable", handles.DisableStorage)
 	storage.POST("/load_all", handles.LoadAllStorages)
+	storage.GET("/health", handles.ListStoragesHealth)
+	storage.GET("/health/history", handles.ListStorageHealthHistory)
+	storage.POST("/health/check", handles.CheckStorageHealth)
+
+	balance := g.Group("/balance")
+	balance.GET("/list", handles.ListBalanceGroups)
+	balance.GET("/get", handles.GetBalanceGroup)
+	balance.POST("/create", handles.CreateBalanceGroup)
+	balance.POST("/update", handles.UpdateBalanceGroup)
+	balance.POST("/delete", handles.DeleteBalanceGroup)
+	balance.GET("/stats", handles.GetBalanceStats)
+
+	webhook := g.Group("/webhook")
+	webhook.GET("/list", handles.ListWebhooks)
+	webhook.GET("/get", handles.GetWebhook)
+	webhook.GET("/events", handles.ListWebhookEvents)
+	webhook.POST("/create", handles.CreateWebhook)
+	webhook.POST("/update", handles.UpdateWebhook)
+	webhook.POST("/delete", handles.DeleteWebhook)
+	webhook.POST("/test", handles.TestWebhook)
+	webhook.GET("/deliveries", handles.ListWebhookDeliveries)
 
 	driver := g.Group("/driver")
 	driver.GET("/list", handles.ListDriverInfo)
@@ -157,12 +211,43 @@ func admin(g *gin.RouterGroup) {
 	ms.POST("/get", message.HttpInstance.GetHandle)
 	ms.POST("/send", message.HttpInstance.SendHandle)
 
+	sched := g.Group("/schedule")
+	sched.GET("/list", handles.ListScheduledJobs)
+	sched.GET("/get", handles.GetScheduledJob)
+	sched.POST("/create", handles.CreateScheduledJob)
+	sched.POST("/update", handles.UpdateScheduledJob)
+	sched.POST("/delete", handles.DeleteScheduledJob)
+	sched.POST("/run", handles.RunScheduledJob)
+	sched.GET("/history", handles.ListScheduledJobHistory)
+
+	share := g.Group("/share")
+	share.GET("/list", handles.ListAllShares)
+	share.POST("/delete", handles.DeleteShare)
+
+	fileRequest := g.Group("/file_request")
+	fileRequest.GET("/list", handles.ListAllFileRequests)
+	fileRequest.POST("/delete", handles.DeleteFileRequest)
+
+	trash := g.Group("/trash")
+	trash.GET("/list", handles.ListTrash)
+	trash.POST("/restore", handles.RestoreTrash)
+	trash.POST("/purge", handles.PurgeTrash)
+	trash.POST("/empty", handles.EmptyTrash)
+
+	g.GET("/audit/list", handles.ListAuditLogs)
+
+	cache := g.Group("/cache")
+	cache.POST("/invalidate", handles.InvalidateCache)
+
 	index := g.Group("/index")
 	index.POST("/build", middlewares.SearchIndex, handles.BuildIndex)
 	index.POST("/update", middlewares.SearchIndex, handles.UpdateIndex)
 	index.POST("/stop", middlewares.SearchIndex, handles.StopIndex)
 	index.POST("/clear", middlewares.SearchIndex, handles.ClearIndex)
 	index.GET("/progress", middlewares.SearchIndex, handles.GetProgress)
+	index.GET("/jobs", middlewares.SearchIndex, handles.ListIndexJobs)
+	index.POST("/storage/start", middlewares.SearchIndex, handles.StartStorageIndex)
+	index.POST("/storage/stop", middlewares.SearchIndex, handles.StopStorageIndex)
 }
 
 func _fs(g *gin.RouterGroup) {
@@ -178,8 +263,11 @@ func _fs(g *gin.RouterGroup) {
 	g.POST("/move", handles.FsMove)
 	g.POST("/recursive_move", handles.FsRecursiveMove)
 	g.POST("/copy", handles.FsCopy)
+	g.POST("/sync", handles.FsSync)
 	g.POST("/remove", handles.FsRemove)
 	g.POST("/remove_empty_directory", handles.FsRemoveEmptyDirectory)
+	g.POST("/versions", handles.FsVersions)
+	g.POST("/restore_version", handles.FsRestoreVersion)
 	uploadLimiter := middlewares.UploadRateLimiter(stream.ClientUploadLimit)
 	g.PUT("/put", middlewares.FsUp, uploadLimiter, handles.FsStream)
 	g.PUT("/form", middlewares.FsUp, uploadLimiter, handles.FsForm)
@@ -194,6 +282,20 @@ func _fs(g *gin.RouterGroup) {
 	a.POST("/decompress", handles.FsArchiveDecompress)
 }
 
+func _share(g *gin.RouterGroup) {
+	g.GET("/list", handles.ListShares)
+	g.POST("/create", handles.CreateShare)
+	g.POST("/update", handles.UpdateShare)
+	g.POST("/delete", handles.DeleteShare)
+}
+
+func _fileRequest(g *gin.RouterGroup) {
+	g.GET("/list", handles.ListFileRequests)
+	g.POST("/create", handles.CreateFileRequest)
+	g.POST("/update", handles.UpdateFileRequest)
+	g.POST("/delete", handles.DeleteFileRequest)
+}
+
 func _task(g *gin.RouterGroup) {
 	handles.SetupTaskRoute(g)
 }
@@ -209,5 +311,6 @@ func Cors(r *gin.Engine) {
 
 func InitS3(e *gin.Engine) {
 	Cors(e)
+	e.Use(middlewares.Metrics, middlewares.Tracing, middlewares.ClientInfo(model.ProtocolS3))
 	S3Server(e.Group("/"))
 }
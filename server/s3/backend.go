@@ -160,7 +160,7 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 		return nil, gofakes3.KeyNotFound(objectName)
 	}
 
-	link, file, err := fs.Link(ctx, fp, model.LinkArgs{})
+	link, file, err := fs.LinkDown(ctx, fp, model.LinkArgs{})
 	if err != nil {
 		return nil, err
 	}
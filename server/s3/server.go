@@ -7,6 +7,9 @@ import (
 	"math/rand"
 	"net/http"
 
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/gofakes3"
 )
 
@@ -23,5 +26,10 @@ func NewServer(ctx context.Context) (h http.Handler, err error) {
 		gofakes3.WithIntegrityCheck(true), // Check Content-MD5 if supplied
 	)
 
-	return faker.Server(), nil
+	s3Handler := faker.Server()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), conf.ClientIPKey, utils.ClientIP(r))
+		ctx = context.WithValue(ctx, conf.ProtocolKey, model.ProtocolS3)
+		s3Handler.ServeHTTP(w, r.WithContext(ctx))
+	}), nil
 }
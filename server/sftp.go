@@ -62,7 +62,8 @@ func (d *SftpDriver) GetFileSystem(sc *ssh.ServerConn) (sftpd.FileSystem, error)
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, "user", userObj)
 	ctx = context.WithValue(ctx, "meta_pass", "")
-	ctx = context.WithValue(ctx, "client_ip", sc.RemoteAddr().String())
+	ctx = context.WithValue(ctx, conf.ClientIPKey, sc.RemoteAddr().String())
+	ctx = context.WithValue(ctx, conf.ProtocolKey, model.ProtocolSFTP)
 	ctx = context.WithValue(ctx, "proxy_header", d.proxyHeader)
 	return &sftp.DriverAdapter{FtpDriver: ftp.NewAferoAdapter(ctx)}, nil
 }
@@ -46,6 +46,8 @@ func WebDav(dav *gin.RouterGroup) {
 func ServeWebDAV(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 	ctx := context.WithValue(c.Request.Context(), "user", user)
+	ctx = context.WithValue(ctx, conf.ClientIPKey, c.ClientIP())
+	ctx = context.WithValue(ctx, conf.ProtocolKey, model.ProtocolWebDAV)
 	handler.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
 }
 
@@ -102,22 +104,30 @@ func WebDAVAuth(c *gin.Context) {
 		c.Abort()
 		return
 	}
-	if (c.Request.Method == "PUT" || c.Request.Method == "MKCOL") && (!user.CanWebdavManage() || !user.CanWrite()) {
+	// the permission at the path is used for the users with ACLs
+	reqPath, err := user.JoinPath(c.Param("path"))
+	if err != nil {
 		c.Status(http.StatusForbidden)
 		c.Abort()
 		return
 	}
-	if c.Request.Method == "MOVE" && (!user.CanWebdavManage() || (!user.CanMove() && !user.CanRename())) {
+	perm := user.At(reqPath)
+	if (c.Request.Method == "PUT" || c.Request.Method == "MKCOL") && (!user.CanWebdavManage() || !perm.CanWrite()) {
 		c.Status(http.StatusForbidden)
 		c.Abort()
 		return
 	}
-	if c.Request.Method == "COPY" && (!user.CanWebdavManage() || !user.CanCopy()) {
+	if c.Request.Method == "MOVE" && (!user.CanWebdavManage() || (!perm.CanMove() && !perm.CanRename())) {
 		c.Status(http.StatusForbidden)
 		c.Abort()
 		return
 	}
-	if c.Request.Method == "DELETE" && (!user.CanWebdavManage() || !user.CanRemove()) {
+	if c.Request.Method == "COPY" && (!user.CanWebdavManage() || !perm.CanCopy()) {
+		c.Status(http.StatusForbidden)
+		c.Abort()
+		return
+	}
+	if c.Request.Method == "DELETE" && (!user.CanWebdavManage() || !perm.CanRemove()) {
 		c.Status(http.StatusForbidden)
 		c.Abort()
 		return
@@ -34,10 +34,10 @@ func moveFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 	srcName := path.Base(src)
 	dstName := path.Base(dst)
 	user := ctx.Value("user").(*model.User)
-	if srcDir != dstDir && !user.CanMove() {
+	if srcDir != dstDir && (!user.At(src).CanMove() || !user.At(dst).CanMove()) {
 		return http.StatusForbidden, nil
 	}
-	if srcName != dstName && !user.CanRename() {
+	if srcName != dstName && !user.At(dst).CanRename() {
 		return http.StatusForbidden, nil
 	}
 	if srcDir == dstDir {
@@ -63,6 +63,10 @@ func moveFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 // See section 9.8.5 for when various HTTP status codes apply.
 func copyFiles(ctx context.Context, src, dst string, overwrite bool) (status int, err error) {
 	dstDir := path.Dir(dst)
+	user := ctx.Value("user").(*model.User)
+	if !user.At(dst).CanCopy() {
+		return http.StatusForbidden, nil
+	}
 	_, err = fs.Copy(context.WithValue(ctx, conf.NoTaskKey, struct{}{}), src, dstDir)
 	if err != nil {
 		return http.StatusInternalServerError, err
@@ -91,7 +95,8 @@ func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 	if depth == 1 {
 		depth = 0
 	}
-	meta, _ := op.GetNearestMeta(name)
+	user, _ := ctx.Value("user").(*model.User)
+	meta, _ := op.GetNearestMetaForUser(user, name)
 	// Read directory names.
 	objs, err := fs.List(context.WithValue(ctx, "meta", meta), name, &fs.ListArgs{})
 	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)